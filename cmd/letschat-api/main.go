@@ -8,6 +8,7 @@ import (
 	"github.com/MuhamedUsman/letschat/internal/api/server"
 	"github.com/MuhamedUsman/letschat/internal/api/service"
 	"github.com/MuhamedUsman/letschat/internal/api/utility"
+	"github.com/MuhamedUsman/letschat/internal/api/webhook"
 	"github.com/MuhamedUsman/letschat/internal/common"
 	"log/slog"
 	"os"
@@ -20,25 +21,39 @@ func main() {
 	db := repository.OpenDB(cfg)
 	bgTask := common.NewBackgroundTask()
 	mailr := mailer.New(cfg)
+	sender := webhook.New(cfg)
 	// Repositories
 	userRepo := repository.NewUserRepository(db)
 	tokenRepo := repository.NewTokenRepository(db)
 	messageRepo := repository.NewMessageRepository(db)
 	conversationRepo := repository.NewConversationRepository(db)
+	eventRepo := repository.NewEventRepository(db)
+	watermarkRepo := repository.NewWatermarkRepository(db)
+	scheduledRepo := repository.NewScheduledMessageRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
 	// Services
-	userService := service.NewUserService(userRepo)
+	userService := service.NewUserService(userRepo, cfg.Handle.RedirectGrace)
 	tokenService := service.NewTokenService(tokenRepo)
-	messageService := service.NewMessageService(messageRepo)
+	messageService := service.NewMessageService(messageRepo, eventRepo, watermarkRepo, conversationRepo)
 	conversationService := service.NewConversationService(conversationRepo)
+	presenceService := service.NewPresenceService(userRepo, cfg.Presence.Debounce, cfg.Presence.PersistEvery)
+	scheduledService := service.NewScheduledMessageService(scheduledRepo)
+	webhookService := service.NewWebhookService(
+		webhookRepo, cfg.Webhook.MaxAttempts, cfg.Webhook.BackoffBase, cfg.Webhook.BackoffMax,
+	)
 	// Service Group
-	srv := service.New(userService, tokenService, messageService, conversationService)
+	srv := service.New(
+		userService, tokenService, messageService, conversationService, presenceService, scheduledService, webhookService,
+	)
 	// Facades
 	userFacade := facade.NewUserFacade(srv, db, mailr, bgTask)
 	tokenFacade := facade.NewTokenFacade(srv, db, mailr, bgTask)
 	messageFacade := facade.NewMessageFacade(srv, db, bgTask)
 	conversationFacade := facade.NewConversationFacade(srv)
+	presenceFacade := facade.NewPresenceFacade(srv)
+	webhookFacade := facade.NewWebhookFacade(srv, bgTask, sender, 2*cfg.Webhook.Timeout)
 	// Facade Group
-	fac := facade.New(userFacade, tokenFacade, messageFacade, conversationFacade)
+	fac := facade.New(userFacade, tokenFacade, messageFacade, conversationFacade, presenceFacade, webhookFacade)
 	// Server
 	s := server.NewServer(cfg, bgTask, fac)
 	// printing banner
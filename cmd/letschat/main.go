@@ -3,6 +3,7 @@ package main
 import (
 	"flag"
 	"github.com/MuhamedUsman/letschat/internal/client"
+	"github.com/MuhamedUsman/letschat/internal/client/notify"
 	"github.com/MuhamedUsman/letschat/internal/tui"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -10,19 +11,56 @@ import (
 	zone "github.com/lrstanley/bubblezone"
 	"log/slog"
 	"os"
+	"time"
 )
 
 func main() {
 
-	var key int
-	flag.IntVar(&key, "usr", 1, "User to login for testing")
+	var awayAfter, heartbeatInterval time.Duration
+	var maxMissedHeartbeats int
+	var profile, notifiers, notifyCmd, quietHours string
+	flag.StringVar(&profile, "profile", "",
+		"Server profile of the config.json in the app data, or local or production, whose account is shown, "+
+			"empty for the account active last time; $LETSCHAT_SERVER, $LETSCHAT_WS_SERVER & $LETSCHAT_CA_BUNDLE "+
+			"override it")
+	flag.DurationVar(&awayAfter, "away-after", 5*time.Minute, "Idle time before reported as away, 0 disables it")
+	flag.DurationVar(&heartbeatInterval, "heartbeat-interval", 15*time.Second, "Websocket heartbeat ping interval")
+	flag.IntVar(&maxMissedHeartbeats, "max-missed-heartbeats", 3,
+		"Websocket missed heartbeats in a row before reconnecting")
+	flag.StringVar(&notifiers, "notify", notify.Bell,
+		"Comma separated notifiers of the msgs received {bell|osc9|osc777|notify-send|cmd}, empty disables them")
+	flag.StringVar(&notifyCmd, "notify-cmd", "",
+		"Command run by the cmd notifier, with the msg in $LETSCHAT_TITLE & $LETSCHAT_BODY")
+	flag.StringVar(&quietHours, "quiet-hours", "", "Daily span without notifications, e.g. 22:00-07:30")
 	flag.Parse()
 
 	slogger := slog.New(tint.NewHandler(os.Stderr, nil))
 
+	if heartbeatInterval <= 0 || maxMissedHeartbeats < 1 {
+		slogger.Error("the heartbeat interval must be positive, & at least one heartbeat missed before reconnecting")
+		os.Exit(1)
+	}
+	notifs, err := notify.New(notifiers, notifyCmd, os.Stdout)
+	if err != nil {
+		slogger.Error(err.Error())
+		os.Exit(1)
+	}
+	quiet, err := notify.ParseSchedule(quietHours)
+	if err != nil {
+		slogger.Error(err.Error())
+		os.Exit(1)
+	}
+
 	// using it as initialization, if err occurs, we halt the application on startup rather than having issues while the
 	// app is running
-	if err := client.Init(key); err != nil {
+	accounts, err := client.LoadAccounts(profile, client.Options{
+		AwayAfter:           awayAfter,
+		HeartbeatInterval:   heartbeatInterval,
+		MaxMissedHeartbeats: maxMissedHeartbeats,
+		Notifiers:           notifs,
+		QuietHours:          quiet,
+	})
+	if err != nil {
 		slogger.Error(err.Error())
 		os.Exit(1)
 	}
@@ -39,7 +77,7 @@ func main() {
 	zone.NewGlobal()
 	_ = lipgloss.DefaultRenderer().HasDarkBackground()
 	_, err = tea.NewProgram(
-		tui.InitialTabContainerModel(),
+		tui.InitialModel(accounts),
 		tea.WithAltScreen(),
 		tea.WithMouseAllMotion(),
 		tea.WithoutBracketedPaste(),
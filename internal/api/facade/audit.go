@@ -0,0 +1,45 @@
+package facade
+
+import (
+	"github.com/MuhamedUsman/letschat/internal/domain"
+	"log/slog"
+	"maps"
+	"sync"
+)
+
+// AuditLog keeps count of the msg operations rejected for each user, every rejection is also logged,
+// so repeated offenders can be spotted from the logs & the counts
+type AuditLog struct {
+	mu     sync.Mutex
+	counts map[string]int // keys are userID
+}
+
+func NewAuditLog() *AuditLog {
+	return &AuditLog{counts: make(map[string]int)}
+}
+
+func (a *AuditLog) Record(usrID string, m domain.MessageSent, reason string) {
+	a.mu.Lock()
+	a.counts[usrID]++
+	count := a.counts[usrID]
+	a.mu.Unlock()
+	var msgID string
+	if m.ID != nil {
+		msgID = *m.ID
+	}
+	slog.Warn("rejected message operation",
+		"user", usrID,
+		"receiver", m.ReceiverID,
+		"msg", msgID,
+		"operation", m.Operation,
+		"reason", reason,
+		"violations", count,
+	)
+}
+
+// Counts returns the number of rejected operations by userID, served on the debug address of the server
+func (a *AuditLog) Counts() map[string]int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return maps.Clone(a.counts)
+}
@@ -0,0 +1,91 @@
+package facade
+
+import (
+	"context"
+	"fmt"
+	"github.com/MuhamedUsman/letschat/internal/domain"
+	"github.com/google/uuid"
+	"time"
+)
+
+// CreateBot registers a bot owned by u along its api key, bots cannot own bots
+func (f *UserFacade) CreateBot(ctx context.Context, b *domain.BotRegister, u *domain.User) (*domain.Bot, error) {
+	if u.IsBot {
+		return nil, fmt.Errorf("%w: bots cannot own bots", domain.ErrForbidden)
+	}
+	var bot domain.Bot
+	if err := f.txManager.RunInTX(ctx, func(ctx context.Context) error {
+		botID, err := f.service.RegisterBot(ctx, u.ID, b)
+		if err != nil {
+			return err
+		}
+		if bot.Key, err = f.service.GenerateToken(ctx, botID, domain.ScopeBot); err != nil {
+			return err
+		}
+		bot.User, err = f.service.GetByUniqueField(ctx, botID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return &bot, nil
+}
+
+// GetBots returns the bots owned by u, without their api keys
+func (f *UserFacade) GetBots(ctx context.Context, u *domain.User) ([]*domain.Bot, error) {
+	usrs, err := f.service.GetBots(ctx, u.ID)
+	if err != nil {
+		return nil, err
+	}
+	bots := make([]*domain.Bot, len(usrs))
+	for i, usr := range usrs {
+		bots[i] = &domain.Bot{User: usr}
+	}
+	return bots, nil
+}
+
+// RotateBotKey revokes the api key of the bot botID owned by u, and returns a new one,
+// domain.ErrRecordNotFound if u does not own such a bot
+func (f *UserFacade) RotateBotKey(ctx context.Context, botID string, u *domain.User) (*domain.Bot, error) {
+	usr, err := f.service.GetByUniqueField(ctx, botID)
+	if err != nil {
+		return nil, err
+	}
+	if !usr.IsBot || usr.BotOwnerID == nil || *usr.BotOwnerID != u.ID {
+		return nil, domain.ErrRecordNotFound
+	}
+	bot := domain.Bot{User: usr}
+	if err = f.txManager.RunInTX(ctx, func(ctx context.Context) error {
+		if err = f.service.DeleteAllForUser(ctx, botID, domain.ScopeBot); err != nil {
+			return err
+		}
+		bot.Key, err = f.service.GenerateToken(ctx, botID, domain.ScopeBot)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return &bot, nil
+}
+
+// SendBotMessage sends the msg of the bot u through ProcessSentMessage, as if it was sent over the websocket
+func (f *MessageFacade) SendBotMessage(
+	ctx context.Context,
+	in domain.BotMessageInput,
+	u *domain.User,
+) (*domain.Message, bool, error) {
+	if ev := in.ValidateBotMessageInput(); ev.HasErrors() {
+		return nil, false, ev
+	}
+	return f.ProcessSentMessage(ctx, in.MessageSent(uuid.New().String(), time.Now()), u)
+}
+
+// GetBotUpdates acknowledges every event of the bot in the context up to offset, then returns at most limit events
+// after it, the bot has no local store, so the offset is the cursor it has processed the events up to, no event of
+// the bot below it commits later, see EventRepository.Append
+func (f *MessageFacade) GetBotUpdates(ctx context.Context, offset int64, limit int) ([]*domain.Message, error) {
+	if offset > 0 {
+		if err := f.service.AckEvents(ctx, offset); err != nil {
+			return nil, err
+		}
+	}
+	return f.service.GetEventsSince(ctx, offset, limit)
+}
@@ -14,6 +14,29 @@ func NewConversationFacade(srv *service.Service) *ConversationFacade {
 	return &ConversationFacade{srv}
 }
 
+// GetConversations overlays the in-memory presences on the persisted last_online, which may lag behind
 func (f *ConversationFacade) GetConversations(ctx context.Context) ([]*domain.Conversation, error) {
-	return f.service.GetConversations(ctx)
+	convos, err := f.service.GetConversations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(convos))
+	for i, convo := range convos {
+		ids[i] = convo.UserID
+	}
+	presences, err := f.service.GetPresences(ctx, ids...)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]domain.Presence, len(presences))
+	for _, p := range presences {
+		byID[p.UserID] = p
+	}
+	for _, convo := range convos {
+		if p, ok := byID[convo.UserID]; ok {
+			convo.Presence = p.Status
+			convo.LastOnline = p.LastOnline
+		}
+	}
+	return convos, nil
 }
@@ -7,14 +7,25 @@ type Facade struct {
 	*TokenFacade
 	*MessageFacade
 	*ConversationFacade
+	*PresenceFacade
+	*WebhookFacade
 }
 
-func New(uf *UserFacade, tf *TokenFacade, mf *MessageFacade, cf *ConversationFacade) *Facade {
+func New(
+	uf *UserFacade,
+	tf *TokenFacade,
+	mf *MessageFacade,
+	cf *ConversationFacade,
+	pf *PresenceFacade,
+	wf *WebhookFacade,
+) *Facade {
 	return &Facade{
 		UserFacade:         uf,
 		TokenFacade:        tf,
 		MessageFacade:      mf,
 		ConversationFacade: cf,
+		PresenceFacade:     pf,
+		WebhookFacade:      wf,
 	}
 }
 
@@ -2,16 +2,20 @@ package facade
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/MuhamedUsman/letschat/internal/api/service"
 	"github.com/MuhamedUsman/letschat/internal/common"
 	"github.com/MuhamedUsman/letschat/internal/domain"
 	"log/slog"
+	"time"
 )
 
 type MessageFacade struct {
 	service   *service.Service
 	txManager TXManager
 	bgTask    *common.BackgroundTask
+	audit     *AuditLog
 }
 
 func NewMessageFacade(service *service.Service,
@@ -21,17 +25,104 @@ func NewMessageFacade(service *service.Service,
 		service:   service,
 		txManager: txMan,
 		bgTask:    bgTask,
+		audit:     NewAuditLog(),
 	}
 }
 
 func (f *MessageFacade) ProcessSentMessage(ctx context.Context,
 	m domain.MessageSent,
 	u *domain.User,
+) (*domain.Message, bool, error) {
+	msg, convoCreated, err := f.sendMessage(ctx, m, u)
+	if err != nil {
+		return nil, convoCreated, err
+	}
+	f.processMessage(ctx, msg)
+	return msg, convoCreated, nil
+}
+
+// SyncEvents acknowledges every event up to the since cursor of the SyncRequestMsg, then calls fn with the
+// events after it in bounded batches, returns the cursor of the last event streamed
+func (f *MessageFacade) SyncEvents(
+	ctx context.Context,
+	m domain.MessageSent,
+	fn func(events []*domain.Message) error,
+) (int64, error) {
+	if ev := m.ValidateMessageSent(); ev != nil && ev.HasErrors() {
+		return 0, ev
+	}
+	if err := f.service.AckEvents(ctx, *m.Since); err != nil {
+		return 0, err
+	}
+	return f.service.StreamEventsSince(ctx, *m.Since, fn)
+}
+
+// PurgeExpiredMessages deletes the msgs of the conversations with disappearing msgs on, once their ExpiresAt passes,
+// along the events they are still pending as
+func (f *MessageFacade) PurgeExpiredMessages(ctx context.Context) error {
+	return f.txManager.RunInTX(ctx, func(ctx context.Context) error {
+		n, err := f.service.PurgeExpiredMessages(ctx, time.Now())
+		if n > 0 {
+			slog.Info("purged expired messages", "count", n)
+		}
+		return err
+	})
+}
+
+// AuditLog returns the log of the rejected msg operations
+func (f *MessageFacade) AuditLog() *AuditLog {
+	return f.audit
+}
+
+// Helpers & Stuff ----------------------------------------------------------------------------------------------------
+
+// sendMessage authorizes m & appends its event, in the tx of ctx if any, the msg is persisted by processMessage once
+// it is committed
+func (f *MessageFacade) sendMessage(
+	ctx context.Context,
+	m domain.MessageSent,
+	u *domain.User,
 ) (*domain.Message, bool, error) {
 	if ev := m.ValidateMessageSent(); ev != nil && ev.HasErrors() {
 		return nil, false, ev
 	}
+	if err := f.authorizeReceiver(ctx, m, u); err != nil {
+		return nil, false, err
+	}
 	msg := f.service.PopulateMessage(m, u)
+	if err := f.service.AuthorizeMessage(ctx, msg); err != nil {
+		if errors.Is(err, domain.ErrForbidden) {
+			return nil, false, f.rejectMessage(m, u, "operation", err)
+		}
+		return nil, false, err
+	}
+	if msg.Operation.IsWatermark() || msg.Operation == domain.DisappearingMsg {
+		convoExists, err := f.service.ConversationExists(ctx, msg.SenderID, m.ReceiverID)
+		if err != nil {
+			return nil, false, err
+		}
+		if !convoExists {
+			err = fmt.Errorf("%w: no conversation with this user", domain.ErrForbidden)
+			return nil, false, f.rejectMessage(m, u, "receiverID", err)
+		}
+	}
+	switch msg.Operation {
+	case domain.CreateMsg:
+		if err := f.stampExpiry(ctx, msg); err != nil {
+			return nil, false, err
+		}
+	case domain.DisappearingMsg:
+		// persisted synchronously, the msgs sent right after are stamped with it
+		if err := f.service.SetDisappearAfter(ctx, msg.SenderID, msg.ReceiverID, *msg.DisappearAfter); err != nil {
+			return nil, false, err
+		}
+	}
+	// appended synchronously, so the msg is relayed along its cursor
+	if msg.Operation.IsEvent() {
+		if err := f.service.AppendEvent(ctx, msg); err != nil {
+			return nil, false, err
+		}
+	}
 	convoCreated := false
 	if msg.Operation == domain.CreateMsg {
 		convoExists, err := f.service.ConversationExists(ctx, msg.SenderID, m.ReceiverID)
@@ -45,18 +136,54 @@ func (f *MessageFacade) ProcessSentMessage(ctx context.Context,
 			}
 		}
 	}
-	f.processMessage(ctx, msg)
 	return msg, convoCreated, nil
 }
 
-func (f *MessageFacade) WriteUnDeliveredMessagesToWSConn(ctx context.Context, c domain.MsgChan) error {
-	return f.service.GetUnDeliveredMessages(ctx, c)
+// authorizeReceiver ensures the receiver of the msg is an existing & activated user other than the sender
+func (f *MessageFacade) authorizeReceiver(ctx context.Context, m domain.MessageSent, u *domain.User) error {
+	if m.ReceiverID == u.ID {
+		return f.rejectMessage(m, u, "receiverID", fmt.Errorf("%w: must not be the sender", domain.ErrForbidden))
+	}
+	rcvr, err := f.service.GetByUniqueField(ctx, m.ReceiverID)
+	if err != nil {
+		if errors.Is(err, domain.ErrRecordNotFound) {
+			return f.rejectMessage(m, u, "receiverID", fmt.Errorf("%w: user does not exist", domain.ErrForbidden))
+		}
+		return err
+	}
+	if !rcvr.Activated {
+		return f.rejectMessage(m, u, "receiverID", fmt.Errorf("%w: user is not activated", domain.ErrForbidden))
+	}
+	return nil
+}
+
+// stampExpiry sets the ExpiresAt of msg if the disappearing msgs are on for its conversation, counted from when the
+// server receives it, the SentAt is set by the sending client & could put the expiry off indefinitely
+func (f *MessageFacade) stampExpiry(ctx context.Context, msg *domain.Message) error {
+	secs, err := f.service.GetDisappearAfter(ctx, msg.SenderID, msg.ReceiverID)
+	if err != nil || secs == 0 {
+		return err
+	}
+	expiresAt := time.Now().Add(time.Duration(secs) * time.Second)
+	msg.ExpiresAt = &expiresAt
+	return nil
 }
 
-// Helpers & Stuff ----------------------------------------------------------------------------------------------------
+// rejectMessage records the violation in the audit log, and returns it as a validation error for the sender
+func (f *MessageFacade) rejectMessage(m domain.MessageSent, u *domain.User, field string, err error) error {
+	f.audit.Record(u.ID, m, err.Error())
+	ev := domain.NewErrValidation()
+	ev.AddError(field, err.Error())
+	return ev
+}
 
+// processMessage persists msg in the background, detached from the cancellation of ctx (the ws conn's),
+// so an in-flight write is flushed even if the conn closes, BackgroundTask.Shutdown waits for it on shutdown
 func (f *MessageFacade) processMessage(ctx context.Context, msg *domain.Message) {
+	ctx = context.WithoutCancel(ctx)
 	f.bgTask.Run(func(context.Context) {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
 		if err := f.txManager.RunInTX(ctx, func(ctx context.Context) error {
 			return f.service.ProcessSentMessages(ctx, msg)
 		}); err != nil {
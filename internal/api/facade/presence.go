@@ -0,0 +1,54 @@
+package facade
+
+import (
+	"context"
+	"github.com/MuhamedUsman/letschat/internal/api/service"
+	"github.com/MuhamedUsman/letschat/internal/domain"
+)
+
+type PresenceFacade struct {
+	service *service.Service
+}
+
+func NewPresenceFacade(srv *service.Service) *PresenceFacade {
+	return &PresenceFacade{srv}
+}
+
+func (f *PresenceFacade) RunPresence(shtdwnCtx context.Context) {
+	f.service.RunPresence(shtdwnCtx)
+}
+
+func (f *PresenceFacade) ConnectPresence(usrID string) {
+	f.service.ConnectPresence(usrID)
+}
+
+// DisconnectPresence also drops the presence subscriptions of the user, they are renewed on reconnect
+func (f *PresenceFacade) DisconnectPresence(usrID string) {
+	f.service.UnsubscribePresence(usrID)
+	f.service.DisconnectPresence(usrID)
+}
+
+func (f *PresenceFacade) SetPresence(m domain.MessageSent, u *domain.User) error {
+	if ev := m.ValidateMessageSent(); ev != nil && ev.HasErrors() {
+		return ev
+	}
+	f.service.SetPresence(u.ID, *m.Presence)
+	return nil
+}
+
+// SubscribePresence replaces the presence subscriptions of the user, and returns the current presences of them
+func (f *PresenceFacade) SubscribePresence(
+	ctx context.Context,
+	m domain.MessageSent,
+	u *domain.User,
+) ([]domain.Presence, error) {
+	if ev := m.ValidateMessageSent(); ev != nil && ev.HasErrors() {
+		return nil, ev
+	}
+	f.service.SubscribePresence(u.ID, m.UserIDs)
+	return f.service.GetPresences(ctx, m.UserIDs...)
+}
+
+func (f *PresenceFacade) PresenceChanges() <-chan domain.PresenceChange {
+	return f.service.PresenceChanges()
+}
@@ -0,0 +1,163 @@
+package facade
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/MuhamedUsman/letschat/internal/api/utility"
+	"github.com/MuhamedUsman/letschat/internal/domain"
+	"github.com/google/uuid"
+	"log/slog"
+	"time"
+)
+
+const (
+	// maxPendingScheduled bounds the msgs a single user may have scheduled at once
+	maxPendingScheduled = 100
+	// releaseBatchSize bounds the due scheduled msgs released at once by ReleaseDueMessages
+	releaseBatchSize = 100
+)
+
+// ReleasedMessage is a domain.ScheduledMessage sent by ReleaseDueMessages
+type ReleasedMessage struct {
+	// as it is relayed to the receiver
+	Msg *domain.Message
+	// the domain.ScheduledSentMsg appended to the event log of the sender
+	SentEvent    *domain.Message
+	ConvoCreated bool
+}
+
+func (f *MessageFacade) ScheduleMessage(
+	ctx context.Context,
+	in domain.ScheduledMessageInput,
+	u *domain.User,
+) (*domain.ScheduledMessage, error) {
+	if ev := in.ValidateScheduledMessageInput(false); ev.HasErrors() {
+		return nil, ev
+	}
+	ms := domain.MessageSent{ReceiverID: *in.ReceiverID, Body: in.Body, Operation: domain.CreateMsg}
+	if err := f.authorizeReceiver(ctx, ms, u); err != nil {
+		return nil, err
+	}
+	pending, err := f.service.GetScheduledMessages(ctx, u.ID, "")
+	if err != nil {
+		return nil, err
+	}
+	if len(pending) >= maxPendingScheduled {
+		ev := domain.NewErrValidation()
+		ev.AddError("send_at", fmt.Sprintf("must not have more than %d messages scheduled", maxPendingScheduled))
+		return nil, ev
+	}
+	sm := &domain.ScheduledMessage{
+		ID:         uuid.New().String(),
+		SenderID:   u.ID,
+		ReceiverID: *in.ReceiverID,
+		Body:       *in.Body,
+		SendAt:     *in.SendAt,
+	}
+	if err = f.service.ScheduleMessage(ctx, sm); err != nil {
+		return nil, err
+	}
+	return sm, nil
+}
+
+// GetScheduledMessages returns the pending msgs of u to receiverID, or to anyone if empty
+func (f *MessageFacade) GetScheduledMessages(
+	ctx context.Context,
+	receiverID string,
+	u *domain.User,
+) ([]*domain.ScheduledMessage, error) {
+	return f.service.GetScheduledMessages(ctx, u.ID, receiverID)
+}
+
+// UpdateScheduledMessage changes the body & the send time of a pending msg of u, the nil fields of in are left as is
+func (f *MessageFacade) UpdateScheduledMessage(
+	ctx context.Context,
+	id string,
+	in domain.ScheduledMessageInput,
+	u *domain.User,
+) (*domain.ScheduledMessage, error) {
+	if ev := in.ValidateScheduledMessageInput(true); ev.HasErrors() {
+		return nil, ev
+	}
+	sm, err := f.service.GetScheduledMessage(ctx, id, u.ID)
+	if err != nil {
+		return nil, err
+	}
+	// released or edited by another client in the meantime
+	if sm.Version != *in.Version {
+		return nil, domain.ErrEditConflict
+	}
+	if in.Body != nil {
+		sm.Body = *in.Body
+	}
+	if in.SendAt != nil {
+		sm.SendAt = *in.SendAt
+	}
+	if err = f.service.UpdateScheduledMessage(ctx, sm); err != nil {
+		return nil, err
+	}
+	return sm, nil
+}
+
+func (f *MessageFacade) CancelScheduledMessage(ctx context.Context, id string, u *domain.User) error {
+	return f.service.CancelScheduledMessage(ctx, id, u.ID)
+}
+
+// ReleaseDueMessages sends the scheduled msgs due by now through ProcessSentMessage, as if their senders sent them
+// right now, fn is called with each of them to be relayed, its ctx carries the sender
+func (f *MessageFacade) ReleaseDueMessages(ctx context.Context, fn func(ctx context.Context, r ReleasedMessage)) error {
+	due, err := f.service.GetDueScheduledMessages(ctx, releaseBatchSize)
+	if err != nil {
+		return err
+	}
+	for _, sm := range due {
+		// it is retried on the next release, the others are not held back by it
+		if err = f.releaseMessage(ctx, sm, fn); err != nil {
+			slog.Error("releasing the scheduled message", "id", sm.ID, "err", err)
+		}
+	}
+	return nil
+}
+
+// Helpers & Stuff ----------------------------------------------------------------------------------------------------
+
+// releaseMessage claims sm & sends it in a single tx, so an edit or a cancellation racing the release wins, & sm is
+// retried on the next release if sending it fails for any reason other than being rejected
+func (f *MessageFacade) releaseMessage(
+	ctx context.Context,
+	sm *domain.ScheduledMessage,
+	fn func(ctx context.Context, r ReleasedMessage),
+) error {
+	sndr, err := f.service.GetByUniqueField(ctx, sm.SenderID)
+	if err != nil {
+		return err
+	}
+	ctx = utility.ContextWithUser(ctx, sndr)
+	var msg *domain.Message
+	var convoCreated bool
+	if err = f.txManager.RunInTX(ctx, func(ctx context.Context) error {
+		claimed, err := f.service.ClaimScheduledMessage(ctx, sm)
+		if err != nil || !claimed {
+			return err
+		}
+		msg, convoCreated, err = f.sendMessage(ctx, sm.MessageSent(time.Now()), sndr)
+		var ev *domain.ErrValidation
+		if errors.As(err, &ev) { // e.g. the receiver is deactivated since, retrying will not help
+			slog.Warn("dropping the rejected scheduled message", "id", sm.ID, "errors", ev.Errors)
+			return nil
+		}
+		return err
+	}); err != nil || msg == nil {
+		return err
+	}
+	f.processMessage(ctx, msg)
+	sent := *msg
+	sent.Operation = domain.ScheduledSentMsg
+	// the msg is sent already, without the event the sender only misses it while offline
+	if err = f.service.AppendEventFor(ctx, sndr.ID, &sent); err != nil {
+		slog.Error(err.Error())
+	}
+	fn(ctx, ReleasedMessage{Msg: msg, SentEvent: &sent, ConvoCreated: convoCreated})
+	return nil
+}
@@ -80,8 +80,14 @@ func (t *TokenFacade) GenerateAuthToken(ctx context.Context, u *domain.UserAuth)
 	return otp, nil
 }
 
+// VerifyAuthToken returns the user of the authentication token, or the bot of the api key, the routes a bot may
+// access are restricted by the server middlewares
 func (t *TokenFacade) VerifyAuthToken(ctx context.Context, token string) (*domain.User, error) {
 	usr, err := t.service.GetForToken(ctx, domain.ScopeAuthentication, token)
+	var ev *domain.ErrValidation
+	if errors.As(err, &ev) {
+		usr, err = t.service.GetForToken(ctx, domain.ScopeBot, token)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -59,12 +59,15 @@ func (f *UserFacade) GetByUniqueField(ctx context.Context, fieldValue string) (*
 	return f.service.GetByUniqueField(ctx, fieldValue)
 }
 
-func (f *UserFacade) UpdateUser(ctx context.Context, u *domain.UserUpdate) error {
-	return f.service.UpdateUser(ctx, u)
+func (f *UserFacade) GetByHandle(ctx context.Context, handle string) (*domain.User, bool, error) {
+	return f.service.GetByHandle(ctx, handle)
 }
 
-func (f *UserFacade) UpdateUserOnlineStatus(ctx context.Context, u *domain.User, online bool) error {
-	return f.service.UpdateUserOnlineStatus(ctx, u, online)
+func (f *UserFacade) UpdateUser(ctx context.Context, u *domain.UserUpdate) error {
+	// a handle change also redirects the previous one
+	return f.txManager.RunInTX(ctx, func(ctx context.Context) error {
+		return f.service.UpdateUser(ctx, u)
+	})
 }
 
 func (f *UserFacade) ActivateUser(ctx context.Context, plainToken string) error {
@@ -82,10 +85,10 @@ func (f *UserFacade) ActivateUser(ctx context.Context, plainToken string) error
 
 func (f *UserFacade) SearchUser(
 	ctx context.Context,
-	queryParam string,
-	filter domain.Filter,
-) ([]*domain.User, *domain.Metadata, error) {
-	return f.service.GetByQuery(ctx, queryParam, filter)
+	usrID, queryParam string,
+	filter domain.CursorFilter,
+) ([]*domain.User, *domain.CursorMetadata, error) {
+	return f.service.GetByQuery(ctx, usrID, queryParam, filter)
 }
 
 func (f *UserFacade) SetOnlineUsersLastSeen(ctx context.Context) error {
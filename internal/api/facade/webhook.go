@@ -0,0 +1,156 @@
+package facade
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"github.com/MuhamedUsman/letschat/internal/api/service"
+	"github.com/MuhamedUsman/letschat/internal/api/webhook"
+	"github.com/MuhamedUsman/letschat/internal/common"
+	"github.com/MuhamedUsman/letschat/internal/domain"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// deliveryBatchSize bounds the due webhook deliveries attempted at once, concurrently, by DeliverDueWebhooks
+const deliveryBatchSize = 20
+
+type WebhookFacade struct {
+	service *service.Service
+	bgTask  *common.BackgroundTask
+	sender  *webhook.Sender
+	// a claimed delivery is due again after the lease, if its attempt is never completed e.g. on a crash
+	lease time.Duration
+}
+
+func NewWebhookFacade(service *service.Service,
+	bgTask *common.BackgroundTask,
+	sender *webhook.Sender,
+	lease time.Duration) *WebhookFacade {
+	return &WebhookFacade{
+		service: service,
+		bgTask:  bgTask,
+		sender:  sender,
+		lease:   lease,
+	}
+}
+
+// CreateWebhook subscribes a webhook for u, its secret is generated & only returned here
+func (f *WebhookFacade) CreateWebhook(
+	ctx context.Context,
+	in domain.WebhookInput,
+	u *domain.User,
+) (*domain.Webhook, error) {
+	ev := in.ValidateWebhookInput()
+	if ev.HasErrors() {
+		return nil, ev
+	}
+	// the sender checks each delivery again, as the url may resolve differently by then
+	if err := f.sender.CheckURL(ctx, *in.URL); err != nil {
+		ev.AddError("url", err.Error())
+		return nil, ev
+	}
+	webhooks, err := f.service.GetWebhooks(ctx, u.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(webhooks) >= domain.MaxWebhooksPerUser {
+		ev.AddError("url", fmt.Sprintf("must not have more than %d webhooks", domain.MaxWebhooksPerUser))
+		return nil, ev
+	}
+	w := &domain.Webhook{
+		UserID: u.ID,
+		URL:    *in.URL,
+		Events: in.Events,
+		Secret: rand.Text(),
+	}
+	if err = f.service.CreateWebhook(ctx, w); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (f *WebhookFacade) GetWebhooks(ctx context.Context, u *domain.User) ([]*domain.Webhook, error) {
+	return f.service.GetWebhooks(ctx, u.ID)
+}
+
+func (f *WebhookFacade) DeleteWebhook(ctx context.Context, id string, u *domain.User) error {
+	return f.service.DeleteWebhook(ctx, id, u.ID)
+}
+
+// GetWebhookDeliveries returns the delivery log of the webhook id of u, newest first
+func (f *WebhookFacade) GetWebhookDeliveries(
+	ctx context.Context,
+	id string,
+	u *domain.User,
+) ([]*domain.WebhookDelivery, error) {
+	w, err := f.service.GetWebhook(ctx, id, u.ID)
+	if err != nil {
+		return nil, err
+	}
+	return f.service.GetWebhookDeliveries(ctx, w.ID, domain.MaxWebhookDeliveries)
+}
+
+// EmitMessageWebhooks queues msg to the webhooks of its receiver in the background, if its Op is a webhook event
+func (f *WebhookFacade) EmitMessageWebhooks(msg *domain.Message) {
+	event := msg.Operation.WebhookEvent()
+	if event == "" {
+		return
+	}
+	p := domain.WebhookPayload{Event: event, CreatedAt: time.Now(), Message: msg}
+	f.emit(func(ctx context.Context) error {
+		return f.service.EnqueueWebhookDeliveries(ctx, msg.ReceiverID, p)
+	})
+}
+
+// EmitPresenceWebhooks queues the settled presence p to the webhooks of its conversation partners in the background
+func (f *WebhookFacade) EmitPresenceWebhooks(p domain.Presence) {
+	payload := domain.WebhookPayload{Event: domain.WebhookEventPresence, CreatedAt: time.Now(), Presence: &p}
+	f.emit(func(ctx context.Context) error {
+		return f.service.EnqueuePartnerWebhookDeliveries(ctx, p.UserID, payload)
+	})
+}
+
+// DeliverDueWebhooks attempts the due deliveries concurrently, a failed one is retried after its backoff
+func (f *WebhookFacade) DeliverDueWebhooks(ctx context.Context) error {
+	due, err := f.service.ClaimDueWebhookDeliveries(ctx, deliveryBatchSize, f.lease)
+	if err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
+	for _, d := range due {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			status, sendErr := f.sender.Send(ctx, d)
+			if err := f.service.CompleteWebhookDelivery(ctx, d, status, sendErr); err != nil {
+				slog.Error(err.Error())
+			}
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// PurgeWebhookDeliveries deletes the delivered & failed deliveries older than the retention from the log
+func (f *WebhookFacade) PurgeWebhookDeliveries(ctx context.Context, retention time.Duration) error {
+	n, err := f.service.PurgeWebhookDeliveries(ctx, time.Now().Add(-retention))
+	if n > 0 {
+		slog.Info("purged webhook deliveries", "count", n)
+	}
+	return err
+}
+
+// Helpers & Stuff ----------------------------------------------------------------------------------------------------
+
+// emit runs fn in the background, detached from the caller, so queueing never holds up the relay of the event
+func (f *WebhookFacade) emit(fn func(ctx context.Context) error) {
+	f.bgTask.Run(func(context.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := fn(ctx); err != nil {
+			slog.Error(err.Error())
+		}
+	})
+}
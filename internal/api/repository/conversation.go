@@ -47,6 +47,10 @@ func (r *ConversationRepository) GetConversations(ctx context.Context, usrID str
 	            WHEN sender_id = $1 THEN receiver.name
 	            ELSE sender.name
 	        END AS username,
+	        CASE 
+	            WHEN sender_id = $1 THEN receiver.handle
+	            ELSE sender.handle
+	        END AS user_handle,
 	        CASE 
 	            WHEN sender_id = $1 THEN receiver.email
 	            ELSE sender.email
@@ -54,7 +58,28 @@ func (r *ConversationRepository) GetConversations(ctx context.Context, usrID str
 	        CASE 
 	            WHEN sender_id = $1 THEN receiver.last_online
 	            ELSE sender.last_online
-	        END AS last_online
+	        END AS last_online,
+	        CASE 
+	            WHEN sender_id = $1 THEN receiver.status_text
+	            ELSE sender.status_text
+	        END AS status_text,
+	        CASE 
+	            WHEN sender_id = $1 THEN receiver.status_emoji
+	            ELSE sender.status_emoji
+	        END AS status_emoji,
+	        CASE 
+	            WHEN sender_id = $1 THEN receiver.status_expires_at
+	            ELSE sender.status_expires_at
+	        END AS status_expires_at,
+	        CASE 
+	            WHEN sender_id = $1 THEN receiver.bio
+	            ELSE sender.bio
+	        END AS bio,
+	        CASE 
+	            WHEN sender_id = $1 THEN receiver.is_bot
+	            ELSE sender.is_bot
+	        END AS user_is_bot,
+	        disappear_after
 		FROM conversation
 		    INNER JOIN users sender ON sender_id = sender.id
 		    INNER JOIN users receiver ON receiver_id = receiver.id
@@ -98,3 +123,49 @@ func (r *ConversationRepository) ConversationExists(ctx context.Context, senderI
 	}
 	return exists, nil
 }
+
+func (r *ConversationRepository) SetDisappearAfter(
+	ctx context.Context,
+	senderID, receiverID string,
+	secs int64,
+) error {
+	query := `
+		UPDATE conversation
+		SET disappear_after = $3
+		WHERE (sender_id = $1 AND receiver_id = $2) OR (sender_id = $2 AND receiver_id = $1)
+		`
+	var res sql.Result
+	var err error
+	if tx := contextGetTX(ctx); tx != nil {
+		res, err = tx.ExecContext(ctx, query, senderID, receiverID, secs)
+	} else {
+		res, err = r.DB.ExecContext(ctx, query, senderID, receiverID, secs)
+	}
+	if err != nil {
+		return err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return domain.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *ConversationRepository) GetDisappearAfter(ctx context.Context, senderID, receiverID string) (int64, error) {
+	query := `
+		SELECT COALESCE(MAX(disappear_after), 0) -- zero if there is no conversation
+		FROM conversation
+		WHERE (sender_id = $1 AND receiver_id = $2) OR (sender_id = $2 AND receiver_id = $1)
+		`
+	var secs int64
+	var err error
+	if tx := contextGetTX(ctx); tx != nil {
+		err = tx.QueryRowContext(ctx, query, senderID, receiverID).Scan(&secs)
+	} else {
+		err = r.DB.QueryRowContext(ctx, query, senderID, receiverID).Scan(&secs)
+	}
+	return secs, err
+}
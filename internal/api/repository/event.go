@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/MuhamedUsman/letschat/internal/domain"
+	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+var _ domain.EventRepository = (*EventRepository)(nil)
+
+type EventRepository struct {
+	db *DB
+}
+
+func NewEventRepository(db *DB) *EventRepository {
+	return &EventRepository{db}
+}
+
+// Append appends m to the event log of the user, in the tx of the context if any. The appends of a user are
+// serialised by an advisory lock held till the tx commits, so the cursor is drawn only after the previous event of
+// the user is committed, else a lower cursor committing after a higher one is acked would be deleted unseen
+func (r *EventRepository) Append(ctx context.Context, usrID string, m *domain.Message) (int64, error) {
+	tx := contextGetTX(ctx)
+	if tx == nil {
+		var cursor int64
+		err := r.db.RunInTX(ctx, func(ctx context.Context) error {
+			var err error
+			cursor, err = r.Append(ctx, usrID, m)
+			return err
+		})
+		return cursor, err
+	}
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('event:' || $1))`, usrID); err != nil {
+		return 0, err
+	}
+	query := `
+		INSERT INTO event (
+			user_id, id, sender_id, receiver_id, body, sent_at, delivered_at, read_at, operation, up_to, expires_at,
+			disappear_after, pinned_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING cursor
+		`
+	args := []any{
+		usrID, m.ID, m.SenderID, m.ReceiverID, m.Body, m.SentAt, m.DeliveredAt, m.ReadAt, m.Operation, m.UpTo,
+		m.ExpiresAt, m.DisappearAfter, m.PinnedAt,
+	}
+	var cursor int64
+	err := tx.QueryRowxContext(ctx, query, args...).Scan(&cursor)
+	return cursor, err
+}
+
+func (r *EventRepository) GetSince(ctx context.Context, usrID string, since int64, limit int) ([]*domain.Message, error) {
+	query := `
+		SELECT cursor, id, sender_id, receiver_id, body, sent_at, delivered_at, read_at, operation, up_to, expires_at,
+		       disappear_after, pinned_at
+		FROM event
+		WHERE user_id = $1 AND cursor > $2
+		ORDER BY cursor
+		LIMIT $3
+		`
+	var rows *sqlx.Rows
+	var err error
+	if tx := contextGetTX(ctx); tx != nil {
+		rows, err = tx.QueryxContext(ctx, query, usrID, since, limit)
+	} else {
+		rows, err = r.db.QueryxContext(ctx, query, usrID, since, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	events := make([]*domain.Message, 0, limit)
+	for rows.Next() {
+		var msg domain.Message
+		if err = rows.StructScan(&msg); err != nil {
+			return nil, err
+		}
+		events = append(events, &msg)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// DeleteUntil deletes the events of the user up to & including the cursor, the client has persisted them already
+func (r *EventRepository) DeleteUntil(ctx context.Context, usrID string, cursor int64) error {
+	query := `
+		DELETE FROM event
+		WHERE user_id = $1 AND cursor <= $2
+		`
+	if tx := contextGetTX(ctx); tx != nil {
+		_, err := tx.ExecContext(ctx, query, usrID, cursor)
+		return err
+	}
+	_, err := r.db.ExecContext(ctx, query, usrID, cursor)
+	return err
+}
+
+// DeleteExpired deletes the events whose expires_at is passed by until, of every user
+func (r *EventRepository) DeleteExpired(ctx context.Context, until time.Time) (int64, error) {
+	query := `
+		DELETE FROM event
+		WHERE expires_at <= $1
+		`
+	var res sql.Result
+	var err error
+	if tx := contextGetTX(ctx); tx != nil {
+		res, err = tx.ExecContext(ctx, query, until)
+	} else {
+		res, err = r.db.ExecContext(ctx, query, until)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
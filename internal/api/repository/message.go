@@ -2,8 +2,10 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"github.com/MuhamedUsman/letschat/internal/domain"
-	"github.com/jmoiron/sqlx"
+	"time"
 )
 
 var _ domain.MessageRepository = (*MessageRepository)(nil)
@@ -32,37 +34,53 @@ func (r *MessageRepository) GetByID(ctx context.Context, id string, op domain.Ms
 	return &message, err
 }
 
-func (r *MessageRepository) GetUnDeliveredMessages(ctx context.Context, rcvrID string, op domain.MsgOperation, c domain.MsgChan) error {
+// ClaimOwner inserts o, on conflict the no-op update makes RETURNING yield the row recorded before instead
+func (r *MessageRepository) ClaimOwner(ctx context.Context, o domain.MessageOwner) (*domain.MessageOwner, error) {
 	query := `
-		SELECT *
-		FROM message
-		WHERE receiver_id = $1 AND operation = $2
-		ORDER BY sent_at
+		INSERT INTO message_owner (id, sender_id, receiver_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (id)
+		DO UPDATE SET id = message_owner.id
+		RETURNING id, sender_id, receiver_id
 		`
-	var rows *sqlx.Rows
+	var owner domain.MessageOwner
+	var err error
 	if tx := contextGetTX(ctx); tx != nil {
-		rows, _ = tx.QueryxContext(ctx, query, rcvrID, op)
+		err = tx.QueryRowxContext(ctx, query, o.ID, o.SenderID, o.ReceiverID).StructScan(&owner)
 	} else {
-		rows, _ = r.db.QueryxContext(ctx, query, rcvrID, op)
+		err = r.db.QueryRowxContext(ctx, query, o.ID, o.SenderID, o.ReceiverID).StructScan(&owner)
 	}
-	defer rows.Close()
-	for rows.Next() {
-		var msg domain.Message
-		if err := rows.StructScan(&msg); err != nil {
-			return err
-		}
-		c <- &msg
+	if err != nil {
+		return nil, err
 	}
-	if err := rows.Err(); err != nil {
-		return rows.Err()
+	return &owner, nil
+}
+
+func (r *MessageRepository) GetOwner(ctx context.Context, id string) (*domain.MessageOwner, error) {
+	query := `
+		SELECT id, sender_id, receiver_id FROM message_owner
+		WHERE id = $1
+		`
+	var owner domain.MessageOwner
+	var err error
+	if tx := contextGetTX(ctx); tx != nil {
+		err = tx.QueryRowxContext(ctx, query, id).StructScan(&owner)
+	} else {
+		err = r.db.QueryRowxContext(ctx, query, id).StructScan(&owner)
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrRecordNotFound
+		}
+		return nil, err
 	}
-	return nil
+	return &owner, nil
 }
 
 func (r *MessageRepository) InsertMessage(ctx context.Context, m *domain.Message) error {
 	query := `
-		INSERT INTO message (id, sender_id, receiver_id, body, sent_at, delivered_at, read_at, operation) 
-		VALUES (:id, :sender_id, :receiver_id, :body, :sent_at, :delivered_at, :read_at, :operation)
+		INSERT INTO message (id, sender_id, receiver_id, body, sent_at, delivered_at, read_at, operation, expires_at) 
+		VALUES (:id, :sender_id, :receiver_id, :body, :sent_at, :delivered_at, :read_at, :operation, :expires_at)
 		ON CONFLICT (id)
 		DO UPDATE SET
 		              sender_id = EXCLUDED.sender_id,
@@ -71,7 +89,8 @@ func (r *MessageRepository) InsertMessage(ctx context.Context, m *domain.Message
 		              sent_at = EXCLUDED.sent_at,
 		              delivered_at = EXCLUDED.delivered_at,
 		              read_at = EXCLUDED.read_at,
-		              operation = EXCLUDED.operation
+		              operation = EXCLUDED.operation,
+		              expires_at = EXCLUDED.expires_at
 		`
 	if tx := contextGetTX(ctx); tx != nil {
 		_, err := tx.NamedExecContext(ctx, query, m)
@@ -93,3 +112,47 @@ func (r *MessageRepository) DeleteMessage(ctx context.Context, mID string) error
 	_, err := r.db.ExecContext(ctx, query, mID)
 	return err
 }
+
+// DeleteCreatedUpTo deletes the msgs from the sender to the receiver sent up to upTo, still pending as created, the
+// watermark of the receiver acknowledges them at once, returns the count deleted
+func (r *MessageRepository) DeleteCreatedUpTo(
+	ctx context.Context,
+	senderID, receiverID string,
+	upTo time.Time,
+) (int64, error) {
+	query := `
+		DELETE FROM message
+		WHERE sender_id = $1 AND receiver_id = $2 AND operation = $3 AND sent_at <= $4
+		`
+	args := []any{senderID, receiverID, domain.CreateMsg, upTo}
+	var res sql.Result
+	var err error
+	if tx := contextGetTX(ctx); tx != nil {
+		res, err = tx.ExecContext(ctx, query, args...)
+	} else {
+		res, err = r.db.ExecContext(ctx, query, args...)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// DeleteExpired deletes the msgs whose expires_at is passed by until, returns the count deleted
+func (r *MessageRepository) DeleteExpired(ctx context.Context, until time.Time) (int64, error) {
+	query := `
+		DELETE FROM message
+		WHERE expires_at <= $1
+		`
+	var res sql.Result
+	var err error
+	if tx := contextGetTX(ctx); tx != nil {
+		res, err = tx.ExecContext(ctx, query, until)
+	} else {
+		res, err = r.db.ExecContext(ctx, query, until)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/MuhamedUsman/letschat/internal/domain"
+	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+var _ domain.ScheduledMessageRepository = (*ScheduledMessageRepository)(nil)
+
+type ScheduledMessageRepository struct {
+	db *DB
+}
+
+func NewScheduledMessageRepository(db *DB) *ScheduledMessageRepository {
+	return &ScheduledMessageRepository{db}
+}
+
+func (r *ScheduledMessageRepository) Insert(ctx context.Context, sm *domain.ScheduledMessage) error {
+	query := `
+		INSERT INTO scheduled_message (id, sender_id, receiver_id, body, send_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at, version
+		`
+	args := []any{sm.ID, sm.SenderID, sm.ReceiverID, sm.Body, sm.SendAt}
+	if tx := contextGetTX(ctx); tx != nil {
+		return tx.QueryRowxContext(ctx, query, args...).Scan(&sm.CreatedAt, &sm.Version)
+	}
+	return r.db.QueryRowxContext(ctx, query, args...).Scan(&sm.CreatedAt, &sm.Version)
+}
+
+func (r *ScheduledMessageRepository) GetByID(ctx context.Context, id, senderID string) (*domain.ScheduledMessage, error) {
+	query := `
+		SELECT id, sender_id, receiver_id, body, send_at, created_at, version
+		FROM scheduled_message
+		WHERE id = $1 AND sender_id = $2
+		`
+	var sm domain.ScheduledMessage
+	var err error
+	if tx := contextGetTX(ctx); tx != nil {
+		err = tx.QueryRowxContext(ctx, query, id, senderID).StructScan(&sm)
+	} else {
+		err = r.db.QueryRowxContext(ctx, query, id, senderID).StructScan(&sm)
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &sm, nil
+}
+
+func (r *ScheduledMessageRepository) GetBySender(
+	ctx context.Context,
+	senderID, receiverID string,
+) ([]*domain.ScheduledMessage, error) {
+	query := `
+		SELECT id, sender_id, receiver_id, body, send_at, created_at, version
+		FROM scheduled_message
+		WHERE sender_id = $1 AND ($2 = '' OR receiver_id::TEXT = $2)
+		ORDER BY send_at, created_at
+		`
+	var rows *sqlx.Rows
+	var err error
+	if tx := contextGetTX(ctx); tx != nil {
+		rows, err = tx.QueryxContext(ctx, query, senderID, receiverID)
+	} else {
+		rows, err = r.db.QueryxContext(ctx, query, senderID, receiverID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return scanScheduledMessages(rows)
+}
+
+func (r *ScheduledMessageRepository) Update(ctx context.Context, sm *domain.ScheduledMessage) error {
+	query := `
+		UPDATE scheduled_message
+		SET body = $1, send_at = $2, version = version + 1
+		WHERE id = $3 AND sender_id = $4 AND version = $5
+		RETURNING version
+		`
+	args := []any{sm.Body, sm.SendAt, sm.ID, sm.SenderID, sm.Version}
+	var err error
+	if tx := contextGetTX(ctx); tx != nil {
+		err = tx.QueryRowxContext(ctx, query, args...).Scan(&sm.Version)
+	} else {
+		err = r.db.QueryRowxContext(ctx, query, args...).Scan(&sm.Version)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ErrEditConflict
+	}
+	return err
+}
+
+func (r *ScheduledMessageRepository) Delete(ctx context.Context, id, senderID string) error {
+	query := `
+		DELETE FROM scheduled_message
+		WHERE id = $1 AND sender_id = $2
+		`
+	var res sql.Result
+	var err error
+	if tx := contextGetTX(ctx); tx != nil {
+		res, err = tx.ExecContext(ctx, query, id, senderID)
+	} else {
+		res, err = r.db.ExecContext(ctx, query, id, senderID)
+	}
+	if err != nil {
+		return err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return domain.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *ScheduledMessageRepository) GetDue(
+	ctx context.Context,
+	until time.Time,
+	limit int,
+) ([]*domain.ScheduledMessage, error) {
+	query := `
+		SELECT id, sender_id, receiver_id, body, send_at, created_at, version
+		FROM scheduled_message
+		WHERE send_at <= $1
+		ORDER BY send_at, created_at
+		LIMIT $2
+		`
+	var rows *sqlx.Rows
+	var err error
+	if tx := contextGetTX(ctx); tx != nil {
+		rows, err = tx.QueryxContext(ctx, query, until, limit)
+	} else {
+		rows, err = r.db.QueryxContext(ctx, query, until, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return scanScheduledMessages(rows)
+}
+
+func (r *ScheduledMessageRepository) DeleteVersion(ctx context.Context, id string, version int) (bool, error) {
+	query := `
+		DELETE FROM scheduled_message
+		WHERE id = $1 AND version = $2
+		`
+	var res sql.Result
+	var err error
+	if tx := contextGetTX(ctx); tx != nil {
+		res, err = tx.ExecContext(ctx, query, id, version)
+	} else {
+		res, err = r.db.ExecContext(ctx, query, id, version)
+	}
+	if err != nil {
+		return false, err
+	}
+	count, err := res.RowsAffected()
+	return count > 0, err
+}
+
+// Helpers & Stuff ----------------------------------------------------------------------------------------------------
+
+func scanScheduledMessages(rows *sqlx.Rows) ([]*domain.ScheduledMessage, error) {
+	defer rows.Close()
+	sms := make([]*domain.ScheduledMessage, 0)
+	for rows.Next() {
+		var sm domain.ScheduledMessage
+		if err := rows.StructScan(&sm); err != nil {
+			return nil, err
+		}
+		sms = append(sms, &sm)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return sms, nil
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jmoiron/sqlx"
 	"log/slog"
+	"strings"
 	"time"
 )
 
@@ -26,11 +27,11 @@ func (r *UserRepository) RegisterUser(ctx context.Context, u *domain.User) (stri
 	// it takes less time around 2 ms if we check like this the approach below takes 400+ ms
 	tx := contextGetTX(ctx)
 	query := `
-		INSERT INTO users (name, email, password)
-		VALUES ($1, $2, $3)
+		INSERT INTO users (name, handle, email, password)
+		VALUES ($1, $2, $3, $4)
 		RETURNING id
 		`
-	args := []any{u.Name, u.Email, u.Password}
+	args := []any{u.Name, u.Handle, u.Email, u.Password}
 	var userID string
 	var err error
 	if tx != nil {
@@ -44,6 +45,9 @@ func (r *UserRepository) RegisterUser(ctx context.Context, u *domain.User) (stri
 			if pgErr.Code == "23505" && pgErr.ConstraintName == "users_email_key" { // 400+ ms
 				return "", domain.ErrDuplicateEmail
 			}
+			if pgErr.Code == "23505" && pgErr.ConstraintName == "users_handle_key" {
+				return "", domain.ErrDuplicateHandle
+			}
 		}
 	}
 	return userID, nil
@@ -92,7 +96,9 @@ func (r *UserRepository) GetByUniqueField(ctx context.Context, fieldName, fieldV
 func (r *UserRepository) UpdateUser(ctx context.Context, u *domain.User) error {
 	query := `
 		UPDATE users 
-		SET name = :name, email = :email, password = :password, last_online = :last_online, version = version + 1
+		SET name = :name, handle = :handle, email = :email, password = :password, last_online = :last_online,
+		    status_text = :status_text, status_emoji = :status_emoji, status_expires_at = :status_expires_at,
+		    bio = :bio, version = version + 1
 		WHERE id = :id AND version = :version
 		`
 	tx := contextGetTX(ctx)
@@ -104,6 +110,10 @@ func (r *UserRepository) UpdateUser(ctx context.Context, u *domain.User) error {
 		editStatus, err = r.db.NamedExecContext(ctx, query, u)
 	}
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "users_handle_key" {
+			return domain.ErrDuplicateHandle
+		}
 		return err
 	}
 	rowsAffected, err := editStatus.RowsAffected()
@@ -169,42 +179,76 @@ func (r *UserRepository) ActivateUser(ctx context.Context, user *domain.User) er
 
 func (r *UserRepository) GetByQuery(
 	ctx context.Context,
-	paramName, paramValue string,
-	filter domain.Filter,
-) ([]*domain.User, *domain.Metadata, error) {
-	query := fmt.Sprintf(`
-	SELECT COUNT(*) OVER() total, *
-	FROM users
-	WHERE STRICT_WORD_SIMILARITY($1, %v) > 0.5 AND activated = TRUE
-	ORDER BY STRICT_WORD_SIMILARITY($1, %v)
-	LIMIT $2
-	OFFSET $3
-	`, paramName, paramName)
-	args := []any{paramValue, filter.Limit(), filter.Offset()}
+	usrID, queryParam string,
+	filter domain.CursorFilter,
+) ([]*domain.User, *domain.CursorMetadata, error) {
+	// rank is the best of the name, handle & email prefix matches, boosted for the existing conversation partners,
+	// keyset paginated on (rank, id) as the rank of the users change between the pages anyway
+	query := `
+	WITH ranked AS (
+		SELECT u.*, ROUND((GREATEST(
+				WORD_SIMILARITY($1, u.name),
+				CASE WHEN u.handle::TEXT ILIKE $2 THEN 1 ELSE COALESCE(SIMILARITY($1, u.handle::TEXT), 0) END,
+				CASE WHEN u.email::TEXT ILIKE $2 THEN 0.9 ELSE 0 END
+			) + CASE WHEN EXISTS (
+				SELECT 1 FROM conversation c
+				WHERE (c.sender_id = $3 AND c.receiver_id = u.id) OR (c.sender_id = u.id AND c.receiver_id = $3)
+			) THEN 0.5 ELSE 0 END)::NUMERIC, 4) AS rank
+		FROM users u
+		WHERE u.activated = TRUE
+		AND u.id <> $3
+		AND ($1 <% u.name OR $1 % u.handle::TEXT OR u.handle::TEXT ILIKE $2 OR u.email::TEXT ILIKE $2)
+	)
+	SELECT * FROM ranked
+	WHERE $5::NUMERIC IS NULL OR rank < $5 OR (rank = $5 AND id > $6::UUID)
+	ORDER BY rank DESC, id
+	LIMIT $4
+	`
+	var afterRank *float64
+	var afterID *string
+	if filter.After != "" {
+		rank, id, err := domain.DecodeCursor(filter.After)
+		if err != nil {
+			return nil, nil, err
+		}
+		afterRank, afterID = &rank, &id
+	}
+	// one more than the page size, telling if there is a next page
+	args := []any{queryParam, escapeLike(queryParam) + "%", usrID, filter.PageSize + 1, afterRank, afterID}
 	var rows *sqlx.Rows
+	var err error
 	if tx := contextGetTX(ctx); tx != nil {
-		rows, _ = tx.QueryxContext(ctx, query, args...)
+		rows, err = tx.QueryxContext(ctx, query, args...)
 	} else {
-		rows, _ = r.db.QueryxContext(ctx, query, args...)
+		rows, err = r.db.QueryxContext(ctx, query, args...)
+	}
+	if err != nil {
+		return nil, nil, err
 	}
 	defer rows.Close()
-	var total int
-	users := make([]*domain.User, 0)
+	users := make([]*domain.User, 0, filter.PageSize)
+	var metadata domain.CursorMetadata
+	var lastRank float64
 	for rows.Next() {
 		var row struct {
-			Total int `db:"total"`
+			Rank float64 `db:"rank"`
 			domain.User
 		}
-		if err := rows.StructScan(&row); err != nil {
-			return nil, &domain.Metadata{}, err
+		if err = rows.StructScan(&row); err != nil {
+			return nil, nil, err
+		}
+		if len(users) == filter.PageSize {
+			last := users[len(users)-1]
+			metadata.NextCursor = domain.EncodeCursor(lastRank, last.ID)
+			break
 		}
-		total = row.Total
+		lastRank = row.Rank
 		users = append(users, &row.User)
 	}
-	if err := rows.Err(); err != nil {
-		return nil, &domain.Metadata{}, err
+	if err = rows.Err(); err != nil {
+		return nil, nil, err
 	}
-	metadata := domain.CalculateMetadata(total, filter.PageSize, filter.Page)
+	metadata.PageSize = filter.PageSize
 	return users, &metadata, nil
 }
 
@@ -222,3 +266,179 @@ func (r *UserRepository) SetOnlineUsersLastSeen(ctx context.Context, t time.Time
 	}
 	return err
 }
+
+func (r *UserRepository) SetLastOnline(ctx context.Context, usrID string, t *time.Time) error {
+	query := `
+		UPDATE users 
+		SET last_online = $2
+		WHERE id = $1
+	`
+	var err error
+	if tx := contextGetTX(ctx); tx != nil {
+		_, err = tx.ExecContext(ctx, query, usrID, t)
+	} else {
+		_, err = r.db.ExecContext(ctx, query, usrID, t)
+	}
+	return err
+}
+
+// GetLastOnline returns the persisted last_online of the existing users of usrIDs, keyed by their id
+func (r *UserRepository) GetLastOnline(ctx context.Context, usrIDs []string) (map[string]*time.Time, error) {
+	query := `
+		SELECT id, last_online
+		FROM users
+		WHERE id = ANY($1)
+	`
+	var rows *sqlx.Rows
+	var err error
+	if tx := contextGetTX(ctx); tx != nil {
+		rows, err = tx.QueryxContext(ctx, query, usrIDs)
+	} else {
+		rows, err = r.db.QueryxContext(ctx, query, usrIDs)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	lastOnline := make(map[string]*time.Time, len(usrIDs))
+	for rows.Next() {
+		var id string
+		var t *time.Time
+		if err = rows.Scan(&id, &t); err != nil {
+			return nil, err
+		}
+		lastOnline[id] = t
+	}
+	return lastOnline, rows.Err()
+}
+
+func (r *UserRepository) GetByHandleRedirect(ctx context.Context, handle string) (*domain.User, error) {
+	query := `
+		SELECT u.* 
+		FROM handle_redirect hr
+		    INNER JOIN users u ON hr.user_id = u.id
+		WHERE hr.handle = $1 AND hr.expires_at > NOW()
+	`
+	var usr domain.User
+	var err error
+	if tx := contextGetTX(ctx); tx != nil {
+		err = tx.QueryRowxContext(ctx, query, handle).StructScan(&usr)
+	} else {
+		err = r.db.QueryRowxContext(ctx, query, handle).StructScan(&usr)
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	return &usr, nil
+}
+
+func (r *UserRepository) HandleTaken(ctx context.Context, handle, exceptUsrID string) (bool, error) {
+	query := `
+		SELECT EXISTS(SELECT TRUE FROM users WHERE handle = $1 AND id::TEXT <> $2) 
+		    OR EXISTS(SELECT TRUE FROM handle_redirect WHERE handle = $1 AND expires_at > NOW() AND user_id::TEXT <> $2)
+	`
+	var taken bool
+	var err error
+	if tx := contextGetTX(ctx); tx != nil {
+		err = tx.QueryRowContext(ctx, query, handle, exceptUsrID).Scan(&taken)
+	} else {
+		err = r.db.QueryRowContext(ctx, query, handle, exceptUsrID).Scan(&taken)
+	}
+	return taken, err
+}
+
+func (r *UserRepository) AddHandleRedirect(ctx context.Context, handle, usrID string, expiresAt time.Time) error {
+	// an expired redirect of the same handle is replaced
+	query := `
+		INSERT INTO handle_redirect (handle, user_id, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (handle) DO UPDATE 
+		SET user_id = EXCLUDED.user_id, expires_at = EXCLUDED.expires_at
+	`
+	var err error
+	if tx := contextGetTX(ctx); tx != nil {
+		_, err = tx.ExecContext(ctx, query, handle, usrID, expiresAt)
+	} else {
+		_, err = r.db.ExecContext(ctx, query, handle, usrID, expiresAt)
+	}
+	return err
+}
+
+func (r *UserRepository) DeleteHandleRedirect(ctx context.Context, handle string) error {
+	query := `DELETE FROM handle_redirect WHERE handle = $1`
+	var err error
+	if tx := contextGetTX(ctx); tx != nil {
+		_, err = tx.ExecContext(ctx, query, handle)
+	} else {
+		_, err = r.db.ExecContext(ctx, query, handle)
+	}
+	return err
+}
+
+func (r *UserRepository) RegisterBot(ctx context.Context, u *domain.User) (string, error) {
+	query := `
+		INSERT INTO users (name, handle, email, password, activated, is_bot, bot_owner_id)
+		VALUES ($1, $2, $3, $4, TRUE, TRUE, $5)
+		RETURNING id
+		`
+	args := []any{u.Name, u.Handle, u.Email, u.Password, u.BotOwnerID}
+	var botID string
+	var err error
+	if tx := contextGetTX(ctx); tx != nil {
+		err = tx.QueryRowxContext(ctx, query, args...).Scan(&botID)
+	} else {
+		err = r.db.QueryRowxContext(ctx, query, args...).Scan(&botID)
+	}
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			if pgErr.ConstraintName == "users_handle_key" {
+				return "", domain.ErrDuplicateHandle
+			}
+			if pgErr.ConstraintName == "users_email_key" {
+				return "", domain.ErrDuplicateEmail
+			}
+		}
+		return "", err
+	}
+	return botID, nil
+}
+
+func (r *UserRepository) GetBots(ctx context.Context, ownerID string) ([]*domain.User, error) {
+	query := `
+		SELECT *
+		FROM users
+		WHERE bot_owner_id = $1
+		ORDER BY created_at
+	`
+	var rows *sqlx.Rows
+	var err error
+	if tx := contextGetTX(ctx); tx != nil {
+		rows, err = tx.QueryxContext(ctx, query, ownerID)
+	} else {
+		rows, err = r.db.QueryxContext(ctx, query, ownerID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	bots := make([]*domain.User, 0)
+	for rows.Next() {
+		var bot domain.User
+		if err = rows.StructScan(&bot); err != nil {
+			return nil, err
+		}
+		bots = append(bots, &bot)
+	}
+	return bots, rows.Err()
+}
+
+// Helpers & Stuff -----------------------------------------------------------------------------------------------------
+
+// escapeLike escapes the wildcards of s, so it matches literally in a LIKE pattern
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
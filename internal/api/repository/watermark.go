@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"github.com/MuhamedUsman/letschat/internal/domain"
+	"time"
+)
+
+var _ domain.WatermarkRepository = (*WatermarkRepository)(nil)
+
+type WatermarkRepository struct {
+	db *DB
+}
+
+func NewWatermarkRepository(db *DB) *WatermarkRepository {
+	return &WatermarkRepository{db}
+}
+
+// Advance upserts the watermark, GREATEST ignores NULLs so a DeliveredUpToMsg leaves read_up_to as is,
+// a ReadUpToMsg also advances delivered_up_to, as a read msg is delivered too
+func (r *WatermarkRepository) Advance(ctx context.Context, m *domain.Message) error {
+	query := `
+		INSERT INTO watermark (user_id, peer_id, delivered_up_to, read_up_to)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, peer_id)
+		DO UPDATE SET
+		              delivered_up_to = GREATEST(watermark.delivered_up_to, EXCLUDED.delivered_up_to),
+		              read_up_to = GREATEST(watermark.read_up_to, EXCLUDED.read_up_to)
+		`
+	var readUpTo *time.Time
+	if m.Operation == domain.ReadUpToMsg {
+		readUpTo = m.UpTo
+	}
+	args := []any{m.SenderID, m.ReceiverID, m.UpTo, readUpTo}
+	if tx := contextGetTX(ctx); tx != nil {
+		_, err := tx.ExecContext(ctx, query, args...)
+		return err
+	}
+	_, err := r.db.ExecContext(ctx, query, args...)
+	return err
+}
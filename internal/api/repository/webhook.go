@@ -0,0 +1,272 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/MuhamedUsman/letschat/internal/domain"
+	"github.com/jmoiron/sqlx"
+	"strings"
+	"time"
+)
+
+var _ domain.WebhookRepository = (*WebhookRepository)(nil)
+
+type WebhookRepository struct {
+	db *DB
+}
+
+func NewWebhookRepository(db *DB) *WebhookRepository {
+	return &WebhookRepository{db}
+}
+
+func (r *WebhookRepository) Insert(ctx context.Context, w *domain.Webhook) error {
+	query := `
+		INSERT INTO webhook (user_id, url, events, secret)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+		`
+	args := []any{w.UserID, w.URL, w.Events, w.Secret}
+	var err error
+	if tx := contextGetTX(ctx); tx != nil {
+		err = tx.QueryRowxContext(ctx, query, args...).Scan(&w.ID, &w.CreatedAt)
+	} else {
+		err = r.db.QueryRowxContext(ctx, query, args...).Scan(&w.ID, &w.CreatedAt)
+	}
+	return err
+}
+
+func (r *WebhookRepository) GetByUser(ctx context.Context, usrID string) ([]*domain.Webhook, error) {
+	query := `
+		SELECT id, user_id, url, ARRAY_TO_STRING(events, ','), created_at
+		FROM webhook
+		WHERE user_id = $1
+		ORDER BY created_at
+		`
+	var rows *sqlx.Rows
+	var err error
+	if tx := contextGetTX(ctx); tx != nil {
+		rows, err = tx.QueryxContext(ctx, query, usrID)
+	} else {
+		rows, err = r.db.QueryxContext(ctx, query, usrID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	webhooks := make([]*domain.Webhook, 0)
+	for rows.Next() {
+		var w domain.Webhook
+		var events string
+		if err = rows.Scan(&w.ID, &w.UserID, &w.URL, &events, &w.CreatedAt); err != nil {
+			return nil, err
+		}
+		w.Events = strings.Split(events, ",")
+		webhooks = append(webhooks, &w)
+	}
+	return webhooks, rows.Err()
+}
+
+func (r *WebhookRepository) GetByID(ctx context.Context, id, usrID string) (*domain.Webhook, error) {
+	query := `
+		SELECT id, user_id, url, ARRAY_TO_STRING(events, ','), created_at
+		FROM webhook
+		WHERE id = $1 AND user_id = $2
+		`
+	var w domain.Webhook
+	var events string
+	dst := []any{&w.ID, &w.UserID, &w.URL, &events, &w.CreatedAt}
+	var err error
+	if tx := contextGetTX(ctx); tx != nil {
+		err = tx.QueryRowxContext(ctx, query, id, usrID).Scan(dst...)
+	} else {
+		err = r.db.QueryRowxContext(ctx, query, id, usrID).Scan(dst...)
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	w.Events = strings.Split(events, ",")
+	return &w, nil
+}
+
+func (r *WebhookRepository) Delete(ctx context.Context, id, usrID string) error {
+	query := `
+		DELETE FROM webhook
+		WHERE id = $1 AND user_id = $2
+		`
+	var res sql.Result
+	var err error
+	if tx := contextGetTX(ctx); tx != nil {
+		res, err = tx.ExecContext(ctx, query, id, usrID)
+	} else {
+		res, err = r.db.ExecContext(ctx, query, id, usrID)
+	}
+	if err != nil {
+		return err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return domain.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *WebhookRepository) EnqueueDeliveries(ctx context.Context, usrID, event string, payload []byte) error {
+	query := `
+		INSERT INTO webhook_delivery (webhook_id, event, payload)
+		SELECT id, $2, $3
+		FROM webhook
+		WHERE user_id = $1 AND $2 = ANY(events)
+		`
+	var err error
+	if tx := contextGetTX(ctx); tx != nil {
+		_, err = tx.ExecContext(ctx, query, usrID, event, string(payload))
+	} else {
+		_, err = r.db.ExecContext(ctx, query, usrID, event, string(payload))
+	}
+	return err
+}
+
+func (r *WebhookRepository) EnqueuePartnerDeliveries(ctx context.Context, usrID, event string, payload []byte) error {
+	query := `
+		INSERT INTO webhook_delivery (webhook_id, event, payload)
+		SELECT w.id, $2, $3
+		FROM webhook w
+		WHERE $2 = ANY(w.events) AND EXISTS (
+			SELECT 1 FROM conversation c
+			WHERE (c.sender_id = w.user_id AND c.receiver_id = $1) OR (c.sender_id = $1 AND c.receiver_id = w.user_id)
+		)
+		`
+	var err error
+	if tx := contextGetTX(ctx); tx != nil {
+		_, err = tx.ExecContext(ctx, query, usrID, event, string(payload))
+	} else {
+		_, err = r.db.ExecContext(ctx, query, usrID, event, string(payload))
+	}
+	return err
+}
+
+func (r *WebhookRepository) GetDeliveries(
+	ctx context.Context,
+	webhookID string,
+	limit int,
+) ([]*domain.WebhookDelivery, error) {
+	query := `
+		SELECT id, webhook_id, event, payload::TEXT, status, attempts, next_attempt_at, response_status, last_error,
+		       created_at, delivered_at
+		FROM webhook_delivery
+		WHERE webhook_id = $1
+		ORDER BY id DESC
+		LIMIT $2
+		`
+	var rows *sqlx.Rows
+	var err error
+	if tx := contextGetTX(ctx); tx != nil {
+		rows, err = tx.QueryxContext(ctx, query, webhookID, limit)
+	} else {
+		rows, err = r.db.QueryxContext(ctx, query, webhookID, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	deliveries := make([]*domain.WebhookDelivery, 0, limit)
+	for rows.Next() {
+		var d domain.WebhookDelivery
+		var payload string
+		dst := []any{
+			&d.ID, &d.WebhookID, &d.Event, &payload, &d.Status, &d.Attempts, &d.NextAttemptAt, &d.ResponseStatus,
+			&d.LastError, &d.CreatedAt, &d.DeliveredAt,
+		}
+		if err = rows.Scan(dst...); err != nil {
+			return nil, err
+		}
+		d.Payload = []byte(payload)
+		deliveries = append(deliveries, &d)
+	}
+	return deliveries, rows.Err()
+}
+
+func (r *WebhookRepository) ClaimDueDeliveries(
+	ctx context.Context,
+	now, leasedUntil time.Time,
+	limit int,
+) ([]*domain.WebhookDelivery, error) {
+	// the lease is taken by pushing next_attempt_at, so concurrent workers skip the claimed ones
+	query := `
+		UPDATE webhook_delivery d
+		SET next_attempt_at = $2
+		FROM webhook w
+		WHERE d.webhook_id = w.id AND d.id IN (
+			SELECT id FROM webhook_delivery
+			WHERE status = 'pending' AND next_attempt_at <= $1
+			ORDER BY next_attempt_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING d.id, d.webhook_id, d.event, d.payload::TEXT, d.attempts, d.created_at, w.url, w.secret
+		`
+	var rows *sqlx.Rows
+	var err error
+	if tx := contextGetTX(ctx); tx != nil {
+		rows, err = tx.QueryxContext(ctx, query, now, leasedUntil, limit)
+	} else {
+		rows, err = r.db.QueryxContext(ctx, query, now, leasedUntil, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	deliveries := make([]*domain.WebhookDelivery, 0, limit)
+	for rows.Next() {
+		d := domain.WebhookDelivery{Status: domain.WebhookDeliveryPending, NextAttemptAt: leasedUntil}
+		var payload string
+		dst := []any{&d.ID, &d.WebhookID, &d.Event, &payload, &d.Attempts, &d.CreatedAt, &d.URL, &d.Secret}
+		if err = rows.Scan(dst...); err != nil {
+			return nil, err
+		}
+		d.Payload = []byte(payload)
+		deliveries = append(deliveries, &d)
+	}
+	return deliveries, rows.Err()
+}
+
+func (r *WebhookRepository) UpdateDelivery(ctx context.Context, d *domain.WebhookDelivery) error {
+	query := `
+		UPDATE webhook_delivery
+		SET status = $2, attempts = $3, next_attempt_at = $4, response_status = $5, last_error = $6, delivered_at = $7
+		WHERE id = $1
+		`
+	args := []any{d.ID, d.Status, d.Attempts, d.NextAttemptAt, d.ResponseStatus, d.LastError, d.DeliveredAt}
+	var err error
+	if tx := contextGetTX(ctx); tx != nil {
+		_, err = tx.ExecContext(ctx, query, args...)
+	} else {
+		_, err = r.db.ExecContext(ctx, query, args...)
+	}
+	return err
+}
+
+func (r *WebhookRepository) DeleteDeliveriesUntil(ctx context.Context, until time.Time) (int64, error) {
+	query := `
+		DELETE FROM webhook_delivery
+		WHERE status <> 'pending' AND created_at < $1
+		`
+	var res sql.Result
+	var err error
+	if tx := contextGetTX(ctx); tx != nil {
+		res, err = tx.ExecContext(ctx, query, until)
+	} else {
+		res, err = r.db.ExecContext(ctx, query, until)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,180 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"github.com/MuhamedUsman/letschat/internal/api/utility"
+	"github.com/MuhamedUsman/letschat/internal/domain"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+func (s *Server) CreateBotHandler(w http.ResponseWriter, r *http.Request) {
+	var in domain.BotRegister
+	if err := s.readJSON(w, r, &in); err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+	u := utility.ContextGetUser(r.Context())
+	bot, err := s.Facade.CreateBot(r.Context(), &in, u)
+	if err != nil {
+		var ev *domain.ErrValidation
+		switch {
+		case errors.As(err, &ev):
+			s.failedValidationResponse(w, r, ev.Errors)
+		case errors.Is(err, domain.ErrForbidden):
+			s.notPermittedResponse(w, r, err)
+		default:
+			s.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	if err = s.writeJSON(w, envelop{"bot": bot}, http.StatusCreated, nil); err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
+
+func (s *Server) GetBotsHandler(w http.ResponseWriter, r *http.Request) {
+	u := utility.ContextGetUser(r.Context())
+	bots, err := s.Facade.GetBots(r.Context(), u)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+		return
+	}
+	if err = s.writeJSON(w, envelop{"bots": bots}, http.StatusOK, nil); err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
+
+func (s *Server) RotateBotKeyHandler(w http.ResponseWriter, r *http.Request) {
+	u := utility.ContextGetUser(r.Context())
+	bot, err := s.Facade.RotateBotKey(r.Context(), r.PathValue("id"), u)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrRecordNotFound):
+			s.notFoundResponse(w, r)
+		default:
+			s.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	if err = s.writeJSON(w, envelop{"bot": bot}, http.StatusOK, nil); err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
+
+func (s *Server) SendBotMessageHandler(w http.ResponseWriter, r *http.Request) {
+	var in domain.BotMessageInput
+	if err := s.readJSON(w, r, &in); err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+	u := utility.ContextGetUser(r.Context())
+	msg, convoCreated, err := s.Facade.SendBotMessage(r.Context(), in, u)
+	if err != nil {
+		var ev *domain.ErrValidation
+		switch {
+		case errors.As(err, &ev):
+			s.failedValidationResponse(w, r, ev.Errors)
+		default:
+			s.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	// relayed same as handleSentMessages does
+	s.Facade.EmitMessageWebhooks(msg)
+	if s.Hub.Unicast(msg.ReceiverID, msg) && convoCreated {
+		if err = s.syncConvos(r.Context()); err != nil {
+			slog.Error(err.Error())
+		}
+	}
+	if err = s.writeJSON(w, envelop{"message": msg}, http.StatusCreated, nil); err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
+
+// GetBotUpdatesHandler long-polls the event log of the bot, the offset is the cursor of the last update the bot has
+// processed, acknowledging every update up to it, the response carries the offset for the next long-poll
+func (s *Server) GetBotUpdatesHandler(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	ev := domain.NewErrValidation()
+	offset := s.readInt(qs, "offset", 0, ev)
+	timeout := s.readInt(qs, "timeout", 30, ev)
+	limit := s.readInt(qs, "limit", domain.MaxBotUpdatesLimit, ev)
+	ev.Evaluate(offset >= 0, "offset", "must not be negative")
+	ev.Evaluate(timeout >= 0 && time.Duration(timeout)*time.Second <= domain.MaxBotUpdatesTimeout,
+		"timeout", "must be between 0 and 50 seconds")
+	ev.Evaluate(limit >= 1 && limit <= domain.MaxBotUpdatesLimit, "limit", "must be between 1 and 100")
+	if ev.HasErrors() {
+		s.failedValidationResponse(w, r, ev.Errors)
+		return
+	}
+	wait := time.Duration(timeout) * time.Second
+	// the server wide WriteTimeout is shorter than a long-poll
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(wait + 5*time.Second)); err != nil {
+		s.serverErrorResponse(w, r, err)
+		return
+	}
+	updates, err := s.pollBotUpdates(r.Context(), int64(offset), limit, wait)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrAlreadySubscribed):
+			s.redundantSubscription(w, r)
+		case errors.Is(err, ErrShuttingDown):
+			s.shuttingDownResponse(w, r)
+		case errors.Is(err, context.Canceled):
+		default:
+			s.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	next := int64(offset)
+	if len(updates) > 0 {
+		next = updates[len(updates)-1].Cursor
+	}
+	if err = s.writeJSON(w, envelop{"updates": updates, "offset": next}, http.StatusOK, nil); err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
+
+// Helpers & Stuff ----------------------------------------------------------------------------------------------------
+
+// pollBotUpdates returns the updates after offset, waiting up to wait for the first one. The bot is subscribed to
+// the Hub meanwhile, so the msgs relayed to it wake the poll up, same as a single instance of the websocket, a
+// concurrent long-poll or subscription of the bot returns ErrAlreadySubscribed
+func (s *Server) pollBotUpdates(
+	ctx context.Context,
+	offset int64,
+	limit int,
+	wait time.Duration,
+) ([]*domain.Message, error) {
+	if s.isDraining() {
+		return nil, ErrShuttingDown
+	}
+	sub := *utility.ContextGetUser(ctx)
+	sub.Messages = make(chan *domain.Message, s.subscriberMessageBuffer)
+	sub.CloseSlow = func() {} // the relayed msgs only wake the poll up, they are fetched from the event log
+	// registered before the first fetch, so an event appended in between is not missed
+	if err := s.Hub.Register(&sub); err != nil {
+		return nil, err
+	}
+	defer s.Hub.Unregister(&sub)
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	for {
+		updates, err := s.Facade.GetBotUpdates(ctx, offset, limit)
+		if err != nil || len(updates) > 0 || wait == 0 {
+			return updates, err
+		}
+		select {
+		case <-sub.Messages: // not every relayed msg is an event, e.g. a domain.TypingMsg, so fetched again
+		case <-timer.C:
+			return updates, nil
+		case <-s.drained:
+			return updates, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
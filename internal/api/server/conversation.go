@@ -18,29 +18,67 @@ func (s *Server) GetConversationsHandler(w http.ResponseWriter, r *http.Request)
 	}
 }
 
-// Once the receivers gets this broadcast, they will re-fetch the conversations, for synchronization
-func (s *Server) syncConvos(ctx context.Context) error {
-	convos, err := s.Facade.GetConversations(ctx)
+// pushProfile writes the updated profile of the user in ctx to its online conversation partners with a
+// domain.ProfileMsg, the offline ones fetch it with their conversations once connected
+func (s *Server) pushProfile(ctx context.Context) error {
+	u := utility.ContextGetUser(ctx)
+	if u == nil {
+		panic("no user was found in the context, Hint: missing Authentication middleware")
+	}
+	// the one in ctx is from before the update
+	usr, err := s.Facade.GetByUniqueField(ctx, u.ID)
 	if err != nil {
 		return err
 	}
+	to, err := s.onlineConvoPartners(ctx)
+	if err != nil {
+		return err
+	}
+	t := time.Now()
+	p := usr.Profile()
+	msg := domain.Message{
+		SenderID:  u.ID,
+		SentAt:    &t,
+		Operation: domain.ProfileMsg,
+		Profile:   &p,
+	}
+	s.Hub.Multicast(to, &msg)
+	return nil
+}
+
+// Once the receivers gets this broadcast, they will re-fetch the conversations, for synchronization
+func (s *Server) syncConvos(ctx context.Context) error {
 	u := utility.ContextGetUser(ctx)
 	if u == nil {
 		panic("no user was found in the context, Hint: missing Authentication middleware")
 	}
+	to, err := s.onlineConvoPartners(ctx)
+	if err != nil {
+		return err
+	}
+	t := time.Now()
+	msg := domain.Message{
+		SenderID:  u.ID,
+		SentAt:    &t,
+		Operation: domain.SyncConvosMsg,
+	}
+	s.Hub.Multicast(to, &msg)
+	return nil
+}
+
+// Helpers & Stuff ----------------------------------------------------------------------------------------------------
+
+func (s *Server) onlineConvoPartners(ctx context.Context) ([]string, error) {
+	convos, err := s.Facade.GetConversations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
 	for _, convo := range convos {
-		if convo.LastOnline != nil { // meaning the user is not online
+		if convo.Presence == domain.Offline {
 			continue
 		}
-		t := time.Now()
-		msg := domain.Message{
-			SenderID:  u.ID,
-			SentAt:    &t,
-			Operation: domain.SyncConvosMsg,
-		}
-		if v, ok := s.Subscribers[convo.UserID]; ok {
-			v.Messages <- &msg
-		}
+		ids = append(ids, convo.UserID)
 	}
-	return nil
+	return ids, nil
 }
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// serveDebug serves the stats of the server on the Config DebugAddr till shutdown, e.g.
+//
+//	curl localhost:6060/debug/stats
+func (s *Server) serveDebug(shtdwnCtx context.Context) {
+	if s.Config.DebugAddr == "" {
+		return
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /debug/stats", s.debugStatsHandler)
+	srv := &http.Server{
+		Addr:         s.Config.DebugAddr,
+		Handler:      mux,
+		ReadTimeout:  3 * time.Second,
+		WriteTimeout: 6 * time.Second,
+	}
+	go func() {
+		<-shtdwnCtx.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = srv.Shutdown(ctx)
+	}()
+	slog.Info("starting debug server", "addr", srv.Addr)
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		// the api is still served
+		slog.Error("debug server", "err", err)
+	}
+}
+
+func (s *Server) debugStatsHandler(w http.ResponseWriter, r *http.Request) {
+	stats := envelop{"hub": s.Hub.Stats(), "rejectedMsgOps": s.Facade.AuditLog().Counts()}
+	if err := s.writeJSON(w, stats, http.StatusOK, nil); err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
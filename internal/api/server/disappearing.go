@@ -0,0 +1,26 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// purgeExpiredMessages deletes the expired msgs every Config.Disappearing.PurgeInterval, the clients purge their own
+// copies themselves, must be run in a separate long-running goroutine
+func (s *Server) purgeExpiredMessages(shtdwnCtx context.Context) {
+	ticker := time.NewTicker(s.Config.Disappearing.PurgeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if err := s.Facade.PurgeExpiredMessages(ctx); err != nil {
+				slog.Error(err.Error())
+			}
+			cancel()
+		case <-shtdwnCtx.Done():
+			return
+		}
+	}
+}
@@ -66,7 +66,26 @@ func (s *Server) inactiveAccountResponse(w http.ResponseWriter, r *http.Request)
 	s.errorResponse(w, r, http.StatusForbidden, message)
 }
 
+func (s *Server) shuttingDownResponse(w http.ResponseWriter, r *http.Request) {
+	message := "the server is shutting down, please try again shortly"
+	s.errorResponse(w, r, http.StatusServiceUnavailable, message)
+}
+
 func (s *Server) redundantSubscription(w http.ResponseWriter, r *http.Request) {
 	message := "single instance of subscription is allowed for this account"
 	s.errorResponse(w, r, http.StatusConflict, message)
 }
+
+func (s *Server) notPermittedResponse(w http.ResponseWriter, r *http.Request, err error) {
+	s.errorResponse(w, r, http.StatusForbidden, err.Error())
+}
+
+func (s *Server) botRequiredResponse(w http.ResponseWriter, r *http.Request) {
+	message := "only a bot account can access this resource, authenticate with its api key"
+	s.errorResponse(w, r, http.StatusForbidden, message)
+}
+
+func (s *Server) humanRequiredResponse(w http.ResponseWriter, r *http.Request) {
+	message := "a bot account can only access the /v1/bot resources"
+	s.errorResponse(w, r, http.StatusForbidden, message)
+}
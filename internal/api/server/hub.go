@@ -0,0 +1,169 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"github.com/MuhamedUsman/letschat/internal/domain"
+	"log/slog"
+)
+
+var ErrHubClosed = errors.New("hub is closed")
+
+// SlowConsumerPolicy decides what the Hub does with a subscriber whose msg buffer is full
+type SlowConsumerPolicy int
+
+const (
+	// SlowConsumerDisconnect unregisters & closes the conn of the subscriber, on reconnect it syncs the missed events
+	SlowConsumerDisconnect SlowConsumerPolicy = iota
+	// SlowConsumerDrop drops the msg & keeps the subscriber
+	SlowConsumerDrop
+)
+
+type HubStats struct {
+	Subscribers   int    `json:"subscribers"`   // currently registered
+	Registrations uint64 `json:"registrations"` // since the hub started
+	Delivered     uint64 `json:"delivered"`     // msgs queued to a subscriber
+	Dropped       uint64 `json:"dropped"`       // msgs not queued, as the buffer of the subscriber was full
+	SlowConsumers uint64 `json:"slowConsumers"` // subscribers disconnected by SlowConsumerDisconnect
+}
+
+type hubRegistration struct {
+	u     *domain.User
+	reply chan error
+}
+
+type hubCast struct {
+	to    []string
+	msg   *domain.Message
+	reply chan int // number of subscribers the msg is queued to
+}
+
+// Hub owns the subscribers of the server, registration, unregistration, unicast & multicast all go through the
+// single goroutine running Run, so the subscribers are never shared. Sends are non-blocking, a subscriber not
+// keeping up is handled according to the SlowConsumerPolicy
+type Hub struct {
+	policy     SlowConsumerPolicy
+	register   chan hubRegistration
+	unregister chan *domain.User
+	cast       chan hubCast
+	stats      chan chan HubStats
+	done       chan struct{}
+	// owned by the Run goroutine
+	subs     map[string]*domain.User
+	counters HubStats
+}
+
+func NewHub(policy SlowConsumerPolicy) *Hub {
+	return &Hub{
+		policy:     policy,
+		register:   make(chan hubRegistration),
+		unregister: make(chan *domain.User),
+		cast:       make(chan hubCast),
+		stats:      make(chan chan HubStats),
+		done:       make(chan struct{}),
+		subs:       make(map[string]*domain.User), // keys are userID
+	}
+}
+
+// Run must be called in a separate long-running goroutine, once it returns every call on the Hub is a NOOP
+func (h *Hub) Run(shtdwnCtx context.Context) {
+	defer close(h.done)
+	for {
+		select {
+		case r := <-h.register:
+			if _, ok := h.subs[r.u.ID]; ok { // multiple online instances of the account are not allowed by design
+				r.reply <- ErrAlreadySubscribed
+				continue
+			}
+			h.subs[r.u.ID] = r.u
+			h.counters.Registrations++
+			r.reply <- nil
+		case u := <-h.unregister:
+			// the user may have been replaced by a new subscription, after being disconnected as a slow consumer
+			if cur, ok := h.subs[u.ID]; ok && cur == u {
+				delete(h.subs, u.ID)
+			}
+		case c := <-h.cast:
+			c.reply <- h.send(c.to, c.msg)
+		case reply := <-h.stats:
+			s := h.counters
+			s.Subscribers = len(h.subs)
+			reply <- s
+		case <-shtdwnCtx.Done():
+			s := h.counters
+			s.Subscribers = len(h.subs)
+			slog.Info("hub stopped", "stats", s)
+			return
+		}
+	}
+}
+
+// Register adds u as a subscriber, returns ErrAlreadySubscribed if the user is already subscribed
+func (h *Hub) Register(u *domain.User) error {
+	r := hubRegistration{u, make(chan error, 1)}
+	select {
+	case h.register <- r:
+		return <-r.reply
+	case <-h.done:
+		return ErrHubClosed
+	}
+}
+
+// Unregister removes u, if it still is the subscriber of its user
+func (h *Hub) Unregister(u *domain.User) {
+	select {
+	case h.unregister <- u:
+	case <-h.done:
+	}
+}
+
+// Unicast queues msg to the user, reports whether the user is subscribed & the msg is queued
+func (h *Hub) Unicast(userID string, msg *domain.Message) bool {
+	return h.Multicast([]string{userID}, msg) == 1
+}
+
+// Multicast queues msg to every subscribed user of userIDs, returns the number of users it is queued to
+func (h *Hub) Multicast(userIDs []string, msg *domain.Message) int {
+	c := hubCast{userIDs, msg, make(chan int, 1)}
+	select {
+	case h.cast <- c:
+		return <-c.reply
+	case <-h.done:
+		return 0
+	}
+}
+
+func (h *Hub) Stats() HubStats {
+	reply := make(chan HubStats, 1)
+	select {
+	case h.stats <- reply:
+		return <-reply
+	case <-h.done:
+		return HubStats{}
+	}
+}
+
+// send must only be called by the Run goroutine
+func (h *Hub) send(to []string, msg *domain.Message) int {
+	n := 0
+	for _, id := range to {
+		u, ok := h.subs[id]
+		if !ok {
+			continue
+		}
+		select {
+		case u.Messages <- msg:
+			h.counters.Delivered++
+			n++
+		default:
+			h.counters.Dropped++
+			if h.policy == SlowConsumerDisconnect {
+				h.counters.SlowConsumers++
+				delete(h.subs, id)
+				// closing waits for the close handshake, must not block the hub
+				go u.CloseSlow()
+			}
+		}
+	}
+	return n
+}
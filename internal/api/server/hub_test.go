@@ -0,0 +1,199 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"github.com/MuhamedUsman/letschat/internal/domain"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHubRegisterUnregister(t *testing.T) {
+	h := newTestHub(t, SlowConsumerDisconnect)
+	u, _ := newTestSubscriber("1", 1)
+	if err := h.Register(u); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	dup, _ := newTestSubscriber("1", 1)
+	if err := h.Register(dup); !errors.Is(err, ErrAlreadySubscribed) {
+		t.Fatalf("Register duplicate: got %v, want ErrAlreadySubscribed", err)
+	}
+	// a stale subscriber of the user does not unregister the current one
+	h.Unregister(dup)
+	if got := h.Stats().Subscribers; got != 1 {
+		t.Fatalf("Subscribers after stale Unregister: got %d, want 1", got)
+	}
+	h.Unregister(u)
+	if got := h.Stats().Subscribers; got != 0 {
+		t.Fatalf("Subscribers after Unregister: got %d, want 0", got)
+	}
+	if err := h.Register(dup); err != nil {
+		t.Fatalf("Register after Unregister: %v", err)
+	}
+}
+
+func TestHubUnicastMulticast(t *testing.T) {
+	h := newTestHub(t, SlowConsumerDisconnect)
+	var users []*domain.User
+	for _, id := range []string{"1", "2", "3"} {
+		u, _ := newTestSubscriber(id, 1)
+		users = append(users, u)
+		if err := h.Register(u); err != nil {
+			t.Fatalf("Register: %v", err)
+		}
+	}
+	msg := &domain.Message{ID: "m"}
+	if !h.Unicast("1", msg) {
+		t.Fatal("Unicast to a subscriber: got false, want true")
+	}
+	if h.Unicast("unknown", msg) {
+		t.Fatal("Unicast to an unknown user: got true, want false")
+	}
+	if got := h.Multicast([]string{"2", "3", "unknown"}, msg); got != 2 {
+		t.Fatalf("Multicast: got %d, want 2", got)
+	}
+	for _, u := range users {
+		select {
+		case got := <-u.Messages:
+			if got != msg {
+				t.Fatalf("user %v: got msg %v, want %v", u.ID, got.ID, msg.ID)
+			}
+		default:
+			t.Fatalf("user %v: no msg queued", u.ID)
+		}
+	}
+	if got := h.Stats().Delivered; got != 3 {
+		t.Fatalf("Delivered: got %d, want 3", got)
+	}
+}
+
+func TestHubSlowConsumerDrop(t *testing.T) {
+	h := newTestHub(t, SlowConsumerDrop)
+	u, closed := newTestSubscriber("1", 1)
+	if err := h.Register(u); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if !h.Unicast("1", &domain.Message{ID: "first"}) {
+		t.Fatal("Unicast first: got false, want true")
+	}
+	// the buffer is full
+	if h.Unicast("1", &domain.Message{ID: "second"}) {
+		t.Fatal("Unicast second: got true, want false")
+	}
+	<-u.Messages
+	if !h.Unicast("1", &domain.Message{ID: "third"}) {
+		t.Fatal("Unicast after draining: got false, the subscriber must be kept")
+	}
+	s := h.Stats()
+	if s.Subscribers != 1 || s.Delivered != 2 || s.Dropped != 1 || s.SlowConsumers != 0 {
+		t.Fatalf("Stats: got %+v", s)
+	}
+	select {
+	case <-closed:
+		t.Fatal("CloseSlow called, want the subscriber kept")
+	default:
+	}
+}
+
+func TestHubSlowConsumerDisconnect(t *testing.T) {
+	h := newTestHub(t, SlowConsumerDisconnect)
+	u, closed := newTestSubscriber("1", 0)
+	if err := h.Register(u); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if h.Unicast("1", &domain.Message{ID: "m"}) {
+		t.Fatal("Unicast to a full subscriber: got true, want false")
+	}
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("CloseSlow not called")
+	}
+	s := h.Stats()
+	if s.Subscribers != 0 || s.Dropped != 1 || s.SlowConsumers != 1 {
+		t.Fatalf("Stats: got %+v", s)
+	}
+	// it reconnects as a new subscriber
+	reconnected, _ := newTestSubscriber("1", 1)
+	if err := h.Register(reconnected); err != nil {
+		t.Fatalf("Register after disconnect: %v", err)
+	}
+	if got := h.Stats().Registrations; got != 2 {
+		t.Fatalf("Registrations: got %d, want 2", got)
+	}
+}
+
+func TestHubConcurrentUse(t *testing.T) {
+	h := newTestHub(t, SlowConsumerDrop)
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			u, _ := newTestSubscriber(string(rune('a'+i)), 4)
+			if err := h.Register(u); err != nil {
+				t.Errorf("Register: %v", err)
+				return
+			}
+			for range 8 {
+				h.Multicast([]string{u.ID, "a"}, &domain.Message{})
+				h.Stats()
+			}
+			h.Unregister(u)
+		}()
+	}
+	wg.Wait()
+	s := h.Stats()
+	if s.Subscribers != 0 || s.Registrations != 20 {
+		t.Fatalf("Stats: got %+v", s)
+	}
+}
+
+func TestHubClosed(t *testing.T) {
+	h := NewHub(SlowConsumerDisconnect)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		h.Run(ctx)
+		close(done)
+	}()
+	cancel()
+	<-done
+	u, _ := newTestSubscriber("1", 1)
+	if err := h.Register(u); !errors.Is(err, ErrHubClosed) {
+		t.Fatalf("Register: got %v, want ErrHubClosed", err)
+	}
+	if h.Unicast("1", &domain.Message{}) {
+		t.Fatal("Unicast: got true, want false")
+	}
+	if s := h.Stats(); s != (HubStats{}) {
+		t.Fatalf("Stats: got %+v, want zero", s)
+	}
+}
+
+// Helpers & Stuff ----------------------------------------------------------------------------------------------------
+
+func newTestHub(t *testing.T, policy SlowConsumerPolicy) *Hub {
+	t.Helper()
+	h := NewHub(policy)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		h.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return h
+}
+
+// newTestSubscriber returns a subscriber buffering buf msgs, & a chan closed once the hub calls its CloseSlow
+func newTestSubscriber(id string, buf int) (*domain.User, <-chan struct{}) {
+	closed := make(chan struct{})
+	u := &domain.User{ID: id, Messages: make(domain.MsgChan, buf)}
+	u.CloseSlow = func() { close(closed) }
+	return u, closed
+}
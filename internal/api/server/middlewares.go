@@ -55,6 +55,28 @@ func (s *Server) requireActivatedUser(next http.Handler) http.Handler {
 	})
 }
 
+func (s *Server) requireHumanUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		usr := utility.ContextGetUser(r.Context())
+		if usr.IsBot {
+			s.humanRequiredResponse(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) requireBotUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		usr := utility.ContextGetUser(r.Context())
+		if !usr.IsBot {
+			s.botRequiredResponse(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 func (s *Server) recoverPanic(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
@@ -9,8 +9,10 @@ func (s *Server) routes() http.Handler {
 	mux := http.NewServeMux()
 	// Middlewares
 	base := alice.New(s.recoverPanic, s.authenticate)
-	authenticated := alice.New(s.requireAuthenticatedUser)
+	// the api key of a bot authenticates it on the /v1/bot routes only
+	authenticated := alice.New(s.requireAuthenticatedUser, s.requireHumanUser)
 	protected := authenticated.Append(s.requireActivatedUser)
+	bot := alice.New(s.requireAuthenticatedUser, s.requireActivatedUser, s.requireBotUser)
 	// User Routes
 	mux.HandleFunc("POST /v1/users", s.RegisterUserHandler)
 	mux.Handle("GET /v1/users/{field}", authenticated.ThenFunc(s.GetByUniqueFieldHandler))
@@ -23,6 +25,22 @@ func (s *Server) routes() http.Handler {
 	mux.HandleFunc("POST /v1/tokens/auth", s.GenerateAuthTokenHandler)
 	// Conversation Routes
 	mux.Handle("GET /v1/conversations", protected.ThenFunc(s.GetConversationsHandler))
+	// Scheduled Message Routes
+	mux.Handle("GET /v1/messages/scheduled", protected.ThenFunc(s.GetScheduledMessagesHandler))
+	mux.Handle("POST /v1/messages/scheduled", protected.ThenFunc(s.ScheduleMessageHandler))
+	mux.Handle("PUT /v1/messages/scheduled/{id}", protected.ThenFunc(s.UpdateScheduledMessageHandler))
+	mux.Handle("DELETE /v1/messages/scheduled/{id}", protected.ThenFunc(s.CancelScheduledMessageHandler))
+	// Bot Routes, /v1/bots are for the owners, /v1/bot for the bots themselves
+	mux.Handle("GET /v1/bots", protected.ThenFunc(s.GetBotsHandler))
+	mux.Handle("POST /v1/bots", protected.ThenFunc(s.CreateBotHandler))
+	mux.Handle("POST /v1/bots/{id}/key", protected.ThenFunc(s.RotateBotKeyHandler))
+	mux.Handle("POST /v1/bot/messages", bot.ThenFunc(s.SendBotMessageHandler))
+	mux.Handle("GET /v1/bot/updates", bot.ThenFunc(s.GetBotUpdatesHandler))
+	// Webhook Routes
+	mux.Handle("GET /v1/webhooks", protected.ThenFunc(s.GetWebhooksHandler))
+	mux.Handle("POST /v1/webhooks", protected.ThenFunc(s.CreateWebhookHandler))
+	mux.Handle("DELETE /v1/webhooks/{id}", protected.ThenFunc(s.DeleteWebhookHandler))
+	mux.Handle("GET /v1/webhooks/{id}/deliveries", protected.ThenFunc(s.GetWebhookDeliveriesHandler))
 	// Websocket Routes
 	mux.Handle("/sub", protected.ThenFunc(s.WebsocketSubscribeHandler))
 
@@ -0,0 +1,122 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"github.com/MuhamedUsman/letschat/internal/api/facade"
+	"github.com/MuhamedUsman/letschat/internal/api/utility"
+	"github.com/MuhamedUsman/letschat/internal/domain"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+func (s *Server) ScheduleMessageHandler(w http.ResponseWriter, r *http.Request) {
+	var in domain.ScheduledMessageInput
+	if err := s.readJSON(w, r, &in); err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+	u := utility.ContextGetUser(r.Context())
+	sm, err := s.Facade.ScheduleMessage(r.Context(), in, u)
+	if err != nil {
+		var ev *domain.ErrValidation
+		switch {
+		case errors.As(err, &ev):
+			s.failedValidationResponse(w, r, ev.Errors)
+		default:
+			s.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	if err = s.writeJSON(w, envelop{"scheduled_message": sm}, http.StatusCreated, nil); err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
+
+func (s *Server) GetScheduledMessagesHandler(w http.ResponseWriter, r *http.Request) {
+	receiverID := s.readString(r.URL.Query(), "receiverID", "")
+	u := utility.ContextGetUser(r.Context())
+	sms, err := s.Facade.GetScheduledMessages(r.Context(), receiverID, u)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+		return
+	}
+	if err = s.writeJSON(w, envelop{"scheduled_messages": sms}, http.StatusOK, nil); err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
+
+func (s *Server) UpdateScheduledMessageHandler(w http.ResponseWriter, r *http.Request) {
+	var in domain.ScheduledMessageInput
+	if err := s.readJSON(w, r, &in); err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+	u := utility.ContextGetUser(r.Context())
+	sm, err := s.Facade.UpdateScheduledMessage(r.Context(), r.PathValue("id"), in, u)
+	if err != nil {
+		var ev *domain.ErrValidation
+		switch {
+		case errors.As(err, &ev):
+			s.failedValidationResponse(w, r, ev.Errors)
+		case errors.Is(err, domain.ErrRecordNotFound):
+			s.notFoundResponse(w, r)
+		case errors.Is(err, domain.ErrEditConflict):
+			s.editConflictResponse(w, r)
+		default:
+			s.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	if err = s.writeJSON(w, envelop{"scheduled_message": sm}, http.StatusOK, nil); err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
+
+func (s *Server) CancelScheduledMessageHandler(w http.ResponseWriter, r *http.Request) {
+	u := utility.ContextGetUser(r.Context())
+	if err := s.Facade.CancelScheduledMessage(r.Context(), r.PathValue("id"), u); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrRecordNotFound):
+			s.notFoundResponse(w, r)
+		default:
+			s.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// releaseScheduledMessages sends the due scheduled msgs every Config.Scheduled.PollInterval, relaying each to its
+// receiver & back to its sender, must be run in a separate long-running goroutine
+func (s *Server) releaseScheduledMessages(shtdwnCtx context.Context) {
+	ticker := time.NewTicker(s.Config.Scheduled.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			// not cancelled by the shutdown, a release in-flight is completed
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if err := s.Facade.ReleaseDueMessages(ctx, s.relayReleasedMessage); err != nil {
+				slog.Error(err.Error())
+			}
+			cancel()
+		case <-shtdwnCtx.Done():
+			return
+		}
+	}
+}
+
+// Helpers & Stuff ----------------------------------------------------------------------------------------------------
+
+// relayReleasedMessage relays the released msg same as handleSentMessages does, ctx carries its sender
+func (s *Server) relayReleasedMessage(ctx context.Context, r facade.ReleasedMessage) {
+	s.Facade.EmitMessageWebhooks(r.Msg)
+	if s.Hub.Unicast(r.Msg.ReceiverID, r.Msg) && r.ConvoCreated {
+		if err := s.syncConvos(ctx); err != nil {
+			slog.Error(err.Error())
+		}
+	}
+	s.Hub.Unicast(r.Msg.SenderID, r.SentEvent)
+}
@@ -7,7 +7,6 @@ import (
 	"github.com/MuhamedUsman/letschat/internal/api/facade"
 	"github.com/MuhamedUsman/letschat/internal/api/utility"
 	"github.com/MuhamedUsman/letschat/internal/common"
-	"github.com/MuhamedUsman/letschat/internal/domain"
 	"github.com/coder/websocket"
 	"golang.org/x/time/rate"
 	"log/slog"
@@ -27,8 +26,14 @@ type Server struct {
 	subscriberMessageBuffer int
 	publishLimiter          *rate.Limiter
 
-	SubsMu      sync.Mutex
-	Subscribers map[string]*domain.User
+	Hub *Hub
+
+	// the accepted ws conns, closed with a reconnect hint on shutdown, see drainWebsockets
+	wsConnsMu sync.Mutex
+	wsConns   map[*websocket.Conn]struct{}
+	draining  bool
+	// closed once draining starts, ending the bot long-polls early, see GetBotUpdatesHandler
+	drained chan struct{}
 }
 
 func NewServer(cfg *utility.Config, bt *common.BackgroundTask, facade *facade.Facade) *Server {
@@ -42,7 +47,9 @@ func NewServer(cfg *utility.Config, bt *common.BackgroundTask, facade *facade.Fa
 		},
 		subscriberMessageBuffer: 16,
 		publishLimiter:          rate.NewLimiter(rate.Limit(100*time.Millisecond), 10),
-		Subscribers:             make(map[string]*domain.User), // keys are userID
+		Hub:                     NewHub(SlowConsumerDisconnect),
+		wsConns:                 make(map[*websocket.Conn]struct{}),
+		drained:                 make(chan struct{}),
 	}
 }
 
@@ -54,12 +61,21 @@ func (s *Server) Serve() error {
 		WriteTimeout: 6 * time.Second,
 		IdleTimeout:  time.Minute,
 	}
+	s.BackgroundTask.Run(s.Hub.Run)
+	s.BackgroundTask.Run(s.Facade.RunPresence)
+	s.BackgroundTask.Run(s.relayPresenceChanges)
+	s.BackgroundTask.Run(s.releaseScheduledMessages)
+	s.BackgroundTask.Run(s.purgeExpiredMessages)
+	s.BackgroundTask.Run(s.deliverWebhooks)
+	s.BackgroundTask.Run(s.serveDebug)
 	shutdownErr := make(chan error)
 	go func() {
 		quit := make(chan os.Signal, 1)
 		signal.Notify(quit, syscall.SIGTERM, syscall.SIGINT)
 		sig := <-quit
 		slog.Info("shutting down server", "signal", sig.String())
+		// srv.Shutdown does not touch the hijacked ws conns
+		s.drainWebsockets()
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 		if err := srv.Shutdown(ctx); err != nil {
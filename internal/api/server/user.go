@@ -5,6 +5,8 @@ import (
 	"github.com/MuhamedUsman/letschat/internal/api/utility"
 	"github.com/MuhamedUsman/letschat/internal/domain"
 	"net/http"
+	"net/url"
+	"strings"
 )
 
 func (s *Server) RegisterUserHandler(w http.ResponseWriter, r *http.Request) {
@@ -29,6 +31,10 @@ func (s *Server) RegisterUserHandler(w http.ResponseWriter, r *http.Request) {
 
 func (s *Server) GetByUniqueFieldHandler(w http.ResponseWriter, r *http.Request) {
 	fieldValue := r.PathValue("field")
+	if strings.HasPrefix(fieldValue, "@") {
+		s.getByHandle(w, r, fieldValue)
+		return
+	}
 	user, err := s.Facade.GetByUniqueField(r.Context(), fieldValue)
 	if err != nil {
 		switch {
@@ -44,6 +50,27 @@ func (s *Server) GetByUniqueFieldHandler(w http.ResponseWriter, r *http.Request)
 	}
 }
 
+// getByHandle resolves the handle, a previous one is permanently redirected to the current handle of its user
+func (s *Server) getByHandle(w http.ResponseWriter, r *http.Request, handle string) {
+	user, redirected, err := s.Facade.GetByHandle(r.Context(), handle)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrRecordNotFound):
+			s.notFoundResponse(w, r)
+		default:
+			s.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	if redirected && user.Handle != nil {
+		http.Redirect(w, r, "/v1/users/@"+url.PathEscape(*user.Handle), http.StatusPermanentRedirect)
+		return
+	}
+	if err = s.writeJSON(w, envelop{"user": user}, http.StatusOK, nil); err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
+
 func (s *Server) UpdateUserHandler(w http.ResponseWriter, r *http.Request) {
 	var userUpdate domain.UserUpdate
 	if err := s.readJSON(w, r, &userUpdate); err != nil {
@@ -62,8 +89,8 @@ func (s *Server) UpdateUserHandler(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	}
-	// tell every user related to this updated user to sync their conversations
-	if err := s.syncConvos(r.Context()); err != nil {
+	// push the updated profile to every user related to this updated user
+	if err := s.pushProfile(r.Context()); err != nil {
 		s.serverErrorResponse(w, r, err)
 	}
 }
@@ -93,23 +120,21 @@ func (s *Server) ActivateUserHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) SearchUserHandler(w http.ResponseWriter, r *http.Request) {
-	var filter domain.Filter
+	var filter domain.CursorFilter
 	v := r.URL.Query()
 	ev := domain.NewErrValidation()
 	queryParam := s.readString(v, "param", "")
-	filter.Page = s.readInt(v, "page", 1, ev)
+	filter.After = s.readString(v, "after", "")
 	filter.PageSize = s.readInt(v, "size", 30, ev)
-	if ev.HasErrors() {
+	if domain.ValidateCursorFilter(ev, &filter); ev.HasErrors() {
 		s.failedValidationResponse(w, r, ev.Errors)
 		return
 	}
-	users, metadata, err := s.Facade.SearchUser(r.Context(), queryParam, filter)
+	u := utility.ContextGetUser(r.Context())
+	users, metadata, err := s.Facade.SearchUser(r.Context(), u.ID, queryParam, filter)
 	if err != nil {
-		if errors.Is(err, domain.ErrRecordNotFound) {
-			s.notFoundResponse(w, r)
-			return
-		}
 		s.serverErrorResponse(w, r, err)
+		return
 	}
 	if err = s.writeJSON(w, envelop{"users": users, "metadata": metadata}, http.StatusOK, nil); err != nil {
 		s.serverErrorResponse(w, r, err)
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"github.com/MuhamedUsman/letschat/internal/api/utility"
+	"github.com/MuhamedUsman/letschat/internal/domain"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+func (s *Server) CreateWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	var in domain.WebhookInput
+	if err := s.readJSON(w, r, &in); err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+	u := utility.ContextGetUser(r.Context())
+	wh, err := s.Facade.CreateWebhook(r.Context(), in, u)
+	if err != nil {
+		var ev *domain.ErrValidation
+		switch {
+		case errors.As(err, &ev):
+			s.failedValidationResponse(w, r, ev.Errors)
+		default:
+			s.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	if err = s.writeJSON(w, envelop{"webhook": wh}, http.StatusCreated, nil); err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
+
+func (s *Server) GetWebhooksHandler(w http.ResponseWriter, r *http.Request) {
+	u := utility.ContextGetUser(r.Context())
+	whs, err := s.Facade.GetWebhooks(r.Context(), u)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+		return
+	}
+	if err = s.writeJSON(w, envelop{"webhooks": whs}, http.StatusOK, nil); err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
+
+func (s *Server) DeleteWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	u := utility.ContextGetUser(r.Context())
+	if err := s.Facade.DeleteWebhook(r.Context(), r.PathValue("id"), u); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrRecordNotFound):
+			s.notFoundResponse(w, r)
+		default:
+			s.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) GetWebhookDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	u := utility.ContextGetUser(r.Context())
+	deliveries, err := s.Facade.GetWebhookDeliveries(r.Context(), r.PathValue("id"), u)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrRecordNotFound):
+			s.notFoundResponse(w, r)
+		default:
+			s.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	if err = s.writeJSON(w, envelop{"deliveries": deliveries}, http.StatusOK, nil); err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
+
+// deliverWebhooks attempts the due webhook deliveries every Config.Webhook.PollInterval, and purges the delivery log
+// past Config.Webhook.Retention hourly, must be run in a separate long-running goroutine
+func (s *Server) deliverWebhooks(shtdwnCtx context.Context) {
+	ticker := time.NewTicker(s.Config.Webhook.PollInterval)
+	defer ticker.Stop()
+	purgeTicker := time.NewTicker(time.Hour)
+	defer purgeTicker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			// not cancelled by the shutdown, the attempts in-flight are completed
+			ctx, cancel := context.WithTimeout(context.Background(), s.Config.Webhook.Timeout+5*time.Second)
+			if err := s.Facade.DeliverDueWebhooks(ctx); err != nil {
+				slog.Error(err.Error())
+			}
+			cancel()
+		case <-purgeTicker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if err := s.Facade.PurgeWebhookDeliveries(ctx, s.Config.Webhook.Retention); err != nil {
+				slog.Error(err.Error())
+			}
+			cancel()
+		case <-shtdwnCtx.Done():
+			return
+		}
+	}
+}
@@ -2,8 +2,10 @@ package server
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"github.com/MuhamedUsman/letschat/internal/api/utility"
+	"github.com/MuhamedUsman/letschat/internal/common"
 	"github.com/MuhamedUsman/letschat/internal/domain"
 	"github.com/coder/websocket"
 	"github.com/coder/websocket/wsjson"
@@ -16,6 +18,9 @@ import (
 
 var (
 	ErrAlreadySubscribed = errors.New("already subscribed")
+	ErrShuttingDown      = errors.New("server is shutting down")
+	// the conn is already upgraded, so it is closed with a reconnect hint instead of an http response
+	errDrainedOnUpgrade = errors.New("server started shutting down while upgrading")
 )
 
 func (s *Server) WebsocketSubscribeHandler(w http.ResponseWriter, r *http.Request) {
@@ -24,25 +29,21 @@ func (s *Server) WebsocketSubscribeHandler(w http.ResponseWriter, r *http.Reques
 		switch {
 		case errors.Is(err, ErrAlreadySubscribed):
 			s.redundantSubscription(w, r)
+		case errors.Is(err, ErrShuttingDown):
+			s.shuttingDownResponse(w, r)
+		case errors.Is(err, errDrainedOnUpgrade):
 		default:
 			slog.Error(err.Error())
 		}
 		return
 	}
 	u := utility.ContextGetUser(r.Context())
-
-	s.addSubscriber(u)
-	if err = s.Facade.UpdateUserOnlineStatus(r.Context(), u, true); err != nil {
-		s.editConflictResponse(w, r)
-		return
-	}
-	if err = s.broadcastUserOnlineStatus(r.Context(), u, true); err != nil {
-		s.serverErrorResponse(w, r, err)
-		return
-	}
+	// settled & pushed to the subscribers of the user after the debounce, the client subscribes to the presences
+	// it is interested in with a SubscribePresenceMsg once connected
+	s.Facade.ConnectPresence(u.ID)
 
 	// buffered because if there's any error, just return, don't want the other writes to block
-	errChan := make(chan error, 1) // if there is a single err we log and return
+	errChan := make(chan error, 2) // if there is a single err we log and return
 	reqCtx, cancel := context.WithCancel(r.Context())
 	defer cancel()
 	s.BackgroundTask.Run(func(shtdwnCtx context.Context) {
@@ -51,11 +52,12 @@ func (s *Server) WebsocketSubscribeHandler(w http.ResponseWriter, r *http.Reques
 	s.BackgroundTask.Run(func(shtdwnCtx context.Context) {
 		errChan <- s.handleSentMessages(shtdwnCtx, reqCtx, conn)
 	})
+	// a dead NAT mapping never closes the conn, the user would stay online forever
+	s.BackgroundTask.Run(func(shtdwnCtx context.Context) {
+		errChan <- s.heartbeat(shtdwnCtx, reqCtx, conn)
+	})
 
-	if err = s.Facade.WriteUnDeliveredMessagesToWSConn(r.Context(), u.Messages); err != nil {
-		slog.Error(err.Error())
-		return
-	}
+	// undelivered msgs are no longer pushed here, the client asks for them with a SyncRequestMsg once connected
 	defer s.WebsocketSubscribeHandlerDeferFunc(r.Context(), conn)
 
 	if err = <-errChan; err != nil {
@@ -67,24 +69,21 @@ func (s *Server) WebsocketSubscribeHandler(w http.ResponseWriter, r *http.Reques
 			websocket.CloseStatus(err) == websocket.StatusAbnormalClosure ||
 			websocket.CloseStatus(err) == websocket.StatusGoingAway ||
 			errors.Is(err, io.EOF) ||
-			errors.Is(err, context.Canceled) {
+			errors.Is(err, context.Canceled) ||
+			errors.Is(err, common.ErrHeartbeatMissed) {
 			return
 		}
 		slog.Error(err.Error())
 	}
 }
 
-// WebsocketSubscribeHandlerDeferFunc sets the user's LastOnline to time.Now
+// WebsocketSubscribeHandlerDeferFunc unregisters the user, the presence goes offline once the debounce settles
 func (s *Server) WebsocketSubscribeHandlerDeferFunc(reqCtx context.Context, conn *websocket.Conn) {
 	u := utility.ContextGetUser(reqCtx)
-	s.broadcastUserOnlineStatus(reqCtx, u, false)
-	s.removeSubscriber(u)
+	s.Facade.DisconnectPresence(u.ID)
+	s.Hub.Unregister(u)
+	s.untrackConn(conn)
 	conn.CloseNow()
-	for range 5 { // Very unlikely to fail
-		if err := s.Facade.UpdateUserOnlineStatus(reqCtx, u, false); err == nil { // successful case
-			break
-		}
-	}
 }
 
 func (s *Server) subscribe(w http.ResponseWriter, r *http.Request) (*websocket.Conn, error) {
@@ -92,8 +91,8 @@ func (s *Server) subscribe(w http.ResponseWriter, r *http.Request) (*websocket.C
 	var conn *websocket.Conn
 
 	u := utility.ContextGetUser(r.Context()) // User will be authenticated and setup in the context using middleware
-	if _, ok := s.Subscribers[u.ID]; ok {    // multiple online instances of the account are not allowed by design
-		return nil, ErrAlreadySubscribed
+	if s.isDraining() {
+		return nil, ErrShuttingDown
 	}
 	u.Messages = make(chan *domain.Message, s.subscriberMessageBuffer)
 	u.CloseSlow = func() {
@@ -103,14 +102,25 @@ func (s *Server) subscribe(w http.ResponseWriter, r *http.Request) (*websocket.C
 			conn.Close(websocket.StatusPolicyViolation, "connection too slow to keep up with messages")
 		}
 	}
+	// registered before upgrading, so two concurrent subscriptions of the same account cannot both succeed
+	if err := s.Hub.Register(u); err != nil {
+		return nil, err
+	}
 	r = utility.ContextSetUser(r, u) // setting back updated user in context
 	c, err := websocket.Accept(w, r, s.wsAcceptOpts)
 	if err != nil {
+		s.Hub.Unregister(u)
 		return nil, err
 	}
 	mu.Lock()
 	conn = c
 	mu.Unlock()
+	// draining may have started while upgrading
+	if !s.trackConn(conn) {
+		s.Hub.Unregister(u)
+		conn.Close(websocket.StatusGoingAway, s.goingAwayReason())
+		return nil, errDrainedOnUpgrade
+	}
 	return conn, nil
 }
 
@@ -142,79 +152,176 @@ func (s *Server) handleSentMessages(shutdownCtx, reqCtx context.Context, conn *w
 		if err := wsjson.Read(shutdownCtx, conn, &ms); err != nil {
 			return err
 		}
+		// these Ops are answered directly on the conn, not relayed
+		switch ms.Operation {
+		case domain.SyncRequestMsg, domain.PresenceMsg, domain.SubscribePresenceMsg:
+			if err := s.handleControlMessage(reqCtx, conn, ms); err != nil {
+				var ev *domain.ErrValidation
+				if !errors.As(err, &ev) {
+					return err
+				}
+				handleValidationError(conn, ms, ev)
+			}
+			continue
+		}
 		// ProcessSentMessage populate the domain.Message and also concurrently persist it to DB with 5 retries
 		msg, convoCreated, err := s.Facade.ProcessSentMessage(reqCtx, ms, u)
 		if err != nil {
-			if errors.Is(err, domain.ErrValidation{}) {
-				handleValidationError(conn, err)
+			var ev *domain.ErrValidation
+			if errors.As(err, &ev) {
+				handleValidationError(conn, ms, ev)
 			} else {
 				return err
 			}
 			continue
 		}
-		if relayTo, ok := s.Subscribers[ms.ReceiverID]; ok {
-			// we do not want to send msg, these Ops are only for ack to server
-			if msg.Operation == domain.DeliveredConfirmMsg ||
-				msg.Operation == domain.ReadConfirmMsg ||
-				msg.Operation == domain.DeleteConfirmMsg {
-				continue
-			}
-			select {
-			case relayTo.Messages <- msg:
-				if convoCreated {
-					if err = s.syncConvos(reqCtx); err != nil {
-						slog.Error(err.Error())
-						return err
-					}
-				}
-			case <-shutdownCtx.Done():
-				return nil
-			case <-reqCtx.Done():
-				return nil
-			default:
-				u.CloseSlow()
-				return nil
+		// we do not want to send msg, these Ops are only for ack to server
+		if msg.Operation == domain.DeliveredConfirmMsg ||
+			msg.Operation == domain.ReadConfirmMsg ||
+			msg.Operation == domain.DeleteConfirmMsg {
+			continue
+		}
+		s.Facade.EmitMessageWebhooks(msg)
+		// a receiver not keeping up is handled by the Hub, it gets the msg from its event log on reconnect
+		if s.Hub.Unicast(ms.ReceiverID, msg) && convoCreated {
+			if err = s.syncConvos(reqCtx); err != nil {
+				slog.Error(err.Error())
+				return err
 			}
 		}
 	}
 }
 
-func (s *Server) addSubscriber(u *domain.User) {
-	s.SubsMu.Lock()
-	s.Subscribers[u.ID] = u
-	s.SubsMu.Unlock()
+func (s *Server) heartbeat(shutdownCtx, reqCtx context.Context, conn *websocket.Conn) error {
+	u := utility.ContextGetUser(reqCtx)
+	ctx, cancel := context.WithCancel(reqCtx)
+	defer cancel()
+	stop := context.AfterFunc(shutdownCtx, cancel)
+	defer stop()
+	hb := common.NewHeartbeat(s.Config.WS.PingInterval, s.Config.WS.MaxMissedPings)
+	err := hb.Run(ctx, conn)
+	if errors.Is(err, common.ErrHeartbeatMissed) {
+		slog.Warn("closed stale websocket conn", "user", u.ID, "last rtt", hb.RTT())
+	}
+	return err
+}
+
+// trackConn registers the conn to be drained on shutdown, reports false if the server is already draining
+func (s *Server) trackConn(conn *websocket.Conn) bool {
+	s.wsConnsMu.Lock()
+	defer s.wsConnsMu.Unlock()
+	if s.draining {
+		return false
+	}
+	s.wsConns[conn] = struct{}{}
+	return true
 }
 
-func (s *Server) removeSubscriber(u *domain.User) {
-	s.SubsMu.Lock()
-	delete(s.Subscribers, u.ID)
-	s.SubsMu.Unlock()
+func (s *Server) untrackConn(conn *websocket.Conn) {
+	s.wsConnsMu.Lock()
+	delete(s.wsConns, conn)
+	s.wsConnsMu.Unlock()
 }
 
-func (s *Server) broadcastUserOnlineStatus(ctx context.Context, u *domain.User, online bool) error {
-	convos, err := s.Facade.GetConversations(ctx)
-	if err != nil {
-		return err
+func (s *Server) isDraining() bool {
+	s.wsConnsMu.Lock()
+	defer s.wsConnsMu.Unlock()
+	return s.draining
+}
+
+// drainWebsockets stops accepting new subscriptions, and closes every tracked conn with websocket.StatusGoingAway
+// & a reconnect hint, so the clients come back once the server is up again, instead of exhausting their retries
+func (s *Server) drainWebsockets() {
+	s.wsConnsMu.Lock()
+	s.draining = true
+	close(s.drained)
+	conns := make([]*websocket.Conn, 0, len(s.wsConns))
+	for conn := range s.wsConns {
+		conns = append(conns, conn)
 	}
-	for _, convo := range convos {
-		if convo.LastOnline != nil { // meaning the user is not online
-			continue
+	s.wsConnsMu.Unlock()
+	reason := s.goingAwayReason()
+	var wg sync.WaitGroup
+	for _, conn := range conns {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// waits for the close handshake, bounded by the library
+			_ = conn.Close(websocket.StatusGoingAway, reason)
+		}()
+	}
+	wg.Wait()
+	slog.Info("drained websocket subscribers", "count", len(conns), "hub", s.Hub.Stats())
+}
+
+func (s *Server) goingAwayReason() string {
+	ga := domain.GoingAway{
+		Reason:         "server shutting down",
+		ReconnectAfter: s.Config.WS.ReconnectHint.Milliseconds(),
+	}
+	b, _ := json.Marshal(ga)
+	return string(b)
+}
+
+func (s *Server) handleControlMessage(ctx context.Context, conn *websocket.Conn, ms domain.MessageSent) error {
+	u := utility.ContextGetUser(ctx)
+	switch ms.Operation {
+	case domain.SyncRequestMsg:
+		return s.syncEvents(ctx, conn, ms)
+	case domain.PresenceMsg:
+		return s.Facade.SetPresence(ms, u)
+	case domain.SubscribePresenceMsg:
+		presences, err := s.Facade.SubscribePresence(ctx, ms, u)
+		if err != nil {
+			return err
 		}
-		t := time.Now()
-		op := domain.OfflineMsg
-		if online {
-			op = domain.OnlineMsg
+		for _, p := range presences {
+			if err = writeWithTimeout(conn, 2*time.Second, presenceMessage(p)); err != nil {
+				return err
+			}
 		}
-		msg := domain.Message{
-			SenderID:  u.ID,
-			SentAt:    &t,
-			Operation: op,
+	}
+	return nil
+}
+
+// relayPresenceChanges pushes the settled presence changes to their online subscribers,
+// must be run in a separate long-running goroutine
+func (s *Server) relayPresenceChanges(shtdwnCtx context.Context) {
+	changes := s.Facade.PresenceChanges()
+	for {
+		select {
+		case c := <-changes:
+			if len(c.To) > 0 {
+				s.Hub.Multicast(c.To, presenceMessage(c.Presence))
+			}
+			s.Facade.EmitPresenceWebhooks(c.Presence)
+		case <-shtdwnCtx.Done():
+			return
 		}
-		if v, ok := s.Subscribers[convo.UserID]; ok {
-			v.Messages <- &msg
+	}
+}
+
+// syncEvents streams the events after the since cursor of the SyncRequestMsg directly to the conn, batch by batch,
+// followed by a SyncDoneMsg, so the client knows it has caught up
+func (s *Server) syncEvents(ctx context.Context, conn *websocket.Conn, ms domain.MessageSent) error {
+	cursor, err := s.Facade.SyncEvents(ctx, ms, func(events []*domain.Message) error {
+		for _, e := range events {
+			if err := writeWithTimeout(conn, 2*time.Second, e); err != nil {
+				return err
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
-	return nil
+	t := time.Now()
+	done := domain.Message{
+		SentAt:    &t,
+		Operation: domain.SyncDoneMsg,
+		Cursor:    cursor,
+	}
+	return writeWithTimeout(conn, 2*time.Second, done)
 }
 
 func writeWithTimeout(conn *websocket.Conn, t time.Duration, msg any) error {
@@ -223,12 +330,33 @@ func writeWithTimeout(conn *websocket.Conn, t time.Duration, msg any) error {
 	return wsjson.Write(ctx, conn, msg)
 }
 
-func handleValidationError(conn *websocket.Conn, err error) {
-	var ev *domain.ErrValidation
-	if errors.As(err, &ev) {
-		if err = writeWithTimeout(conn, 5*time.Second, ev.Errors); err != nil {
-			slog.Error(err.Error())
-			return
-		}
+// presenceMessage is the PresenceMsg of p, its SentAt is the last online time if offline
+func presenceMessage(p domain.Presence) *domain.Message {
+	t := time.Now()
+	if p.LastOnline != nil {
+		t = *p.LastOnline
+	}
+	return &domain.Message{
+		SenderID:  p.UserID,
+		SentAt:    &t,
+		Operation: domain.PresenceMsg,
+		Presence:  &p.Status,
+	}
+}
+
+// handleValidationError writes back an ErrorMsg frame to the sender, so it can relate the errors to the msg it sent
+func handleValidationError(conn *websocket.Conn, ms domain.MessageSent, ev *domain.ErrValidation) {
+	t := time.Now()
+	frame := domain.Message{
+		ReceiverID: ms.ReceiverID,
+		SentAt:     &t,
+		Operation:  domain.ErrorMsg,
+		Errors:     ev.Errors,
+	}
+	if ms.ID != nil {
+		frame.ID = *ms.ID
+	}
+	if err := writeWithTimeout(conn, 5*time.Second, frame); err != nil {
+		slog.Error(err.Error())
 	}
 }
@@ -25,9 +25,26 @@ func (s *ConversationService) GetConversations(ctx context.Context) ([]*domain.C
 	if usr == nil {
 		panic("no user was found in the context, Hint: missing Authentication middleware")
 	}
-	return s.conversationRepository.GetConversations(ctx, usr.ID)
+	convos, err := s.conversationRepository.GetConversations(ctx, usr.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, convo := range convos {
+		convo.ClearExpiredStatus()
+	}
+	return convos, nil
 }
 
 func (s *ConversationService) ConversationExists(ctx context.Context, senderID, receiverID string) (bool, error) {
 	return s.conversationRepository.ConversationExists(ctx, senderID, receiverID)
 }
+
+// SetDisappearAfter sets the secs after which the msgs of the conversation disappear, for both of its users
+func (s *ConversationService) SetDisappearAfter(ctx context.Context, senderID, receiverID string, secs int64) error {
+	return s.conversationRepository.SetDisappearAfter(ctx, senderID, receiverID, secs)
+}
+
+// GetDisappearAfter returns zero if there is no conversation in between
+func (s *ConversationService) GetDisappearAfter(ctx context.Context, senderID, receiverID string) (int64, error) {
+	return s.conversationRepository.GetDisappearAfter(ctx, senderID, receiverID)
+}
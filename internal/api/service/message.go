@@ -2,32 +2,48 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/MuhamedUsman/letschat/internal/api/utility"
 	"github.com/MuhamedUsman/letschat/internal/domain"
 	"github.com/google/uuid"
+	"time"
 )
 
+// syncBatchSize bounds the number of events fetched & streamed at once while syncing a client
+const syncBatchSize = 100
+
 type MessageService struct {
-	messageRepo domain.MessageRepository
+	messageRepo   domain.MessageRepository
+	eventRepo     domain.EventRepository
+	watermarkRepo domain.WatermarkRepository
+	convoRepo     domain.ConversationRepository
 }
 
-func NewMessageService(messageRepo domain.MessageRepository) *MessageService {
-	return &MessageService{messageRepo}
+func NewMessageService(
+	messageRepo domain.MessageRepository,
+	eventRepo domain.EventRepository,
+	watermarkRepo domain.WatermarkRepository,
+	convoRepo domain.ConversationRepository,
+) *MessageService {
+	return &MessageService{messageRepo, eventRepo, watermarkRepo, convoRepo}
 }
 
 func (*MessageService) PopulateMessage(m domain.MessageSent, sndr *domain.User) *domain.Message {
 	msg := &domain.Message{
-		SenderID:    sndr.ID,
-		ReceiverID:  m.ReceiverID,
-		SentAt:      m.SentAt,
-		DeliveredAt: m.DeliveredAt,
-		ReadAt:      m.ReadAt,
-		Operation:   m.Operation,
+		SenderID:       sndr.ID,
+		ReceiverID:     m.ReceiverID,
+		SentAt:         m.SentAt,
+		DeliveredAt:    m.DeliveredAt,
+		ReadAt:         m.ReadAt,
+		Operation:      m.Operation,
+		UpTo:           m.UpTo,
+		DisappearAfter: m.DisappearAfter,
+		PinnedAt:       m.PinnedAt,
 	}
 	if m.ID != nil {
 		msg.ID = *m.ID
-	} else if msg.Operation == domain.CreateMsg {
+	} else if msg.Operation.HasGeneratedID() {
 		msg.ID = uuid.New().String()
 	} else {
 		panic("msg.Operation != domain.CreateMsg, yet ID is nil, Hint: failing/bad validation")
@@ -58,6 +74,18 @@ func (s *MessageService) ProcessSentMessages(ctx context.Context, m *domain.Mess
 		}
 		return s.messageRepo.InsertMessage(ctx, m)
 
+	// watermarks are kept per conversation participant, the events relay them to the other one, the rows of the msgs
+	// they cover are deleted, as the msgs received while offline are acknowledged by a DeliveredUpToMsg only
+	case domain.DeliveredUpToMsg, domain.ReadUpToMsg:
+		if err := s.watermarkRepo.Advance(ctx, m); err != nil {
+			return err
+		}
+		if m.UpTo == nil {
+			return nil
+		}
+		_, err := s.messageRepo.DeleteCreatedUpTo(ctx, m.ReceiverID, m.SenderID, *m.UpTo)
+		return err
+
 	// these OPs are not for persistence, but merely a confirmation to ensure robustness
 	// these OPs cases will delete msgs with specified Ops, DeliveredMsg, ReadMsg, DeleteMsg
 	case domain.DeliveredConfirmMsg, domain.ReadConfirmMsg, domain.DeleteConfirmMsg:
@@ -67,24 +95,155 @@ func (s *MessageService) ProcessSentMessages(ctx context.Context, m *domain.Mess
 	case domain.OnlineMsg, domain.OfflineMsg, domain.TypingMsg:
 		return nil
 
+	// persisted on the conversation itself before it is relayed, see facade.MessageFacade.ProcessSentMessage
+	case domain.DisappearingMsg:
+		return nil
+
+	// pins are kept by the clients, the events relay them to the other user
+	case domain.PinMsg, domain.UnpinMsg:
+		return nil
+
 	default:
 		return fmt.Errorf("unknown operation %v", m.Operation)
 	}
 }
 
-func (s *MessageService) GetUnDeliveredMessages(ctx context.Context, c domain.MsgChan) error {
+// AppendEvent appends m to the event log of its receiver, and stamps m with the cursor of the event
+func (s *MessageService) AppendEvent(ctx context.Context, m *domain.Message) error {
+	cursor, err := s.eventRepo.Append(ctx, m.ReceiverID, m)
+	if err != nil {
+		return err
+	}
+	m.Cursor = cursor
+	return nil
+}
+
+// AppendEventFor appends m to the event log of usrID instead, e.g. a domain.ScheduledSentMsg to its sender
+func (s *MessageService) AppendEventFor(ctx context.Context, usrID string, m *domain.Message) error {
+	cursor, err := s.eventRepo.Append(ctx, usrID, m)
+	if err != nil {
+		return err
+	}
+	m.Cursor = cursor
+	return nil
+}
+
+// StreamEventsSince calls fn with the events of the user in the context after the since cursor, in batches of
+// syncBatchSize, returns the cursor of the last event streamed, or since if there was none
+func (s *MessageService) StreamEventsSince(
+	ctx context.Context,
+	since int64,
+	fn func(events []*domain.Message) error,
+) (int64, error) {
 	u := utility.ContextGetUser(ctx)
-	// the order matters here
-	ops := []domain.MsgOperation{domain.DeleteMsg, domain.DeliveredMsg, domain.ReadMsg, domain.CreateMsg}
-	for _, op := range ops {
-		// this directly writes to the msg chan
-		if err := s.messageRepo.GetUnDeliveredMessages(ctx, u.ID, op, c); err != nil {
-			return err
+	for {
+		events, err := s.eventRepo.GetSince(ctx, u.ID, since, syncBatchSize)
+		if err != nil {
+			return since, err
+		}
+		if len(events) == 0 {
+			return since, nil
+		}
+		if err = fn(events); err != nil {
+			return since, err
+		}
+		since = events[len(events)-1].Cursor
+		if len(events) < syncBatchSize {
+			return since, nil
 		}
 	}
-	return nil
+}
+
+func (s *MessageService) GetEventsSince(ctx context.Context, since int64, limit int) ([]*domain.Message, error) {
+	u := utility.ContextGetUser(ctx)
+	return s.eventRepo.GetSince(ctx, u.ID, since, limit)
+}
+
+// AckEvents deletes the events of the user in the context up to the cursor, the client has persisted them
+func (s *MessageService) AckEvents(ctx context.Context, cursor int64) error {
+	u := utility.ContextGetUser(ctx)
+	return s.eventRepo.DeleteUntil(ctx, u.ID, cursor)
+}
+
+// PurgeExpiredMessages deletes the msgs & the events whose ExpiresAt is passed by until, returns the msgs deleted
+func (s *MessageService) PurgeExpiredMessages(ctx context.Context, until time.Time) (int64, error) {
+	n, err := s.messageRepo.DeleteExpired(ctx, until)
+	if err != nil {
+		return 0, err
+	}
+	if _, err = s.eventRepo.DeleteExpired(ctx, until); err != nil {
+		return 0, err
+	}
+	return n, nil
 }
 
 func (s *MessageService) SaveMessage(ctx context.Context, m *domain.Message) error {
 	return s.messageRepo.InsertMessage(ctx, m)
 }
+
+// AuthorizeMessage checks m against the domain.MessageOwner of its id, so a user cannot override or delete someone
+// else's msg, a CreateMsg claims its id for its sender if it is new, any other op on an unknown id is rejected.
+// Returns domain.ErrForbidden wrapped with the reason on violation.
+func (s *MessageService) AuthorizeMessage(ctx context.Context, m *domain.Message) error {
+	// a watermark or a DisappearingMsg is not about a single msg, its id is generated by the server
+	if m.Operation == domain.TypingMsg || m.Operation == domain.DisappearingMsg || m.Operation.IsWatermark() {
+		return nil
+	}
+	var owner *domain.MessageOwner
+	var err error
+	if m.Operation == domain.CreateMsg {
+		// an edit keeps the id, the record of the first CreateMsg is returned then
+		o := domain.MessageOwner{ID: m.ID, SenderID: m.SenderID, ReceiverID: m.ReceiverID}
+		owner, err = s.messageRepo.ClaimOwner(ctx, o)
+	} else {
+		owner, err = s.messageRepo.GetOwner(ctx, m.ID)
+		if errors.Is(err, domain.ErrRecordNotFound) {
+			return s.authorizeUnownedMessage(ctx, m)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	author, recipient := owner.SenderID, owner.ReceiverID
+	var reason string
+	switch m.Operation {
+	case domain.CreateMsg, domain.DeleteMsg:
+		if m.SenderID != author {
+			reason = "only the sender may edit or delete this message"
+		}
+	case domain.DeliveredMsg, domain.ReadMsg:
+		if m.SenderID != recipient {
+			reason = "only the receiver may mark this message as delivered or read"
+		}
+	case domain.DeliveredConfirmMsg, domain.ReadConfirmMsg:
+		if m.SenderID != author {
+			reason = "only the sender may acknowledge delivery or read of this message"
+		}
+	case domain.DeleteConfirmMsg:
+		if m.SenderID != recipient {
+			reason = "only the receiver may acknowledge deletion of this message"
+		}
+	}
+	// whatever the role is, the msg must stay between the same parties
+	if reason == "" && !(m.SenderID == author && m.ReceiverID == recipient) &&
+		!(m.SenderID == recipient && m.ReceiverID == author) {
+		reason = "message does not belong to this conversation"
+	}
+	if reason != "" {
+		return fmt.Errorf("%w: %s", domain.ErrForbidden, reason)
+	}
+	return nil
+}
+
+// authorizeUnownedMessage authorizes the ops on a msg acknowledged before its ownership was recorded, who sent it is
+// not known anymore, only that the parties share a conversation
+func (s *MessageService) authorizeUnownedMessage(ctx context.Context, m *domain.Message) error {
+	exists, err := s.convoRepo.ConversationExists(ctx, m.SenderID, m.ReceiverID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("%w: unknown message", domain.ErrForbidden)
+	}
+	return nil
+}
@@ -0,0 +1,266 @@
+package service
+
+import (
+	"context"
+	"github.com/MuhamedUsman/letschat/internal/domain"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+var _ domain.PresenceService = (*PresenceService)(nil)
+
+type presenceEntry struct {
+	// ws conns of the user, the Hub allows a single one, but a reconnect may overlap the teardown of the previous
+	conns int
+	// chosen by the user while connected, Online unless set otherwise
+	chosen domain.PresenceStatus
+	// the last settled presence, the one subscribers know of
+	settled domain.Presence
+	// pending transition, fires once the presence has been stable for the debounce duration
+	timer *time.Timer
+	// settled is not persisted yet
+	dirty bool
+}
+
+// PresenceService keeps the presences in memory, a transition is only settled & pushed to the subscribers once it
+// has been stable for the debounce duration, so a flaky connection does not flap. The settled presences are
+// persisted to users.last_online every persistEvery by RunPresence
+type PresenceService struct {
+	userRepo     domain.UserRepository
+	debounce     time.Duration
+	persistEvery time.Duration
+	changes      chan domain.PresenceChange
+	done         chan struct{}
+
+	mu      sync.Mutex
+	entries map[string]*presenceEntry      // keys are userID
+	watched map[string]map[string]struct{} // userID -> subscriberIDs watching it
+	watches map[string][]string            // subscriberID -> userIDs it watches
+}
+
+func NewPresenceService(userRepo domain.UserRepository, debounce, persistEvery time.Duration) *PresenceService {
+	return &PresenceService{
+		userRepo:     userRepo,
+		debounce:     debounce,
+		persistEvery: persistEvery,
+		changes:      make(chan domain.PresenceChange, 64),
+		done:         make(chan struct{}),
+		entries:      make(map[string]*presenceEntry),
+		watched:      make(map[string]map[string]struct{}),
+		watches:      make(map[string][]string),
+	}
+}
+
+func (s *PresenceService) RunPresence(shtdwnCtx context.Context) {
+	ticker := time.NewTicker(s.persistEvery)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.persist(shtdwnCtx)
+		case <-shtdwnCtx.Done():
+			close(s.done)
+			s.mu.Lock()
+			now := time.Now()
+			for _, e := range s.entries {
+				if e.timer != nil {
+					e.timer.Stop()
+				}
+				if e.settled.Status != domain.Offline {
+					e.settled.Status = domain.Offline
+					e.settled.LastOnline = &now
+					e.dirty = true
+				}
+			}
+			s.mu.Unlock()
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			s.persist(ctx)
+			cancel()
+			return
+		}
+	}
+}
+
+func (s *PresenceService) ConnectPresence(usrID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := s.entry(usrID)
+	e.conns++
+	s.schedule(usrID, e)
+}
+
+func (s *PresenceService) DisconnectPresence(usrID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := s.entry(usrID)
+	e.conns = max(0, e.conns-1)
+	if e.conns == 0 {
+		// a status chosen by the user does not outlive its session
+		e.chosen = domain.Online
+	}
+	s.schedule(usrID, e)
+}
+
+func (s *PresenceService) SetPresence(usrID string, status domain.PresenceStatus) {
+	if status == domain.Offline {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := s.entry(usrID)
+	e.chosen = status
+	s.schedule(usrID, e)
+}
+
+// GetPresences returns the settled presences of usrIDs, the ones not in memory are offline since their persisted
+// last_online, usrIDs that do not exist are skipped
+func (s *PresenceService) GetPresences(ctx context.Context, usrIDs ...string) ([]domain.Presence, error) {
+	presences := make([]domain.Presence, 0, len(usrIDs))
+	var unknown []string
+	s.mu.Lock()
+	for _, id := range usrIDs {
+		if e, ok := s.entries[id]; ok {
+			presences = append(presences, e.settled)
+		} else {
+			unknown = append(unknown, id)
+		}
+	}
+	s.mu.Unlock()
+	if len(unknown) == 0 {
+		return presences, nil
+	}
+	lastOnline, err := s.userRepo.GetLastOnline(ctx, unknown)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range unknown {
+		if t, ok := lastOnline[id]; ok {
+			presences = append(presences, domain.Presence{UserID: id, Status: domain.Offline, LastOnline: t})
+		}
+	}
+	return presences, nil
+}
+
+func (s *PresenceService) SubscribePresence(subscriberID string, usrIDs []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.unsubscribe(subscriberID)
+	for _, id := range usrIDs {
+		if s.watched[id] == nil {
+			s.watched[id] = make(map[string]struct{})
+		}
+		s.watched[id][subscriberID] = struct{}{}
+	}
+	s.watches[subscriberID] = usrIDs
+}
+
+func (s *PresenceService) UnsubscribePresence(subscriberID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.unsubscribe(subscriberID)
+}
+
+func (s *PresenceService) PresenceChanges() <-chan domain.PresenceChange {
+	return s.changes
+}
+
+// Helpers & Stuff ----------------------------------------------------------------------------------------------------
+
+// entry must be called holding s.mu
+func (s *PresenceService) entry(usrID string) *presenceEntry {
+	e, ok := s.entries[usrID]
+	if !ok {
+		now := time.Now()
+		e = &presenceEntry{
+			chosen:  domain.Online,
+			settled: domain.Presence{UserID: usrID, Status: domain.Offline, LastOnline: &now},
+		}
+		s.entries[usrID] = e
+	}
+	return e
+}
+
+// schedule (re)starts the debounce of the transition, must be called holding s.mu
+func (s *PresenceService) schedule(usrID string, e *presenceEntry) {
+	if e.timer != nil {
+		e.timer.Stop()
+	}
+	e.timer = time.AfterFunc(s.debounce, func() { s.settle(usrID) })
+}
+
+// settle publishes the presence of the user if it differs from the settled one
+func (s *PresenceService) settle(usrID string) {
+	s.mu.Lock()
+	e, ok := s.entries[usrID]
+	if !ok {
+		s.mu.Unlock()
+		return
+	}
+	e.timer = nil
+	status := domain.Offline
+	if e.conns > 0 {
+		status = e.chosen
+	}
+	if status == e.settled.Status {
+		s.mu.Unlock()
+		return
+	}
+	e.settled.Status = status
+	e.settled.LastOnline = nil
+	if status == domain.Offline {
+		now := time.Now()
+		e.settled.LastOnline = &now
+	}
+	e.dirty = true
+	change := domain.PresenceChange{Presence: e.settled}
+	for id := range s.watched[usrID] {
+		change.To = append(change.To, id)
+	}
+	s.mu.Unlock()
+	// published even without subscribers, the presence webhooks are produced on it too
+	select {
+	case s.changes <- change:
+	case <-s.done:
+	}
+}
+
+// unsubscribe must be called holding s.mu
+func (s *PresenceService) unsubscribe(subscriberID string) {
+	for _, id := range s.watches[subscriberID] {
+		delete(s.watched[id], subscriberID)
+		if len(s.watched[id]) == 0 {
+			delete(s.watched, id)
+		}
+	}
+	delete(s.watches, subscriberID)
+}
+
+// persist writes the dirty presences, the offline ones persisted by a previous round with nothing pending are
+// evicted from memory, GetPresences falls back to the persisted last_online for them
+func (s *PresenceService) persist(ctx context.Context) {
+	s.mu.Lock()
+	dirty := make([]domain.Presence, 0)
+	for id, e := range s.entries {
+		if e.dirty {
+			dirty = append(dirty, e.settled)
+			e.dirty = false
+			continue
+		}
+		if e.conns == 0 && e.timer == nil && e.settled.Status == domain.Offline {
+			delete(s.entries, id)
+		}
+	}
+	s.mu.Unlock()
+	for _, p := range dirty {
+		if err := s.userRepo.SetLastOnline(ctx, p.UserID, p.LastOnline); err != nil {
+			slog.Error(err.Error())
+			// retried on the next round, unless settled again meanwhile
+			s.mu.Lock()
+			if e, ok := s.entries[p.UserID]; ok {
+				e.dirty = true
+			}
+			s.mu.Unlock()
+		}
+	}
+}
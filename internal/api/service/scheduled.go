@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"github.com/MuhamedUsman/letschat/internal/domain"
+	"time"
+)
+
+var _ domain.ScheduledMessageService = (*ScheduledMessageService)(nil)
+
+type ScheduledMessageService struct {
+	scheduledRepo domain.ScheduledMessageRepository
+}
+
+func NewScheduledMessageService(scheduledRepo domain.ScheduledMessageRepository) *ScheduledMessageService {
+	return &ScheduledMessageService{scheduledRepo}
+}
+
+func (s *ScheduledMessageService) ScheduleMessage(ctx context.Context, sm *domain.ScheduledMessage) error {
+	return s.scheduledRepo.Insert(ctx, sm)
+}
+
+func (s *ScheduledMessageService) GetScheduledMessages(
+	ctx context.Context,
+	senderID, receiverID string,
+) ([]*domain.ScheduledMessage, error) {
+	return s.scheduledRepo.GetBySender(ctx, senderID, receiverID)
+}
+
+func (s *ScheduledMessageService) GetScheduledMessage(
+	ctx context.Context,
+	id, senderID string,
+) (*domain.ScheduledMessage, error) {
+	return s.scheduledRepo.GetByID(ctx, id, senderID)
+}
+
+func (s *ScheduledMessageService) UpdateScheduledMessage(ctx context.Context, sm *domain.ScheduledMessage) error {
+	return s.scheduledRepo.Update(ctx, sm)
+}
+
+func (s *ScheduledMessageService) CancelScheduledMessage(ctx context.Context, id, senderID string) error {
+	return s.scheduledRepo.Delete(ctx, id, senderID)
+}
+
+func (s *ScheduledMessageService) GetDueScheduledMessages(
+	ctx context.Context,
+	limit int,
+) ([]*domain.ScheduledMessage, error) {
+	return s.scheduledRepo.GetDue(ctx, time.Now(), limit)
+}
+
+func (s *ScheduledMessageService) ClaimScheduledMessage(ctx context.Context, sm *domain.ScheduledMessage) (bool, error) {
+	return s.scheduledRepo.DeleteVersion(ctx, sm.ID, sm.Version)
+}
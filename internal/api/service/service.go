@@ -9,16 +9,25 @@ type Service struct {
 	domain.TokenService
 	domain.MessageService
 	domain.ConversationService
+	domain.PresenceService
+	domain.ScheduledMessageService
+	domain.WebhookService
 }
 
 func New(us domain.UserService,
 	ts domain.TokenService,
 	ms domain.MessageService,
-	cs domain.ConversationService) *Service {
+	cs domain.ConversationService,
+	ps domain.PresenceService,
+	sms domain.ScheduledMessageService,
+	whs domain.WebhookService) *Service {
 	return &Service{
-		UserService:         us,
-		TokenService:        ts,
-		MessageService:      ms,
-		ConversationService: cs,
+		UserService:             us,
+		TokenService:            ts,
+		MessageService:          ms,
+		ConversationService:     cs,
+		PresenceService:         ps,
+		ScheduledMessageService: sms,
+		WebhookService:          whs,
 	}
 }
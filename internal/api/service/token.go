@@ -21,7 +21,8 @@ func NewTokenService(tokenRepo domain.TokenRepository) *TokenService {
 	return &TokenService{tokenRepo: tokenRepo}
 }
 
-// GenerateToken generates OTP if scope is ScopeActivation & AuthenticationToken if scope is ScopeAuthentication
+// GenerateToken generates OTP if scope is ScopeActivation, AuthenticationToken if scope is ScopeAuthentication
+// & a bot api key if scope is ScopeBot
 func (s *TokenService) GenerateToken(ctx context.Context, userID string, scope string) (string, error) {
 	token := new(domain.Token)
 	var err error
@@ -30,6 +31,8 @@ func (s *TokenService) GenerateToken(ctx context.Context, userID string, scope s
 		token, err = generateOTP(userID, scope, domain.ScopeActivationTTL)
 	case domain.ScopeAuthentication:
 		token, err = generateAuthToken(userID, scope, domain.ScopeAuthenticationTTL)
+	case domain.ScopeBot:
+		token, err = generateAuthToken(userID, scope, domain.ScopeBotTTL)
 	default:
 		panic("invalid token scope")
 	}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
 	"crypto/sha256"
 	"errors"
 	"fmt"
@@ -18,17 +19,24 @@ var _ domain.UserService = (*UserService)(nil)
 
 type UserService struct {
 	userRepository domain.UserRepository
+	// a previous handle keeps resolving to its user for handleRedirectGrace
+	handleRedirectGrace time.Duration
 }
 
-func NewUserService(userRepo domain.UserRepository) *UserService {
+func NewUserService(userRepo domain.UserRepository, handleRedirectGrace time.Duration) *UserService {
 	return &UserService{
-		userRepository: userRepo,
+		userRepository:      userRepo,
+		handleRedirectGrace: handleRedirectGrace,
 	}
 }
 
 func (s *UserService) RegisterUser(ctx context.Context, u *domain.UserRegister) (string, error) {
 	ev := domain.NewErrValidation()
 	domain.ValidateName(u.Name, ev)
+	u.Handle = domain.NormalizeHandle(u.Handle)
+	if u.Handle != "" {
+		domain.ValidateHandle(u.Handle, ev)
+	}
 	domain.ValidateEmail(u.Email, ev)
 	domain.ValidPlainPassword(u.Password, ev)
 	if ev.HasErrors() {
@@ -42,6 +50,16 @@ func (s *UserService) RegisterUser(ctx context.Context, u *domain.UserRegister)
 		ev.AddError("email", "already exists")
 		return "", ev
 	}
+	if u.Handle != "" {
+		var taken bool
+		if taken, err = s.userRepository.HandleTaken(ctx, u.Handle, ""); err != nil {
+			return "", err
+		}
+		if taken {
+			ev.AddError("handle", "already taken")
+			return "", ev
+		}
+	}
 	passHash, err := generatePasswordHash(u.Password) // check if exists then hash, takes 200 ms approx.
 	if err != nil {
 		return "", fmt.Errorf("error generating password hash: %w", err)
@@ -51,11 +69,18 @@ func (s *UserService) RegisterUser(ctx context.Context, u *domain.UserRegister)
 		Email:    u.Email,
 		Password: passHash,
 	}
+	if u.Handle != "" {
+		usr.Handle = &u.Handle
+	}
 	userID, err := s.userRepository.RegisterUser(ctx, usr)
 	if errors.Is(err, domain.ErrDuplicateEmail) {
 		ev.AddError("email", "already exists")
 		return "", ev
 	}
+	if errors.Is(err, domain.ErrDuplicateHandle) {
+		ev.AddError("handle", "already taken")
+		return "", ev
+	}
 	return userID, nil
 }
 
@@ -77,9 +102,28 @@ func (s *UserService) GetByUniqueField(ctx context.Context, fieldValue string) (
 	if err != nil {
 		return nil, err
 	}
+	user.ClearExpiredStatus()
 	return user, nil
 }
 
+func (s *UserService) GetByHandle(ctx context.Context, handle string) (*domain.User, bool, error) {
+	handle = domain.NormalizeHandle(handle)
+	if !domain.RgxHandle.MatchString(handle) {
+		return nil, false, domain.ErrRecordNotFound
+	}
+	user, err := s.userRepository.GetByUniqueField(ctx, "handle", handle)
+	redirected := false
+	if errors.Is(err, domain.ErrRecordNotFound) {
+		user, err = s.userRepository.GetByHandleRedirect(ctx, handle)
+		redirected = true
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	user.ClearExpiredStatus()
+	return user, redirected, nil
+}
+
 func (s *UserService) UpdateUser(ctx context.Context, u *domain.UserUpdate) error {
 	ev := domain.NewErrValidation()
 	domain.ValidateName(u.Name, ev)
@@ -87,6 +131,16 @@ func (s *UserService) UpdateUser(ctx context.Context, u *domain.UserUpdate) erro
 	if u.NewPassword != nil {
 		domain.ValidPlainPassword(*u.NewPassword, ev)
 	}
+	if u.StatusText != nil || u.StatusEmoji != nil {
+		domain.ValidateStatus(deref(u.StatusText), deref(u.StatusEmoji), u.StatusExpiresAt, ev)
+	}
+	if u.Bio != nil {
+		domain.ValidateBio(*u.Bio, ev)
+	}
+	if u.Handle != nil {
+		*u.Handle = domain.NormalizeHandle(*u.Handle)
+		domain.ValidateHandle(*u.Handle, ev)
+	}
 	if ev.HasErrors() {
 		return ev
 	}
@@ -120,24 +174,53 @@ func (s *UserService) UpdateUser(ctx context.Context, u *domain.UserUpdate) erro
 		}
 		usr.Password = newPassHash
 	}
+	if u.StatusText != nil || u.StatusEmoji != nil {
+		usr.StatusText = deref(u.StatusText)
+		usr.StatusEmoji = deref(u.StatusEmoji)
+		usr.StatusExpiresAt = u.StatusExpiresAt
+	}
+	if u.Bio != nil {
+		usr.Bio = *u.Bio
+	}
+	if u.Handle != nil && (usr.Handle == nil || *usr.Handle != *u.Handle) {
+		if err = s.changeHandle(ctx, usr, *u.Handle); err != nil {
+			return err
+		}
+	}
 	if err = s.userRepository.UpdateUser(ctx, usr); err != nil {
+		if errors.Is(err, domain.ErrDuplicateHandle) {
+			ev.AddError("handle", "already taken")
+			return ev
+		}
 		return err
 	}
 	return nil
 }
 
-func (s *UserService) UpdateUserOnlineStatus(ctx context.Context, usr *domain.User, online bool) error {
-	u, err := s.userRepository.GetByUniqueField(ctx, "id", usr.ID)
+// changeHandle sets the handle of usr, the previous one redirects to usr for the grace period,
+// must be run in a transaction with the update of usr
+func (s *UserService) changeHandle(ctx context.Context, usr *domain.User, handle string) error {
+	taken, err := s.userRepository.HandleTaken(ctx, handle, usr.ID)
 	if err != nil {
 		return err
 	}
-	var lastOnline *time.Time
-	if !online {
-		now := time.Now()
-		lastOnline = &now
+	if taken {
+		ev := domain.NewErrValidation()
+		ev.AddError("handle", "already taken")
+		return ev
+	}
+	// the user may take its previous handle back, before the redirect expires
+	if err = s.userRepository.DeleteHandleRedirect(ctx, handle); err != nil {
+		return err
 	}
-	u.LastOnline = lastOnline
-	return s.userRepository.UpdateUser(ctx, u)
+	if usr.Handle != nil {
+		expiresAt := time.Now().Add(s.handleRedirectGrace)
+		if err = s.userRepository.AddHandleRedirect(ctx, *usr.Handle, usr.ID, expiresAt); err != nil {
+			return err
+		}
+	}
+	usr.Handle = &handle
+	return nil
 }
 
 func (s *UserService) GetForToken(ctx context.Context, scope string, plainToken string) (*domain.User, error) {
@@ -145,7 +228,7 @@ func (s *UserService) GetForToken(ctx context.Context, scope string, plainToken
 	switch scope {
 	case domain.ScopeActivation:
 		domain.ValidateOTP(plainToken, ev)
-	case domain.ScopeAuthentication:
+	case domain.ScopeAuthentication, domain.ScopeBot:
 		domain.ValidateAuthenticationToken(plainToken, ev)
 	}
 	if ev.HasErrors() {
@@ -158,7 +241,7 @@ func (s *UserService) GetForToken(ctx context.Context, scope string, plainToken
 			switch scope {
 			case domain.ScopeActivation:
 				ev.AddError("otp", "invalid")
-			case domain.ScopeAuthentication:
+			case domain.ScopeAuthentication, domain.ScopeBot:
 				ev.AddError("token", "invalid")
 			}
 			return nil, ev
@@ -202,22 +285,73 @@ func (s *UserService) AuthenticateUser(ctx context.Context, u *domain.UserAuth)
 
 func (s *UserService) GetByQuery(
 	ctx context.Context,
-	queryParam string,
-	filter domain.Filter,
-) ([]*domain.User, *domain.Metadata, error) {
-	var paramName string // name or email
-	if strings.Contains(queryParam, "@") {
-		paramName = "email"
-	} else {
-		paramName = "name"
+	usrID, queryParam string,
+	filter domain.CursorFilter,
+) ([]*domain.User, *domain.CursorMetadata, error) {
+	// handles are searched without the "@", emails keep theirs as only prefixes are matched on
+	queryParam = strings.TrimPrefix(strings.TrimSpace(queryParam), "@")
+	if queryParam == "" {
+		return []*domain.User{}, &domain.CursorMetadata{PageSize: filter.PageSize}, nil
+	}
+	users, metadata, err := s.userRepository.GetByQuery(ctx, usrID, queryParam, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, u := range users {
+		u.ClearExpiredStatus()
 	}
-	return s.userRepository.GetByQuery(ctx, paramName, queryParam, filter)
+	return users, metadata, nil
 }
 
 func (s *UserService) SetOnlineUsersLastSeen(ctx context.Context, t time.Time) error {
 	return s.userRepository.SetOnlineUsersLastSeen(ctx, t)
 }
 
+func (s *UserService) RegisterBot(ctx context.Context, ownerID string, b *domain.BotRegister) (string, error) {
+	ev := b.ValidateBotRegister()
+	if ev.HasErrors() {
+		return "", ev
+	}
+	bots, err := s.userRepository.GetBots(ctx, ownerID)
+	if err != nil {
+		return "", err
+	}
+	if len(bots) >= domain.MaxBotsPerOwner {
+		ev.AddError("owner", fmt.Sprintf("must not own more than %d bots", domain.MaxBotsPerOwner))
+		return "", ev
+	}
+	taken, err := s.userRepository.HandleTaken(ctx, b.Handle, "")
+	if err != nil {
+		return "", err
+	}
+	if taken {
+		ev.AddError("handle", "already taken")
+		return "", ev
+	}
+	// a bot never logs in with a password, it is authenticated with its api key only
+	passHash, err := generatePasswordHash(rand.Text())
+	if err != nil {
+		return "", fmt.Errorf("error generating password hash: %w", err)
+	}
+	bot := &domain.User{
+		Name:       b.Name,
+		Handle:     &b.Handle,
+		Email:      b.Email(),
+		Password:   passHash,
+		BotOwnerID: &ownerID,
+	}
+	botID, err := s.userRepository.RegisterBot(ctx, bot)
+	if errors.Is(err, domain.ErrDuplicateHandle) || errors.Is(err, domain.ErrDuplicateEmail) {
+		ev.AddError("handle", "already taken")
+		return "", ev
+	}
+	return botID, err
+}
+
+func (s *UserService) GetBots(ctx context.Context, ownerID string) ([]*domain.User, error) {
+	return s.userRepository.GetBots(ctx, ownerID)
+}
+
 func generatePasswordHash(plainPassword string) ([]byte, error) {
 	hash, err := bcrypt.GenerateFromPassword([]byte(plainPassword), 12)
 	if err != nil {
@@ -230,3 +364,12 @@ func generatePasswordHash(plainPassword string) ([]byte, error) {
 func comparePasswordHash(hash []byte, plain string) bool {
 	return bcrypt.CompareHashAndPassword(hash, []byte(plain)) == nil
 }
+
+// deref returns the zero value of T for nil
+func deref[T any](v *T) T {
+	var zero T
+	if v == nil {
+		return zero
+	}
+	return *v
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/MuhamedUsman/letschat/internal/domain"
+	"github.com/google/uuid"
+	"time"
+)
+
+var _ domain.WebhookService = (*WebhookService)(nil)
+
+type WebhookService struct {
+	webhookRepo domain.WebhookRepository
+	// a failed attempt is retried after backoffBase doubled per attempt, up to backoffMax, maxAttempts in total
+	maxAttempts int
+	backoffBase time.Duration
+	backoffMax  time.Duration
+}
+
+func NewWebhookService(
+	webhookRepo domain.WebhookRepository,
+	maxAttempts int,
+	backoffBase, backoffMax time.Duration,
+) *WebhookService {
+	return &WebhookService{
+		webhookRepo: webhookRepo,
+		maxAttempts: maxAttempts,
+		backoffBase: backoffBase,
+		backoffMax:  backoffMax,
+	}
+}
+
+func (s *WebhookService) CreateWebhook(ctx context.Context, w *domain.Webhook) error {
+	return s.webhookRepo.Insert(ctx, w)
+}
+
+func (s *WebhookService) GetWebhooks(ctx context.Context, usrID string) ([]*domain.Webhook, error) {
+	return s.webhookRepo.GetByUser(ctx, usrID)
+}
+
+func (s *WebhookService) GetWebhook(ctx context.Context, id, usrID string) (*domain.Webhook, error) {
+	if uuid.Validate(id) != nil {
+		return nil, domain.ErrRecordNotFound
+	}
+	return s.webhookRepo.GetByID(ctx, id, usrID)
+}
+
+func (s *WebhookService) DeleteWebhook(ctx context.Context, id, usrID string) error {
+	if uuid.Validate(id) != nil {
+		return domain.ErrRecordNotFound
+	}
+	return s.webhookRepo.Delete(ctx, id, usrID)
+}
+
+func (s *WebhookService) EnqueueWebhookDeliveries(ctx context.Context, usrID string, p domain.WebhookPayload) error {
+	payload, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("error marshalling webhook payload: %w", err)
+	}
+	return s.webhookRepo.EnqueueDeliveries(ctx, usrID, p.Event, payload)
+}
+
+func (s *WebhookService) EnqueuePartnerWebhookDeliveries(
+	ctx context.Context,
+	usrID string,
+	p domain.WebhookPayload,
+) error {
+	payload, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("error marshalling webhook payload: %w", err)
+	}
+	return s.webhookRepo.EnqueuePartnerDeliveries(ctx, usrID, p.Event, payload)
+}
+
+func (s *WebhookService) GetWebhookDeliveries(
+	ctx context.Context,
+	webhookID string,
+	limit int,
+) ([]*domain.WebhookDelivery, error) {
+	return s.webhookRepo.GetDeliveries(ctx, webhookID, limit)
+}
+
+func (s *WebhookService) ClaimDueWebhookDeliveries(
+	ctx context.Context,
+	limit int,
+	lease time.Duration,
+) ([]*domain.WebhookDelivery, error) {
+	now := time.Now()
+	return s.webhookRepo.ClaimDueDeliveries(ctx, now, now.Add(lease), limit)
+}
+
+func (s *WebhookService) CompleteWebhookDelivery(
+	ctx context.Context,
+	d *domain.WebhookDelivery,
+	responseStatus int,
+	err error,
+) error {
+	d.Attempts++
+	d.ResponseStatus = nil
+	if responseStatus != 0 {
+		d.ResponseStatus = &responseStatus
+	}
+	switch {
+	case err == nil:
+		t := time.Now()
+		d.Status, d.LastError, d.DeliveredAt = domain.WebhookDeliveryDelivered, "", &t
+	case d.Attempts >= s.maxAttempts:
+		d.Status, d.LastError = domain.WebhookDeliveryFailed, err.Error()
+	default:
+		d.LastError = err.Error()
+		d.NextAttemptAt = time.Now().Add(s.backoff(d.Attempts))
+	}
+	return s.webhookRepo.UpdateDelivery(ctx, d)
+}
+
+func (s *WebhookService) PurgeWebhookDeliveries(ctx context.Context, until time.Time) (int64, error) {
+	return s.webhookRepo.DeleteDeliveriesUntil(ctx, until)
+}
+
+// Helpers & Stuff ----------------------------------------------------------------------------------------------------
+
+// backoff is the wait after the nth failed attempt, backoffBase doubled per attempt, capped at backoffMax
+func (s *WebhookService) backoff(attempts int) time.Duration {
+	d := s.backoffBase
+	for range attempts - 1 {
+		if d >= s.backoffMax/2 {
+			return s.backoffMax
+		}
+		d *= 2
+	}
+	return min(d, s.backoffMax)
+}
@@ -9,12 +9,15 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type Config struct {
 	Port int
 	ENV  string
-	DB   struct {
+	// the stats of the server are served on it, not on Port, so they are not public, see server.serveDebug
+	DebugAddr string
+	DB        struct {
 		DSN             string
 		MaxOpenConn     int
 		MaxIdleConn     int
@@ -27,12 +30,40 @@ type Config struct {
 		Password string
 		Sender   string
 	}
+	WS struct {
+		PingInterval   time.Duration
+		MaxMissedPings int
+		ReconnectHint  time.Duration
+	}
+	Presence struct {
+		Debounce     time.Duration
+		PersistEvery time.Duration
+	}
+	Handle struct {
+		RedirectGrace time.Duration
+	}
+	Scheduled struct {
+		PollInterval time.Duration
+	}
+	Disappearing struct {
+		PurgeInterval time.Duration
+	}
+	Webhook struct {
+		PollInterval time.Duration
+		Timeout      time.Duration
+		MaxAttempts  int
+		BackoffBase  time.Duration
+		BackoffMax   time.Duration
+		Retention    time.Duration
+		AllowPrivate bool
+	}
 }
 
 func ParseFlags() *Config {
 	var cfg Config
 	flag.IntVar(&cfg.Port, "port", 8080, "API server Port")
 	flag.StringVar(&cfg.ENV, "env", "dev", "Environment (dev|stag|prod)")
+	flag.StringVar(&cfg.DebugAddr, "debug-addr", "localhost:6060", "Address serving /debug/stats, empty disables it")
 	// DB Flags
 	flag.StringVar(&cfg.DB.DSN, "db-dsn", "", "PostgreSQL DSN")
 	flag.IntVar(&cfg.DB.MaxOpenConn, "db-max-open-conn", 25, "PostgreSQL max open connections")
@@ -44,7 +75,33 @@ func ParseFlags() *Config {
 	flag.StringVar(&cfg.SMTP.Username, "smtp-username", "", "SMTP username")
 	flag.StringVar(&cfg.SMTP.Password, "smtp-password", "", "SMTP password")
 	flag.StringVar(&cfg.SMTP.Sender, "smtp-sender", "", "SMTP sender")
+	// Websocket Flags
+	flag.DurationVar(&cfg.WS.PingInterval, "ws-ping-interval", 15*time.Second, "Websocket heartbeat ping interval")
+	flag.IntVar(&cfg.WS.MaxMissedPings, "ws-max-missed-pings", 3, "Websocket missed heartbeats before closing the conn")
+	flag.DurationVar(&cfg.WS.ReconnectHint, "ws-reconnect-hint", 3*time.Second, "Reconnect delay suggested on shutdown")
+	// Presence Flags
+	flag.DurationVar(&cfg.Presence.Debounce, "presence-debounce", 3*time.Second, "Presence stable time before pushed")
+	flag.DurationVar(&cfg.Presence.PersistEvery, "presence-persist-every", 30*time.Second, "Presence persistence interval")
+	// Handle Flags
+	flag.DurationVar(&cfg.Handle.RedirectGrace, "handle-redirect-grace", 30*24*time.Hour, "Previous handle redirect period")
+	// Scheduled Message Flags
+	flag.DurationVar(&cfg.Scheduled.PollInterval, "scheduled-poll-interval", 5*time.Second, "Due scheduled messages poll interval")
+	// Disappearing Message Flags
+	flag.DurationVar(&cfg.Disappearing.PurgeInterval, "disappearing-purge-interval", time.Minute, "Expired messages purge interval")
+	// Webhook Flags
+	flag.DurationVar(&cfg.Webhook.PollInterval, "webhook-poll-interval", time.Second, "Due webhook deliveries poll interval")
+	flag.DurationVar(&cfg.Webhook.Timeout, "webhook-timeout", 5*time.Second, "Webhook delivery request timeout")
+	flag.IntVar(&cfg.Webhook.MaxAttempts, "webhook-max-attempts", 8, "Webhook delivery attempts before failing it")
+	flag.DurationVar(&cfg.Webhook.BackoffBase, "webhook-backoff-base", 10*time.Second, "Webhook first retry backoff")
+	flag.DurationVar(&cfg.Webhook.BackoffMax, "webhook-backoff-max", time.Hour, "Webhook max retry backoff")
+	flag.DurationVar(&cfg.Webhook.Retention, "webhook-retention", 7*24*time.Hour, "Webhook delivery log retention")
+	flag.BoolVar(&cfg.Webhook.AllowPrivate, "webhook-allow-private", false,
+		"Allow webhooks to loopback, private & link-local addresses, for local development only")
 	flag.Parse()
+	if cfg.WS.PingInterval <= 0 || cfg.WS.MaxMissedPings < 1 {
+		slog.Error("the ws ping interval must be positive, & at least one ping missed before closing the conn")
+		os.Exit(1)
+	}
 	return &cfg
 }
 
@@ -11,8 +11,12 @@ type ctxKey string
 const UserCtxKey = ctxKey("USER")
 
 func ContextSetUser(r *http.Request, user *domain.User) *http.Request {
-	ctx := context.WithValue(r.Context(), UserCtxKey, user)
-	return r.WithContext(ctx)
+	return r.WithContext(ContextWithUser(r.Context(), user))
+}
+
+// ContextWithUser is for the work done on behalf of a user outside a request, e.g. releasing its scheduled msgs
+func ContextWithUser(ctx context.Context, user *domain.User) context.Context {
+	return context.WithValue(ctx, UserCtxKey, user)
 }
 
 func ContextGetUser(ctx context.Context) *domain.User {
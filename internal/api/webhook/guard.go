@@ -0,0 +1,71 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"syscall"
+)
+
+// ErrForbiddenAddr is returned for a webhook url resolving to an address of the server's own network, e.g. of the
+// cloud metadata service or the database, so the webhooks cannot probe it
+var ErrForbiddenAddr = errors.New("must not resolve to a loopback, private, link-local or unspecified address")
+
+// shared address space of the carrier-grade NATs, & "this network", neither is reachable on the internet
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("0.0.0.0/8"),
+}
+
+// CheckURL resolves the host of rawURL, ErrForbiddenAddr if any of its addresses is not public, the dialer checks
+// each conn again as the host may resolve differently by the time a delivery is attempted
+func (s *Sender) CheckURL(ctx context.Context, rawURL string) error {
+	if s.allowPrivate {
+		return nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", u.Hostname())
+	if err != nil {
+		return fmt.Errorf("must resolve: %w", err)
+	}
+	for _, addr := range addrs {
+		if !isPublic(addr) {
+			return ErrForbiddenAddr
+		}
+	}
+	return nil
+}
+
+// Helpers & Stuff ----------------------------------------------------------------------------------------------------
+
+// dialControl is the net.Dialer Control of the Sender, it runs on the resolved address of each conn
+func dialControl(_, address string, _ syscall.RawConn) error {
+	ap, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !isPublic(ap.Addr()) {
+		return fmt.Errorf("dial %v: %w", address, ErrForbiddenAddr)
+	}
+	return nil
+}
+
+func isPublic(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() || addr.IsMulticast() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() {
+		return false
+	}
+	for _, p := range nonPublicPrefixes {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,92 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/MuhamedUsman/letschat/internal/api/utility"
+	"github.com/MuhamedUsman/letschat/internal/domain"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	EventHeader    = "X-Letschat-Event"
+	WebhookHeader  = "X-Letschat-Webhook"
+	DeliveryHeader = "X-Letschat-Delivery" // the same across the retries of a delivery
+	// TimestampHeader is the unix time of the attempt, signed along the body, the receivers should reject the stale
+	// ones, so a captured request cannot be replayed
+	TimestampHeader = "X-Letschat-Timestamp"
+	// SignatureHeader is "sha256=" followed by the hex HMAC-SHA256 of "<timestamp>.<body>" keyed with the secret
+	SignatureHeader = "X-Letschat-Signature"
+)
+
+type Sender struct {
+	client *http.Client
+	// the webhooks may be served on the server's own network, for local development only
+	allowPrivate bool
+}
+
+func New(cfg *utility.Config) *Sender {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	if !cfg.Webhook.AllowPrivate {
+		d := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: dialControl}
+		t.DialContext = d.DialContext
+		// a proxy would dial the webhook instead, past the dialControl
+		t.Proxy = nil
+	}
+	return &Sender{
+		client: &http.Client{
+			Transport: t,
+			Timeout:   cfg.Webhook.Timeout,
+			// a redirect is reported as a failed attempt, the webhook must be subscribed with its final url
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		allowPrivate: cfg.Webhook.AllowPrivate,
+	}
+}
+
+// Send POSTs the payload of d to its webhook, returns the response status, zero if there was no response,
+// & an error for any status other than 2xx
+func (s *Sender) Send(ctx context.Context, d *domain.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+	ts := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Letschat-Webhook/1")
+	req.Header.Set(EventHeader, d.Event)
+	req.Header.Set(WebhookHeader, d.WebhookID)
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(d.ID, 10))
+	req.Header.Set(TimestampHeader, strconv.FormatInt(ts, 10))
+	req.Header.Set(SignatureHeader, "sha256="+Sign(d.Secret, ts, d.Payload))
+	res, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	// drained, so the conn is reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("unexpected response status %d", res.StatusCode)
+	}
+	return res.StatusCode, nil
+}
+
+// Sign is the hex HMAC-SHA256 of "<timestamp>.<body>" keyed with the secret, see SignatureHeader
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
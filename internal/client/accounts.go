@@ -0,0 +1,292 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/MuhamedUsman/letschat/internal/client/notify"
+	"github.com/MuhamedUsman/letschat/internal/client/repository"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// accountsFile in the FilesDir lists the accounts & the active one, e.g.
+//
+//	{"active": "2", "accounts": [{"id": "1", "profile": "local"}, {"id": "2", "profile": "staging"}]}
+const accountsFile = "accounts.json"
+
+// Account is the id of an account, it names its db & keyring entry, on the server profile by name
+type Account struct {
+	ID      string `json:"id"`
+	Profile string `json:"profile"`
+}
+
+type accountsState struct {
+	Active   string    `json:"active"`
+	Accounts []Account `json:"accounts"`
+}
+
+// Options are applied to the client of each account, see Client
+type Options struct {
+	AwayAfter           time.Duration
+	HeartbeatInterval   time.Duration
+	MaxMissedHeartbeats int
+	Notifiers           []notify.Notifier
+	QuietHours          *notify.Schedule
+}
+
+// Accounts are the clients of the accounts logged into, possibly on different servers, each keeps its ws conn & its
+// processes running in the background while another one is active in the tui
+type Accounts struct {
+	FilesDir string
+	opts     Options
+	cfg      config
+	creds    *credentials
+	// picked on startup, the env vars override it
+	profile Profile
+	// by name on startup, see LoadAccounts
+	requested string
+	mu        sync.Mutex
+	clients   []*Client
+	active    int
+	// on a profile no longer in the config, kept for when it is back
+	skipped []Account
+}
+
+// LoadAccounts opens the client of each account in the accountsFile & runs its processes, the active one is the first
+// on the profile by name if not empty, a new one is added on it if there is none, or the one active last time, it
+// waits for Unlock if the credentials are Locked
+func LoadAccounts(profile string, opts Options) (*Accounts, error) {
+	dir, err := getAppStoragePath(appName)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := loadConfig(dir)
+	if err != nil {
+		return nil, err
+	}
+	p, err := cfg.profile(profile, true)
+	if err != nil {
+		return nil, err
+	}
+	creds, err := openCredentials(dir, cfg.Credentials)
+	if err != nil {
+		return nil, err
+	}
+	a := &Accounts{FilesDir: dir, opts: opts, cfg: cfg, creds: creds, profile: p, requested: profile}
+	if creds.locked() {
+		return a, nil
+	}
+	return a, a.load()
+}
+
+// Locked reports whether the credentials file requires its passphrase before the accounts are loaded, see Unlock
+func (a *Accounts) Locked() bool {
+	return a.creds.locked()
+}
+
+// SettingPassphrase reports whether the credentials file is new, the passphrase given to Unlock then encrypts it
+func (a *Accounts) SettingPassphrase() bool {
+	return a.creds.isNew()
+}
+
+// Unlock unlocks the credentials file with passphrase & loads the accounts, ErrWrongPassphrase if it does not match
+func (a *Accounts) Unlock(passphrase string) error {
+	if err := a.creds.unlock(passphrase); err != nil {
+		return err
+	}
+	return a.load()
+}
+
+// Active returns the client of the account shown in the tui
+func (a *Accounts) Active() *Client {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.clients[a.active]
+}
+
+// List returns the clients of the accounts, in the order they were added
+func (a *Accounts) List() []*Client {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return slices.Clone(a.clients)
+}
+
+// Profiles returns the names of the profiles an account can be added on
+func (a *Accounts) Profiles() []string {
+	return a.cfg.profileNames()
+}
+
+// SetActive makes the account of c the active one, remembered for the next startup
+func (a *Accounts) SetActive(c *Client) error {
+	a.mu.Lock()
+	i := slices.Index(a.clients, c)
+	if i >= 0 {
+		a.active = i
+	}
+	a.mu.Unlock()
+	if i < 0 {
+		return errors.New("account not found")
+	}
+	return a.persist()
+}
+
+// Next makes the account after the active one the active one & returns its client
+func (a *Accounts) Next() (*Client, error) {
+	a.mu.Lock()
+	a.active = (a.active + 1) % len(a.clients)
+	c := a.clients[a.active]
+	a.mu.Unlock()
+	return c, a.persist()
+}
+
+// Add adds an account on the profile by name & makes it the active one, it requires a login
+func (a *Accounts) Add(profile string) (*Client, error) {
+	p := a.profile
+	if profile != p.Name {
+		var err error
+		if p, err = a.cfg.profile(profile, false); err != nil {
+			return nil, err
+		}
+	}
+	a.mu.Lock()
+	var last int
+	for _, c := range a.clients {
+		id, _ := strconv.Atoi(c.Account)
+		last = max(last, id)
+	}
+	for _, acc := range a.skipped {
+		id, _ := strconv.Atoi(acc.ID)
+		last = max(last, id)
+	}
+	a.mu.Unlock()
+	// the first one is 1, keeping the db of before the accounts
+	c, err := a.open(strconv.Itoa(last+1), p)
+	if err != nil {
+		return nil, err
+	}
+	a.mu.Lock()
+	a.clients = append(a.clients, c)
+	a.active = len(a.clients) - 1
+	a.mu.Unlock()
+	return c, a.persist()
+}
+
+// Remove stops the client of the account, other than the active one, & forgets it, its db & its keyring entry
+func (a *Accounts) Remove(c *Client) error {
+	a.mu.Lock()
+	i := slices.Index(a.clients, c)
+	if i < 0 || i == a.active {
+		a.mu.Unlock()
+		return errors.New("only an account other than the active one can be removed")
+	}
+	a.clients = slices.Delete(a.clients, i, i+1)
+	if i < a.active {
+		a.active--
+	}
+	a.mu.Unlock()
+	errs := []error{c.BT.Shutdown(5 * time.Second), c.db.Close()}
+	if c.AuthToken != "" {
+		errs = append(errs, c.krm.removeAuthTokenFromKeyring())
+	}
+	errs = append(errs, repository.DeleteDBFile(c.FilesDir, c.Profile.storageName(), c.Account), a.persist())
+	return errors.Join(errs...)
+}
+
+// Shutdown gracefully shuts down the processes of the clients of all the accounts
+func (a *Accounts) Shutdown(timeout time.Duration) error {
+	var errs []error
+	for _, c := range a.List() {
+		errs = append(errs, c.BT.Shutdown(timeout))
+	}
+	return errors.Join(errs...)
+}
+
+// Helpers & Stuff -----------------------------------------------------------------------------------------------------
+
+// load opens the clients of the accounts in the accountsFile, see LoadAccounts
+func (a *Accounts) load() error {
+	st, err := readAccountsState(a.FilesDir)
+	if err != nil {
+		return err
+	}
+	// of a failed attempt before
+	a.clients, a.active, a.skipped = nil, 0, nil
+	for _, acc := range st.Accounts {
+		ap := a.profile
+		if acc.Profile != ap.Name {
+			if ap, err = a.cfg.profile(acc.Profile, false); err != nil {
+				// e.g. removed from the config, the others are still usable
+				slog.Error("account skipped", "id", acc.ID, "err", err)
+				a.skipped = append(a.skipped, acc)
+				continue
+			}
+		}
+		c, err := a.open(acc.ID, ap)
+		if err != nil {
+			return errors.Join(fmt.Errorf("account %v: %w", acc.ID, err), a.Shutdown(5*time.Second))
+		}
+		a.clients = append(a.clients, c)
+		if acc.ID == st.Active {
+			a.active = len(a.clients) - 1
+		}
+	}
+	if p := a.profile; a.requested != "" {
+		if i := slices.IndexFunc(a.clients, func(c *Client) bool { return c.Profile.Name == p.Name }); i >= 0 {
+			a.active = i
+		} else if _, err = a.Add(p.Name); err != nil {
+			return errors.Join(err, a.Shutdown(5*time.Second))
+		}
+	} else if len(a.clients) == 0 {
+		if _, err = a.Add(a.profile.Name); err != nil {
+			return errors.Join(err, a.Shutdown(5*time.Second))
+		}
+	}
+	return a.persist()
+}
+
+func (a *Accounts) open(id string, p Profile) (*Client, error) {
+	c, err := newClient(a.FilesDir, id, p, a.creds.kr)
+	if err != nil {
+		return nil, err
+	}
+	c.AwayAfter, c.Notifiers, c.QuietHours = a.opts.AwayAfter, a.opts.Notifiers, a.opts.QuietHours
+	c.HeartbeatInterval, c.MaxMissedHeartbeats = a.opts.HeartbeatInterval, a.opts.MaxMissedHeartbeats
+	c.RunStartupProcesses()
+	return c, nil
+}
+
+func (a *Accounts) persist() error {
+	a.mu.Lock()
+	st := accountsState{Active: a.clients[a.active].Account}
+	for _, c := range a.clients {
+		st.Accounts = append(st.Accounts, Account{ID: c.Account, Profile: c.Profile.Name})
+	}
+	st.Accounts = append(st.Accounts, a.skipped...)
+	a.mu.Unlock()
+	b, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(a.FilesDir, accountsFile), b, 0o600)
+}
+
+func readAccountsState(dir string) (accountsState, error) {
+	var st accountsState
+	b, err := os.ReadFile(filepath.Join(dir, accountsFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return st, nil
+	}
+	if err != nil {
+		return st, err
+	}
+	if err = json.Unmarshal(b, &st); err != nil {
+		return st, fmt.Errorf("%v: %w", accountsFile, err)
+	}
+	return st, nil
+}
@@ -4,19 +4,19 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"github.com/99designs/keyring"
+	"github.com/MuhamedUsman/letschat/internal/client/notify"
 	"github.com/MuhamedUsman/letschat/internal/client/repository"
 	"github.com/MuhamedUsman/letschat/internal/common"
 	"github.com/MuhamedUsman/letschat/internal/domain"
 	"github.com/coder/websocket"
+	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
 	"sync"
-)
-
-var (
-	once   sync.Once
-	client *Client
+	"sync/atomic"
+	"time"
 )
 
 type Client struct {
@@ -25,6 +25,10 @@ type Client struct {
 	AuthToken string
 	// it's where all the application related files will live on the client side from db, logging anything
 	FilesDir string
+	// the id of the account, it names the db & the keyring entry along the Profile, see Accounts
+	Account string
+	// the server talked to, see config.profile
+	Profile Profile
 	// currently logged-in user we fetch and populates this once there is a read from UsrLogin chan
 	CurrentUsr  *domain.User
 	WsConnState *WsConnBroadcaster
@@ -41,10 +45,46 @@ type Client struct {
 	// will be called from main method after there is write on RunningTui chan from tui.TabContainerModel
 	// initialized in Init func
 	RunStartupProcesses func()
-	wsConn              *websocket.Conn
+	// the ws conn is pinged every HeartbeatInterval, & closed as stale after MaxMissedHeartbeats missed pongs in a row
+	HeartbeatInterval   time.Duration
+	MaxMissedHeartbeats int
+	// the tui reports the user as away after AwayAfter without focus or keyboard input, zero disables it
+	AwayAfter time.Duration
+	// the user is notified by each of the Notifiers of the msgs received outside the focused conversation, unless
+	// muted, the presence is DoNotDisturb or it is QuietHours, see notifyReceivedMsgs
+	Notifiers  []notify.Notifier
+	QuietHours *notify.Schedule
+	// the current ws conn, read by the writes of the presence outside the conn's goroutine
+	wsConn atomic.Pointer[websocket.Conn]
+	// trusts the CABundle of the Profile, if any
+	httpClient *http.Client
+	ep         endpoints
+	// heartbeat of the current ws conn, nil until the first connection
+	heartbeat atomic.Pointer[common.Heartbeat]
+	// reconnect delay suggested by the server while shutting down, consumed by attemptWsReconnectOnDisconnect
+	reconnectHint atomic.Int64
 	// talks to the api for managing native os based credential manager
 	krm      *keyringManager
 	sentMsgs sentMsgs
+	// false while the server streams the events missed since the last persisted cursor, see requestSync
+	synced atomic.Bool
+	// true once an event is relayed live after the last sync, see markLiveEvent
+	liveEvents atomic.Bool
+	// the ops applied locally, undone if the server rejects them
+	undoable undoableOps
+	// guards the presences received on the ws conn & the users whose presence is watched next to the conversations
+	presenceMu       sync.Mutex
+	presences        map[string]domain.Presence
+	discoveredUsrIDs []string
+	// chosen by the user, the one sent is Away instead while idle, unless DoNotDisturb
+	chosenPresence domain.PresenceStatus
+	idle           bool
+	// preferences of the current user, loaded on login, see RawMsgs & Muted
+	rawMsgs atomic.Bool
+	mutedMu sync.RWMutex
+	muted   map[string]bool
+	// the user id of the conversation the user has in front, empty if none or the terminal is out of focus
+	focusedConvo atomic.Value
 	// wrapper around *sqlx.DB
 	db *repository.DB
 	// directory to store application related files on client side, determined on startup for respected OS
@@ -52,36 +92,37 @@ type Client struct {
 	repo *repository.LocalRepository
 }
 
-// Init initializes Storage Dirs, keyringManager to support access token storage at OS level,
-// also opens a connection to sqlite DB, runs idempotent migrations, starts a goroutine to listen for user login,
-// a goroutine to connect to Ws and listen for recvMsgs
-// to get instance to a client use Get
-func Init(key int) error {
-	var c Client
+// newClient initializes the client of the account by its id on the server Profile p, the keyringManager to support
+// access token storage in kr, see credentials, also opens a connection to its own sqlite DB in filesDir, runs
+// idempotent migrations, RunStartupProcesses then starts a goroutine to listen for user login, a goroutine to connect
+// to Ws and listen for recvMsgs, see Accounts
+func newClient(filesDir, account string, p Profile, kr keyring.Keyring) (*Client, error) {
+	c := Client{FilesDir: filesDir, Account: account, Profile: p, ep: newEndpoints(p)}
 	var err error
-	once.Do(func() {
-		c.FilesDir, err = getAppStoragePath(appName)
-		c.krm, err = newKeyringManager(key)
-		if err != nil {
-			return
-		}
-		c.AuthToken = c.krm.getAuthTokenFromKeyring()
-		c.BT = common.NewBackgroundTask()
-		c.WsConnState = newWsConnBroadcaster()
-		c.LoginState = newLoginBroadcaster()
-		c.Conversations = newConvosBroadcaster()
-		c.RecvMsgs = newRecvMsgsBroadcaster()
-		// Connecting to sqlite
-		c.db, err = repository.OpenDB(c.FilesDir, key)
-		if err != nil {
-			return
-		}
-		c.repo = repository.NewLocalRepository(c.db)
-		// Running idempotent migrations
-		err = c.db.RunMigrations()
-	})
+	if c.httpClient, err = p.httpClient(); err != nil {
+		return nil, err
+	}
+	c.krm = newKeyringManager(kr, account, p.storageName())
+	c.AuthToken = c.krm.getAuthTokenFromKeyring()
+	c.BT = common.NewBackgroundTask()
+	c.AwayAfter = 5 * time.Minute
+	c.WsConnState = newWsConnBroadcaster()
+	c.LoginState = newLoginBroadcaster()
+	c.Conversations = newConvosBroadcaster()
+	c.RecvMsgs = newRecvMsgsBroadcaster()
+	c.presences = make(map[string]domain.Presence)
+	c.muted = make(map[string]bool)
+	c.focusedConvo.Store("")
+	c.chosenPresence = domain.Online
+	// Connecting to sqlite
+	c.db, err = repository.OpenDB(c.FilesDir, p.storageName(), account)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	c.repo = repository.NewLocalRepository(c.db)
+	// Running idempotent migrations
+	if err = c.db.RunMigrations(); err != nil {
+		return nil, err
 	}
 	c.RunStartupProcesses = sync.OnceFunc(func() {
 		c.BT.Run(func(shtdwnCtx context.Context) { c.LoginState.Broadcast(shtdwnCtx) })
@@ -93,6 +134,8 @@ func Init(key int) error {
 		c.BT.Run(func(shtdwnCtx context.Context) { c.attemptWsReconnectOnDisconnect(shtdwnCtx) })
 		c.BT.Run(func(shtdwnCtx context.Context) { c.wsConnectAndListenForMessages(shtdwnCtx) })
 		c.BT.Run(func(shtdwnCtx context.Context) { c.populateConversationsAccordingToWsConnState(shtdwnCtx) })
+		c.BT.Run(func(shtdwnCtx context.Context) { c.sweepExpiredMsgs(shtdwnCtx) })
+		c.BT.Run(func(shtdwnCtx context.Context) { c.notifyReceivedMsgs(shtdwnCtx) })
 		u, err := c.repo.GetCurrentUser()
 		if err != nil {
 			if errors.Is(err, domain.ErrRecordNotFound) {
@@ -103,13 +146,7 @@ func Init(key int) error {
 		}
 		c.CurrentUsr = u
 	})
-	client = &c
-	return nil
-}
-
-// Get returns singleton instance of a Client
-func Get() *Client {
-	return client
+	return &c, nil
 }
 
 // Helpers & Stuff -----------------------------------------------------------------------------------------------------
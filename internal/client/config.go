@@ -0,0 +1,179 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// configFile in the FilesDir holds the server profiles by name, besides the built-in ones, the default one & where the
+// auth tokens are stored, see credentialsConfig, e.g.
+//
+//	{
+//	  "default": "staging",
+//	  "profiles": {
+//	    "staging": {"baseUrl": "https://staging.example.com/v1", "caBundle": "/etc/letschat/staging-ca.pem"}
+//	  },
+//	  "credentials": {"backend": "file", "cachePassphrase": true}
+//	}
+//
+// the wsUrl of a profile is derived from its baseUrl if not set, e.g. wss://staging.example.com
+const configFile = "config.json"
+
+const (
+	localProfile      = "local"
+	productionProfile = "production"
+)
+
+// env vars overriding the urls & the ca bundle of the active profile
+const (
+	serverEnv   = "LETSCHAT_SERVER"
+	wsServerEnv = "LETSCHAT_WS_SERVER"
+	caBundleEnv = "LETSCHAT_CA_BUNDLE"
+)
+
+// Profile is a server the client talks to, each keeps its own db & keyring entry, so switching between them keeps the
+// login & the msgs of each
+type Profile struct {
+	Name    string `json:"-"`
+	BaseUrl string `json:"baseUrl"`
+	WsUrl   string `json:"wsUrl"`
+	// pem file of the certificates trusted besides those of the system, e.g. of a server with a self-signed one
+	CABundle string `json:"caBundle"`
+}
+
+type config struct {
+	Default     string             `json:"default"`
+	Profiles    map[string]Profile `json:"profiles"`
+	Credentials credentialsConfig  `json:"credentials"`
+}
+
+var builtinProfiles = map[string]Profile{
+	localProfile:      {BaseUrl: "http://localhost:8080/v1", WsUrl: "ws://localhost:8080"},
+	productionProfile: {BaseUrl: "https://muhammadusman.site/v1", WsUrl: "wss://muhammadusman.site"},
+}
+
+// loadConfig returns the configFile in dir over the built-in profiles
+func loadConfig(dir string) (config, error) {
+	cfg := config{Default: localProfile, Profiles: maps.Clone(builtinProfiles)}
+	b, err := os.ReadFile(filepath.Join(dir, configFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return config{}, err
+	}
+	var fileCfg config
+	if err = json.Unmarshal(b, &fileCfg); err != nil {
+		return config{}, fmt.Errorf("%v: %w", configFile, err)
+	}
+	if fileCfg.Default != "" {
+		cfg.Default = fileCfg.Default
+	}
+	maps.Copy(cfg.Profiles, fileCfg.Profiles)
+	cfg.Credentials = fileCfg.Credentials
+	return cfg, nil
+}
+
+// profile returns the profile by name, the default one if name is empty, overridden by the LETSCHAT_SERVER,
+// LETSCHAT_WS_SERVER & LETSCHAT_CA_BUNDLE env vars if env
+func (cfg config) profile(name string, env bool) (Profile, error) {
+	if name == "" {
+		name = cfg.Default
+	}
+	p, ok := cfg.Profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("unknown profile %q, the profiles are in the %v of the app data", name, configFile)
+	}
+	// it names the db file & the keyring entry
+	if strings.ContainsFunc(name, func(r rune) bool { return !isProfileNameRune(r) }) {
+		return Profile{}, fmt.Errorf("profile %q: only letters, digits, - & _ are allowed in the name", name)
+	}
+	p.Name = name
+	if env {
+		if s := os.Getenv(serverEnv); s != "" {
+			p.BaseUrl, p.WsUrl = s, ""
+		}
+		if s := os.Getenv(wsServerEnv); s != "" {
+			p.WsUrl = s
+		}
+		if s := os.Getenv(caBundleEnv); s != "" {
+			p.CABundle = s
+		}
+	}
+	if err := p.normalize(); err != nil {
+		return Profile{}, fmt.Errorf("profile %q: %w", name, err)
+	}
+	return p, nil
+}
+
+// profileNames returns the names of the profiles, sorted
+func (cfg config) profileNames() []string {
+	return slices.Sorted(maps.Keys(cfg.Profiles))
+}
+
+// httpClient returns the client trusting the CABundle of p, if any, used for both the api & the ws conn
+func (p Profile) httpClient() (*http.Client, error) {
+	if p.CABundle == "" {
+		return http.DefaultClient, nil
+	}
+	pem, err := os.ReadFile(p.CABundle)
+	if err != nil {
+		return nil, fmt.Errorf("ca bundle of profile %q: %w", p.Name, err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("ca bundle of profile %q: no certificates in %v", p.Name, p.CABundle)
+	}
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.TLSClientConfig = &tls.Config{RootCAs: pool}
+	return &http.Client{Transport: t}, nil
+}
+
+// storageName tells apart the db & the keyring entry of p from those of the other profiles, the local profile keeps
+// the ones it had before the profiles
+func (p Profile) storageName() string {
+	if p.Name == localProfile {
+		return ""
+	}
+	return p.Name
+}
+
+// Helpers & Stuff -----------------------------------------------------------------------------------------------------
+
+// normalize validates the urls of p, without a trailing slash, deriving the WsUrl from the BaseUrl if empty
+func (p *Profile) normalize() error {
+	p.BaseUrl, p.WsUrl = strings.TrimSuffix(p.BaseUrl, "/"), strings.TrimSuffix(p.WsUrl, "/")
+	base, err := url.Parse(p.BaseUrl)
+	if err != nil || (base.Scheme != "http" && base.Scheme != "https") || base.Host == "" {
+		return fmt.Errorf("baseUrl must be an http or https url, got %q", p.BaseUrl)
+	}
+	if p.WsUrl == "" {
+		ws := url.URL{Scheme: "ws", Host: base.Host}
+		if base.Scheme == "https" {
+			ws.Scheme = "wss"
+		}
+		p.WsUrl = ws.String()
+	}
+	ws, err := url.Parse(p.WsUrl)
+	if err != nil || (ws.Scheme != "ws" && ws.Scheme != "wss") || ws.Host == "" {
+		return fmt.Errorf("wsUrl must be a ws or wss url, got %q", p.WsUrl)
+	}
+	return nil
+}
+
+func isProfileNameRune(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_'
+}
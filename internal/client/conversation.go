@@ -46,6 +46,9 @@ func (c *Client) populateConversationsAccordingToWsConnState(shtdwnCtx context.C
 					c.saveConvosAndWriteToChan(convos)
 				}
 			case Disconnected:
+				c.presenceMu.Lock()
+				clear(c.presences)
+				c.presenceMu.Unlock()
 				convos, err := c.repo.GetConversations()
 				if err != nil {
 					log.Fatal(err)
@@ -60,13 +63,13 @@ func (c *Client) populateConversationsAccordingToWsConnState(shtdwnCtx context.C
 }
 
 func (c *Client) getConversations() ([]*domain.Conversation, int, error) {
-	r, err := http.NewRequest(http.MethodGet, getConversations, nil)
+	r, err := http.NewRequest(http.MethodGet, c.ep.getConversations, nil)
 	if err != nil {
 		slog.Error(err.Error())
 		return nil, 0, ErrApplication
 	}
 	r.Header.Set("Authorization", "Bearer "+c.AuthToken)
-	resp, err := http.DefaultClient.Do(r)
+	resp, err := c.httpClient.Do(r)
 	if err != nil {
 		slog.Error(err.Error())
 		return nil, http.StatusServiceUnavailable, getMostNestedError(err)
@@ -116,6 +119,12 @@ func (c *Client) populateConvosWithLatestMsgs(convos []*domain.Conversation) []*
 	return convos
 }
 
+// RefreshConversations writes the latest conversations to the subscribers again, e.g. of the tui switching to the
+// account, as they are written on the changes only
+func (c *Client) RefreshConversations() {
+	c.getPopulateSaveConvosAndWriteToChan()
+}
+
 func (c *Client) conversationExistsWithReceiver(receiverID string) (bool, error) {
 	if _, err := c.repo.GetConversationByUserID(receiverID); err != nil {
 		if errors.Is(err, domain.ErrRecordNotFound) {
@@ -131,4 +140,8 @@ func (c *Client) saveConvosAndWriteToChan(convos []*domain.Conversation) {
 	c.Conversations.Write(convos)
 	_ = c.repo.DeleteAllConversations()
 	_ = c.repo.SaveConversations(convos...)
+	// a new conversation may not be watched yet
+	if err := c.subscribePresence(); err != nil {
+		slog.Error(err.Error())
+	}
 }
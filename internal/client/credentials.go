@@ -0,0 +1,340 @@
+package client
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/99designs/keyring"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/scrypt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// credentialsFile in the FilesDir holds the auth tokens of the accounts, encrypted with a passphrase, where the OS has
+// no keyring, e.g. on a headless linux box over ssh without a secret service
+const credentialsFile = "credentials.json"
+
+// the credentials backends of the config
+const (
+	// the keyring of the OS, falling back to the credentialsFile
+	autoBackend = "auto"
+	// the keyring of the OS, or the kernel keyring of linux, forgetting the tokens on reboot
+	keyringBackend = "keyring"
+	fileBackend    = "file"
+)
+
+// minPassphraseLen applies when the passphrase of a new credentialsFile is set
+const minPassphraseLen = 8
+
+var (
+	// names the passphrase of the credentialsFile in the kernel keyring, if cached
+	passphraseKey = appName + " Passphrase"
+
+	ErrWrongPassphrase = errors.New("wrong passphrase")
+	errLocked          = errors.New("the credentials are locked, the passphrase is required")
+)
+
+// the keyrings of the OS, persistent unlike the kernel keyring
+var osBackends = []keyring.BackendType{
+	keyring.WinCredBackend,
+	keyring.KeychainBackend,
+	keyring.SecretServiceBackend,
+	keyring.KWalletBackend,
+}
+
+// credentialsConfig is the "credentials" of the configFile, e.g. {"backend": "file", "cachePassphrase": true}
+type credentialsConfig struct {
+	// auto, keyring or file, auto if empty
+	Backend string `json:"backend"`
+	// caches the passphrase of the credentialsFile in the user keyring of the kernel, on linux only, so the next
+	// sessions till a reboot do not ask for it
+	CachePassphrase bool `json:"cachePassphrase"`
+}
+
+// credentials store the auth tokens of the accounts in kr, locked until the passphrase is known if it is the file
+type credentials struct {
+	kr    keyring.Keyring
+	file  *fileKeyring
+	cache bool
+}
+
+// openCredentials opens the backend of cfg, unlocking the credentialsFile with the cached passphrase if any
+func openCredentials(dir string, cfg credentialsConfig) (*credentials, error) {
+	switch cfg.Backend {
+	case "", autoBackend:
+		if kr, err := keyring.Open(keyringConfig(osBackends)); err == nil {
+			return &credentials{kr: kr}, nil
+		}
+		slog.Info("no keyring of the os available, using the credentials file")
+	case keyringBackend:
+		kr, err := keyring.Open(keyringConfig(append(osBackends, keyring.KeyCtlBackend)))
+		if err != nil {
+			return nil, fmt.Errorf("no keyring available, the %q credentials backend might do: %w", fileBackend, err)
+		}
+		return &credentials{kr: kr}, nil
+	case fileBackend:
+	default:
+		return nil, fmt.Errorf("unknown credentials backend %q, it is one of %v, %v or %v",
+			cfg.Backend, autoBackend, keyringBackend, fileBackend)
+	}
+	f := &fileKeyring{path: filepath.Join(dir, credentialsFile)}
+	cr := &credentials{kr: f, file: f, cache: cfg.CachePassphrase}
+	if cr.cache {
+		if pass, ok := cachedPassphrase(); ok && f.unlock(pass) != nil {
+			// changed since, e.g. the file was replaced
+			uncachePassphrase()
+		}
+	}
+	return cr, nil
+}
+
+// locked reports whether the passphrase of the credentialsFile is required
+func (cr *credentials) locked() bool {
+	return cr.file != nil && cr.file.locked()
+}
+
+// isNew reports whether the credentialsFile does not exist yet, the passphrase unlocking it is being set
+func (cr *credentials) isNew() bool {
+	if cr.file == nil {
+		return false
+	}
+	_, err := os.Stat(cr.file.path)
+	return errors.Is(err, os.ErrNotExist)
+}
+
+// unlock unlocks the credentialsFile with passphrase, setting it if the file is new, & caches it if configured so
+func (cr *credentials) unlock(passphrase string) error {
+	if cr.file == nil {
+		return nil
+	}
+	if cr.isNew() && len(passphrase) < minPassphraseLen {
+		return fmt.Errorf("the passphrase must be at least %d characters long", minPassphraseLen)
+	}
+	if err := cr.file.unlock(passphrase); err != nil {
+		return err
+	}
+	if cr.cache {
+		cachePassphrase(passphrase)
+	}
+	return nil
+}
+
+// Helpers & Stuff -----------------------------------------------------------------------------------------------------
+
+func keyringConfig(backends []keyring.BackendType) keyring.Config {
+	return keyring.Config{
+		AllowedBackends:         backends,
+		ServiceName:             serviceName,
+		KeyCtlScope:             "user",
+		LibSecretCollectionName: appName,
+		WinCredPrefix:           appName,
+	}
+}
+
+// passphraseCache is the user keyring of the kernel, it errs on the OSes other than linux
+func passphraseCache() (keyring.Keyring, error) {
+	return keyring.Open(keyring.Config{
+		AllowedBackends: []keyring.BackendType{keyring.KeyCtlBackend},
+		ServiceName:     appName,
+		KeyCtlScope:     "user",
+	})
+}
+
+func cachedPassphrase() (string, bool) {
+	kr, err := passphraseCache()
+	if err != nil {
+		return "", false
+	}
+	item, err := kr.Get(passphraseKey)
+	if err != nil {
+		return "", false
+	}
+	return string(item.Data), true
+}
+
+func cachePassphrase(passphrase string) {
+	kr, err := passphraseCache()
+	if err != nil {
+		slog.Error("caching the passphrase", "err", err)
+		return
+	}
+	item := keyring.Item{Key: passphraseKey, Data: []byte(passphrase), Label: passphraseKey}
+	if err = kr.Set(item); err != nil {
+		slog.Error("caching the passphrase", "err", err)
+	}
+}
+
+func uncachePassphrase() {
+	if kr, err := passphraseCache(); err == nil {
+		_ = kr.Remove(passphraseKey)
+	}
+}
+
+// fileKeyring is the keyring.Keyring over the credentialsFile, its items are sealed together with a key derived from
+// the passphrase by scrypt, it errs until unlocked
+type fileKeyring struct {
+	path  string
+	mu    sync.Mutex
+	key   []byte
+	salt  []byte
+	items map[string]fileItem
+}
+
+type fileItem struct {
+	keyring.Item
+	ModifiedAt time.Time `json:"modifiedAt"`
+}
+
+// sealedFile is the credentialsFile, the items are sealed by XChaCha20-Poly1305
+type sealedFile struct {
+	Salt  []byte `json:"salt"`
+	Nonce []byte `json:"nonce"`
+	Items []byte `json:"items"`
+}
+
+func (f *fileKeyring) locked() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.key == nil
+}
+
+// unlock opens the file with passphrase, or creates it if it does not exist
+func (f *fileKeyring) unlock(passphrase string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	b, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		salt := make([]byte, 16)
+		if _, err = rand.Read(salt); err != nil {
+			return err
+		}
+		if f.key, err = deriveKey(passphrase, salt); err != nil {
+			return err
+		}
+		f.salt, f.items = salt, make(map[string]fileItem)
+		return f.save()
+	}
+	if err != nil {
+		return err
+	}
+	var sf sealedFile
+	if err = json.Unmarshal(b, &sf); err != nil {
+		return fmt.Errorf("%v: %w", credentialsFile, err)
+	}
+	key, err := deriveKey(passphrase, sf.Salt)
+	if err != nil {
+		return err
+	}
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return err
+	}
+	plain, err := aead.Open(nil, sf.Nonce, sf.Items, nil)
+	if err != nil {
+		return ErrWrongPassphrase
+	}
+	items := make(map[string]fileItem)
+	if err = json.Unmarshal(plain, &items); err != nil {
+		return fmt.Errorf("%v: %w", credentialsFile, err)
+	}
+	f.key, f.salt, f.items = key, sf.Salt, items
+	return nil
+}
+
+func (f *fileKeyring) Get(key string) (keyring.Item, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.key == nil {
+		return keyring.Item{}, errLocked
+	}
+	item, ok := f.items[key]
+	if !ok {
+		return keyring.Item{}, keyring.ErrKeyNotFound
+	}
+	return item.Item, nil
+}
+
+func (f *fileKeyring) GetMetadata(key string) (keyring.Metadata, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.key == nil {
+		return keyring.Metadata{}, errLocked
+	}
+	item, ok := f.items[key]
+	if !ok {
+		return keyring.Metadata{}, keyring.ErrKeyNotFound
+	}
+	item.Data = nil
+	return keyring.Metadata{Item: &item.Item, ModificationTime: item.ModifiedAt}, nil
+}
+
+func (f *fileKeyring) Set(item keyring.Item) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.key == nil {
+		return errLocked
+	}
+	f.items[item.Key] = fileItem{Item: item, ModifiedAt: time.Now()}
+	return f.save()
+}
+
+func (f *fileKeyring) Remove(key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.key == nil {
+		return errLocked
+	}
+	if _, ok := f.items[key]; !ok {
+		return keyring.ErrKeyNotFound
+	}
+	delete(f.items, key)
+	return f.save()
+}
+
+func (f *fileKeyring) Keys() ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.key == nil {
+		return nil, errLocked
+	}
+	keys := make([]string, 0, len(f.items))
+	for k := range f.items {
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+// save seals the items with a new nonce, replacing the file at once so a crash leaves either the old or the new one
+func (f *fileKeyring) save() error {
+	plain, err := json.Marshal(f.items)
+	if err != nil {
+		return err
+	}
+	aead, err := chacha20poly1305.NewX(f.key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return err
+	}
+	b, err := json.Marshal(sealedFile{Salt: f.salt, Nonce: nonce, Items: aead.Seal(nil, nonce, plain, nil)})
+	if err != nil {
+		return err
+	}
+	tmp := f.path + ".tmp"
+	if err = os.WriteFile(tmp, b, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, f.path)
+}
+
+func deriveKey(passphrase string, salt []byte) ([]byte, error) {
+	// the recommended parameters for interactive logins
+	return scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, chacha20poly1305.KeySize)
+}
@@ -0,0 +1,91 @@
+package client
+
+import (
+	"context"
+	"github.com/MuhamedUsman/letschat/internal/domain"
+	"log/slog"
+	"time"
+)
+
+// expiredMsgsSweepInterval is how often the msgs of the conversations with disappearing msgs on are purged locally
+const expiredMsgsSweepInterval = 5 * time.Second
+
+// SetDisappearAfter turns the disappearing msgs of the conversation with usrID on for both of its users, zero turns
+// them off, the msgs sent afterward disappear once after has passed since they were sent
+func (c *Client) SetDisappearAfter(usrID string, after time.Duration) error {
+	secs := int64(after / time.Second)
+	c.sentMsgs.msgs <- &domain.Message{
+		SenderID:       c.CurrentUsr.ID,
+		ReceiverID:     usrID,
+		SentAt:         ptr(time.Now()),
+		Operation:      domain.DisappearingMsg,
+		DisappearAfter: &secs,
+	}
+	if !<-c.sentMsgs.done {
+		return ErrMsgNotSent
+	}
+	c.setConvoDisappearAfter(usrID, secs)
+	return nil
+}
+
+// DisappearAfter returns the disappearing msgs setting of the conversation with usrID, zero if off
+func (c *Client) DisappearAfter(usrID string) time.Duration {
+	for _, convo := range c.Conversations.Get() {
+		if convo.UserID == usrID {
+			return time.Duration(convo.DisappearAfter) * time.Second
+		}
+	}
+	return 0
+}
+
+// ExpiresAt returns the expiry of a msg sent at sentAt to usrID, nil if the disappearing msgs are off, the server
+// stamps the msg it relays counting from when it receives it, about the same
+func (c *Client) ExpiresAt(usrID string, sentAt time.Time) *time.Time {
+	after := c.DisappearAfter(usrID)
+	if after == 0 {
+		return nil
+	}
+	return ptr(sentAt.Add(after))
+}
+
+// Helpers & Stuff -----------------------------------------------------------------------------------------------------
+
+// setConvoDisappearAfter updates the conversation with usrID, its setting is shown in the chat header
+func (c *Client) setConvoDisappearAfter(usrID string, secs int64) {
+	convos := c.Conversations.Get()
+	for i := range convos {
+		if convos[i].UserID == usrID {
+			convos[i].DisappearAfter = secs
+			c.saveConvosAndWriteToChan(convos)
+			break
+		}
+	}
+}
+
+// sweepExpiredMsgs purges the expired msgs every expiredMsgsSweepInterval, relaying each purged one to the tui as a
+// domain.ExpiredMsg on RecvMsgs, must be run in a separate long-running goroutine
+func (c *Client) sweepExpiredMsgs(shtdwnCtx context.Context) {
+	ticker := time.NewTicker(expiredMsgsSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			expired, err := c.repo.DeleteExpiredMsgs(time.Now())
+			if err != nil {
+				slog.Error(err.Error())
+				continue
+			}
+			if len(expired) == 0 {
+				continue
+			}
+			for _, msg := range expired {
+				msg.Operation = domain.ExpiredMsg
+				c.RecvMsgs.Write(msg)
+			}
+			// the purged msgs may be the latest ones
+			c.getPopulateSaveConvosAndWriteToChan()
+		case <-shtdwnCtx.Done():
+			return
+		}
+	}
+}
@@ -1,25 +1,49 @@
 package client
 
 const (
-	//baseUrl               = "https://muhammadusman.site/v1"
-	baseUrl               = "http://localhost:8080/v1"
 	usersEndpoint         = "/users"
 	tokensEndpoint        = "/tokens"
 	conversationsEndpoint = "/conversations"
-	wsBaseUrl             = "ws://localhost:8080"
+	scheduledEndpoint     = "/messages/scheduled"
 	websocketsEndpoint    = "/sub"
+)
 
-	registerUser         = baseUrl + usersEndpoint // POST
-	getByUniqueField     = baseUrl + usersEndpoint // GET
-	getCurrentActiveUser = getByUniqueField + "/current"
-	searchUser           = getByUniqueField
-	updateUser           = baseUrl + usersEndpoint               // PUT
-	activateUser         = baseUrl + usersEndpoint + "/activate" // POST
+// endpoints are the urls on the server of a Profile, each client has its own
+type endpoints struct {
+	registerUser         string // POST
+	getByUniqueField     string // GET
+	getCurrentActiveUser string
+	searchUser           string
+	updateUser           string // PUT
+	activateUser         string // POST
 
-	generateOTP  = baseUrl + tokensEndpoint + "/otp"  // POST
-	authenticate = baseUrl + tokensEndpoint + "/auth" // POST
+	generateOTP  string // POST
+	authenticate string // POST
 
-	getConversations = baseUrl + conversationsEndpoint
+	getConversations string
 
-	subscribeTo = wsBaseUrl + websocketsEndpoint
-)
+	scheduledMessages string // GET, POST & PUT, DELETE with "/{id}"
+
+	subscribeTo string
+}
+
+func newEndpoints(p Profile) endpoints {
+	baseUrl, wsBaseUrl := p.BaseUrl, p.WsUrl
+	return endpoints{
+		registerUser:         baseUrl + usersEndpoint,
+		getByUniqueField:     baseUrl + usersEndpoint,
+		getCurrentActiveUser: baseUrl + usersEndpoint + "/current",
+		searchUser:           baseUrl + usersEndpoint,
+		updateUser:           baseUrl + usersEndpoint,
+		activateUser:         baseUrl + usersEndpoint + "/activate",
+
+		generateOTP:  baseUrl + tokensEndpoint + "/otp",
+		authenticate: baseUrl + tokensEndpoint + "/auth",
+
+		getConversations: baseUrl + conversationsEndpoint,
+
+		scheduledMessages: baseUrl + scheduledEndpoint,
+
+		subscribeTo: wsBaseUrl + websocketsEndpoint,
+	}
+}
@@ -11,27 +11,23 @@ var (
 )
 
 type keyringManager struct {
-	kr  keyring.Keyring
-	key int
+	kr keyring.Keyring
+	// the token of each account is its own entry
+	tokenKey string
 }
 
-func newKeyringManager(key int) (*keyringManager, error) {
-	cfg := keyring.Config{
-		ServiceName:             serviceName,
-		KeyCtlScope:             "user",
-		LibSecretCollectionName: appName,
-		WinCredPrefix:           appName,
+// newKeyringManager takes the keyring of the credentials, the id of the account & the storage name of its profile
+func newKeyringManager(kr keyring.Keyring, account, profile string) *keyringManager {
+	k := &keyringManager{kr: kr, tokenKey: tokenKey + " #" + account}
+	if profile != "" {
+		k.tokenKey += " (" + profile + ")"
 	}
-	kr, err := keyring.Open(cfg)
-	if err != nil {
-		return nil, err
-	}
-	return &keyringManager{kr: kr, key: key}, nil
+	return k
 }
 
 func (k *keyringManager) setAuthTokenInKeyring(label, data string) error {
 	item := keyring.Item{
-		Key:         tokenKey,
+		Key:         k.tokenKey,
 		Data:        []byte(data),
 		Description: "auth token to validate user after basic login",
 	}
@@ -40,19 +36,13 @@ func (k *keyringManager) setAuthTokenInKeyring(label, data string) error {
 }
 
 func (k *keyringManager) removeAuthTokenFromKeyring() error {
-	return k.kr.Remove(tokenKey)
+	return k.kr.Remove(k.tokenKey)
 }
 
 func (k *keyringManager) getAuthTokenFromKeyring() string {
-	/*token, err := k.kr.Get(tokenKey)
+	token, err := k.kr.Get(k.tokenKey)
 	if err != nil {
 		return ""
 	}
-	return string(token.Data)*/
-	if k.key == 1 {
-		return "7IQWKCP62SMPVEBXYTEPIYW5KM"
-	} else if k.key == 2 {
-		return "DT7OMUDGN4LSVIDQQPRGF4WWKQ"
-	}
-	return ""
+	return string(token.Data)
 }
@@ -84,57 +84,108 @@ func (c *Client) SendTypingStatus(msg domain.Message) {
 	}
 }
 
-func (c *Client) GetMessagesAsPageAndMarkAsRead(senderID string, page int) ([]*domain.Message, *domain.Metadata, error) {
+const msgsPageSize = 25
+
+// GetMessagesAsPage returns a page of the conversation with the sender, if markAsRead the unread msgs of the page
+// are marked as read, it must be false while the user is not looking at them, e.g. the terminal is not in focus
+func (c *Client) GetMessagesAsPage(senderID string, page int, markAsRead bool) ([]*domain.Message, *domain.Metadata, error) {
 	f := domain.Filter{
 		Page:     page,
-		PageSize: 25,
+		PageSize: msgsPageSize,
 	}
 	msgs, metadata, err := c.repo.GetMsgsAsPage(senderID, f)
 	if err != nil {
 		return nil, nil, err
 	}
-	var msgsToSetAsRead []*domain.Message
+	if !markAsRead {
+		return msgs, metadata, nil
+	}
+	var latestUnread *domain.Message
+	readAt := ptr(time.Now())
 	for _, msg := range msgs {
 		if c.isValidReadUpdate(msg) {
-			msg.ReadAt = ptr(time.Now())
-			msgsToSetAsRead = append(msgsToSetAsRead, msg)
+			msg.ReadAt = readAt
+			if latestUnread == nil { // msgs are ordered by sent_at DESC
+				latestUnread = msg
+			}
 		}
 	}
-	c.BT.Run(func(shtdwnCtx context.Context) {
-		for _, msg := range msgsToSetAsRead {
-			// I/O call
-			_ = c.SetMsgAsRead(msg) // Ignore & retry on reconnect
-		}
-	})
+	if latestUnread != nil {
+		c.BT.Run(func(shtdwnCtx context.Context) {
+			// I/O call, a single watermark for all the unread msgs
+			_ = c.SetMsgAsRead(latestUnread) // Ignore
+		})
+	}
 	return msgs, metadata, nil
 }
 
+// SearchMessages returns a page of the local msgs matching the query, of the conversation with convo or of all of
+// them if empty
+func (c *Client) SearchMessages(query, convo string, page int) ([]*domain.MessageHit, *domain.Metadata, error) {
+	f := domain.Filter{
+		Page:     page,
+		PageSize: 50,
+	}
+	return c.repo.Search(query, convo, f)
+}
+
+// GetMessagesPageOf returns the page of the conversation with the sender holding the msg, see GetMessagesAsPage
+func (c *Client) GetMessagesPageOf(senderID, msgID string, markAsRead bool) ([]*domain.Message, *domain.Metadata, error) {
+	offset, err := c.repo.GetMsgOffset(senderID, msgID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c.GetMessagesAsPage(senderID, offset/msgsPageSize+1, markAsRead)
+}
+
 func (c *Client) handleReceivedMsgs(shtdwnCtx context.Context) {
 	token, ch := c.RecvMsgs.Subscribe()
 	defer c.RecvMsgs.Unsubscribe(token)
+	// latest msg per sender streamed while syncing, their deliveries are confirmed at once on domain.SyncDoneMsg
+	undelivered := make(map[string]time.Time)
 	for {
 		select {
 		case msg := <-ch:
 			switch msg.Operation {
 
 			case domain.CreateMsg:
-				err := c.repo.SaveMsg(msg)
+				// events may be replayed while syncing, the msg is only saved & its delivery confirmed once
+				existing, err := c.repo.GetMsgByID(msg.ID)
+				// only its author may edit a msg
+				if err == nil && existing.SenderID != msg.SenderID {
+					slog.Warn("msg rejected, its id is of another sender", "id", msg.ID, "sender", msg.SenderID)
+					break
+				}
+				if err == nil && existing.DeliveredAt != nil {
+					break
+				}
 				if err != nil {
-					slog.Error(err.Error())
+					if err = c.repo.SaveMsg(msg); err != nil {
+						slog.Error(err.Error())
+					}
 				}
-				if err = c.setMsgAsDelivered(msg.ID, msg.SenderID); err != nil {
-					slog.Error(err.Error())
+				if c.synced.Load() {
+					if err = c.setMsgAsDelivered(msg.ID, msg.SenderID); err != nil {
+						slog.Error(err.Error())
+					}
+				} else if msg.SentAt != nil {
+					if upTo, ok := undelivered[msg.SenderID]; !ok || msg.SentAt.After(upTo) {
+						undelivered[msg.SenderID] = *msg.SentAt
+					}
 				}
 				c.getPopulateSaveConvosAndWriteToChan()
 
 			case domain.DeliveredMsg:
-				if err := c.repo.UpdateMsg(msg); err != nil {
-					slog.Error(err.Error())
+				// only the receiver of a msg may mark it as delivered
+				if c.isReceiverOf(msg) {
+					if err := c.repo.UpdateMsg(msg); err != nil {
+						slog.Error(err.Error())
+					}
 				}
 				// echo back delivery confirmation
 				c.sentMsgs.msgs <- &domain.Message{
 					ID:         msg.ID,
-					SenderID:   client.CurrentUsr.ID,
+					SenderID:   c.CurrentUsr.ID,
 					ReceiverID: msg.SenderID,
 					Body:       "",
 					SentAt:     ptr(time.Now()),
@@ -145,13 +196,16 @@ func (c *Client) handleReceivedMsgs(shtdwnCtx context.Context) {
 				}
 
 			case domain.ReadMsg:
-				if err := c.repo.UpdateMsg(msg); err != nil {
-					slog.Error(err.Error())
+				// only the receiver of a msg may mark it as read
+				if c.isReceiverOf(msg) {
+					if err := c.repo.UpdateMsg(msg); err != nil {
+						slog.Error(err.Error())
+					}
 				}
 				// echo back read confirmation
 				c.sentMsgs.msgs <- &domain.Message{
 					ID:         msg.ID,
-					SenderID:   client.CurrentUsr.ID,
+					SenderID:   c.CurrentUsr.ID,
 					ReceiverID: msg.SenderID,
 					Body:       "",
 					SentAt:     ptr(time.Now()),
@@ -162,8 +216,11 @@ func (c *Client) handleReceivedMsgs(shtdwnCtx context.Context) {
 				}
 
 			case domain.DeleteMsg:
-				_ = c.repo.DeleteMsg(msg.ID)
-				c.getPopulateSaveConvosAndWriteToChan()
+				// only the author of a msg may delete it for everyone
+				if m, err := c.repo.GetMsgByID(msg.ID); err == nil && m.SenderID == msg.SenderID {
+					_ = c.repo.DeleteMsg(msg.ID)
+					c.getPopulateSaveConvosAndWriteToChan()
+				}
 				// echo back with delete confirmation
 				c.sentMsgs.msgs <- &domain.Message{
 					ID:         msg.ID,
@@ -177,11 +234,50 @@ func (c *Client) handleReceivedMsgs(shtdwnCtx context.Context) {
 					slog.Error("unable to echo back deletion confirmation")
 				}
 
-			case domain.OnlineMsg:
-				c.setUsrOnlineStatus(msg, true)
+			case domain.ScheduledSentMsg:
+				// our scheduled msg released by the server, events may be replayed while syncing, it is saved once
+				if _, err := c.repo.GetMsgByID(msg.ID); err != nil {
+					sent := *msg
+					sent.Operation = domain.CreateMsg
+					if err = c.repo.SaveMsg(&sent); err != nil {
+						slog.Error(err.Error())
+					}
+				}
+				c.refreshConvosWith(msg.ReceiverID)
+
+			case domain.PresenceMsg:
+				c.setUsrPresence(msg)
+
+			case domain.ProfileMsg:
+				c.setUsrProfile(msg)
+
+			case domain.DisappearingMsg:
+				if msg.DisappearAfter != nil {
+					c.setConvoDisappearAfter(msg.SenderID, *msg.DisappearAfter)
+				}
+
+			case domain.PinMsg, domain.UnpinMsg:
+				c.setMsgPinnedAt(msg)
+
+			case domain.DeliveredUpToMsg, domain.ReadUpToMsg:
+				if err := c.repo.UpdateMsgsUpTo(msg); err != nil {
+					slog.Error(err.Error())
+				}
+
+			case domain.SyncDoneMsg:
+				c.synced.Store(true)
+				for senderID, upTo := range undelivered {
+					if err := c.setMsgsDeliveredUpTo(senderID, upTo); err != nil {
+						slog.Error(err.Error())
+					}
+				}
+				clear(undelivered)
+				if err := c.repo.SaveSyncCursor(msg.Cursor); err != nil {
+					slog.Error(err.Error())
+				}
 
-			case domain.OfflineMsg:
-				c.setUsrOnlineStatus(msg, false)
+			case domain.ErrorMsg:
+				slog.Error("server rejected the message", "id", msg.ID, "errors", msg.Errors)
 
 			case domain.SyncConvosMsg:
 				convos, code, err := c.getConversations()
@@ -196,6 +292,7 @@ func (c *Client) handleReceivedMsgs(shtdwnCtx context.Context) {
 					c.saveConvosAndWriteToChan(convos)
 				}
 			}
+			c.markLiveEvent(msg)
 
 		case <-shtdwnCtx.Done():
 			return
@@ -222,23 +319,54 @@ func (c *Client) setMsgAsDelivered(msgID, receiverID string) error {
 	return nil
 }
 
+// setMsgsDeliveredUpTo confirms the delivery of every msg from the sender sent up to upTo with a single watermark
+func (c *Client) setMsgsDeliveredUpTo(senderID string, upTo time.Time) error {
+	msg := &domain.Message{
+		SenderID:    c.CurrentUsr.ID,
+		ReceiverID:  senderID,
+		DeliveredAt: ptr(time.Now()),
+		Operation:   domain.DeliveredUpToMsg,
+		UpTo:        &upTo,
+	}
+	c.sentMsgs.msgs <- msg
+	// if msg is not sent
+	if !<-c.sentMsgs.done {
+		return ErrMsgNotSent
+	}
+	return c.repo.UpdateMsgsUpTo(msg)
+}
+
+// UnreadMsgsCount returns the count of the msgs received by the current user & not read yet, 0 if not logged in
+func (c *Client) UnreadMsgsCount() int64 {
+	if c.CurrentUsr == nil {
+		return 0
+	}
+	count, err := c.repo.GetUnreadMsgsCount(c.CurrentUsr.ID)
+	if err != nil {
+		slog.Error(err.Error())
+	}
+	return count
+}
+
+// SetMsgAsRead marks msg & every older msg from its sender as read, with a single ReadUpToMsg watermark
 func (c *Client) SetMsgAsRead(msg *domain.Message) error {
 	msgToSend := &domain.Message{
-		ID:         msg.ID,
-		SenderID:   c.CurrentUsr.ID,
-		ReceiverID: msg.SenderID, // confirm that message is read
-		ReadAt:     msg.ReadAt,
-		Operation:  domain.ReadMsg,
+		SenderID:    c.CurrentUsr.ID,
+		ReceiverID:  msg.SenderID, // confirm that message is read
+		DeliveredAt: msg.ReadAt,   // only set on the msgs not yet delivered
+		ReadAt:      msg.ReadAt,
+		Operation:   domain.ReadUpToMsg,
+		UpTo:        msg.SentAt,
 	}
 	// this may block, in theory, depends on the connection
 	c.sentMsgs.msgs <- msgToSend
 	if !<-c.sentMsgs.done {
-		if err := c.repo.UpdateMsg(msg); err != nil {
+		if err := c.repo.UpdateMsgsUpTo(msgToSend); err != nil {
 			return err
 		}
 		return ErrMsgNotSent
 	}
-	if err := c.repo.UpdateMsg(msg); err != nil {
+	if err := c.repo.UpdateMsgsUpTo(msgToSend); err != nil {
 		return err
 	}
 	return nil
@@ -254,6 +382,11 @@ func (c *Client) DeleteMsgForMe(msgId string) error {
 }
 
 func (c *Client) DeleteMsgForEveryone(msg *domain.Message) error {
+	stored, err := c.repo.GetMsgByID(msg.ID)
+	if err != nil {
+		return err
+	}
+	c.undoOnRejection(msg.ID, func() error { return c.restoreMsg(stored) })
 	// this may block, in theory, depends on the connection
 	c.sentMsgs.msgs <- msg
 	if <-c.sentMsgs.done {
@@ -278,20 +411,33 @@ func (c *Client) DeleteForMeAllMsgsForConversation(senderId, receiverId string)
 
 // Helpers & Stuff -----------------------------------------------------------------------------------------------------
 
-func (c *Client) setUsrOnlineStatus(msg *domain.Message, online bool) {
-	convos := c.Conversations.Get()
-	lastOnline := msg.SentAt
-	if online {
-		lastOnline = nil
+// isReceiverOf reports whether the sender of the DeliveredMsg or ReadMsg is the receiver of the stored msg it marks
+func (c *Client) isReceiverOf(msg *domain.Message) bool {
+	m, err := c.repo.GetMsgByID(msg.ID)
+	return err == nil && m.ReceiverID == msg.SenderID
+}
+
+// setUsrProfile updates the conversation with the sender, its profile is shown under its name
+func (c *Client) setUsrProfile(msg *domain.Message) {
+	if msg.Profile == nil {
+		return
 	}
+	convos := c.Conversations.Get()
 	for i := range convos {
-		// offline/online user is in the convos
 		if convos[i].UserID == msg.SenderID {
-			convos[i].LastOnline = lastOnline
+			convos[i].SetProfile(*msg.Profile)
+			c.saveConvosAndWriteToChan(convos)
 			break
 		}
 	}
-	c.Conversations.Write(convos)
+}
+
+// markLiveEvent notes an event relayed live, its cursor is not persisted, as the hub relays the events of different
+// senders out of cursor order, one with a lower cursor may still be on its way, see resyncPeriodically
+func (c *Client) markLiveEvent(msg *domain.Message) {
+	if msg.Cursor != 0 && c.synced.Load() {
+		c.liveEvents.Store(true)
+	}
 }
 
 func ptr[T any](v T) *T {
@@ -302,6 +448,30 @@ func (c *Client) isValidReadUpdate(msg *domain.Message) bool {
 	return msg.SenderID != c.CurrentUsr.ID && msg.DeliveredAt != nil && msg.ReadAt == nil
 }
 
+// refreshConvosWith updates the latest msgs of the conversations, fetching them from the server if there is none with
+// usrID locally, the server has made one
+func (c *Client) refreshConvosWith(usrID string) {
+	exists, err := c.conversationExistsWithReceiver(usrID)
+	if err != nil {
+		slog.Error(err.Error())
+		return
+	}
+	if exists {
+		c.getPopulateSaveConvosAndWriteToChan()
+		return
+	}
+	convos, code, err := c.getConversations()
+	if err != nil {
+		slog.Error(err.Error())
+		return
+	}
+	if code == http.StatusUnauthorized {
+		c.LoginState.Write(false) // user will be redirected to log-in by tui
+		return
+	}
+	c.saveConvosAndWriteToChan(convos)
+}
+
 // once there is a message, we also update the conversations as the latest msg will also need update and save to db
 func (c *Client) getPopulateSaveConvosAndWriteToChan() {
 	convos := c.Conversations.Get()
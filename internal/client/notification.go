@@ -0,0 +1,112 @@
+package client
+
+import (
+	"cmp"
+	"context"
+	"github.com/MuhamedUsman/letschat/internal/client/notify"
+	"github.com/MuhamedUsman/letschat/internal/domain"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// notificationBodyLen is the max runes of the msg body shown in a notification
+const notificationBodyLen = 120
+
+// SetFocusedConvo is reported by the tui, usrID is of the conversation in front of the user, empty if none or the
+// terminal is out of focus, the msgs received on it are not notified of
+func (c *Client) SetFocusedConvo(usrID string) {
+	c.focusedConvo.Store(usrID)
+}
+
+// MuteConvo mutes the conversation with usrID for the current user, or unmutes it if not mute, the msgs received on
+// a muted conversation are not notified of
+func (c *Client) MuteConvo(usrID string, mute bool) error {
+	var err error
+	if mute {
+		err = c.repo.SaveMutedConvo(usrID)
+	} else {
+		err = c.repo.DeleteMutedConvo(usrID)
+	}
+	if err != nil {
+		return err
+	}
+	c.mutedMu.Lock()
+	defer c.mutedMu.Unlock()
+	if mute {
+		c.muted[usrID] = true
+	} else {
+		delete(c.muted, usrID)
+	}
+	return nil
+}
+
+// Muted reports whether the conversation with usrID is muted by the current user
+func (c *Client) Muted(usrID string) bool {
+	c.mutedMu.RLock()
+	defer c.mutedMu.RUnlock()
+	return c.muted[usrID]
+}
+
+// Helpers & Stuff -----------------------------------------------------------------------------------------------------
+
+// notifyReceivedMsgs notifies the user of the msgs received, see shouldNotify, by each of the Notifiers in the
+// background, as the broadcast waits on each of its subscribers, must be run in a separate long-running goroutine
+func (c *Client) notifyReceivedMsgs(shtdwnCtx context.Context) {
+	token, ch := c.RecvMsgs.Subscribe()
+	defer c.RecvMsgs.Unsubscribe(token)
+	for {
+		select {
+		case msg := <-ch:
+			if !c.shouldNotify(msg) {
+				continue
+			}
+			n := c.notification(msg)
+			c.BT.Run(func(shtdwnCtx context.Context) {
+				ctx, cancel := context.WithTimeout(shtdwnCtx, 5*time.Second)
+				defer cancel()
+				for _, notifier := range c.Notifiers {
+					if err := notifier.Notify(ctx, n); err != nil {
+						slog.Error(err.Error())
+					}
+				}
+			})
+		case <-shtdwnCtx.Done():
+			return
+		}
+	}
+}
+
+// shouldNotify reports whether the msg is a new one from another user, outside the focused conversation, the ones
+// replayed while syncing are skipped, those are already in the unread counts
+func (c *Client) shouldNotify(msg *domain.Message) bool {
+	if len(c.Notifiers) == 0 || msg.Operation != domain.CreateMsg || !c.synced.Load() {
+		return false
+	}
+	if c.CurrentUsr == nil || msg.SenderID == c.CurrentUsr.ID || msg.SenderID == c.focusedConvo.Load().(string) {
+		return false
+	}
+	if c.Muted(msg.SenderID) || c.QuietHours.Contains(time.Now()) {
+		return false
+	}
+	c.presenceMu.Lock()
+	dnd := c.chosenPresence == domain.DoNotDisturb
+	c.presenceMu.Unlock()
+	return !dnd
+}
+
+// notification of the msg, titled with the name of its sender
+func (c *Client) notification(msg *domain.Message) notify.Notification {
+	var name string
+	for _, convo := range c.Conversations.Get() {
+		if convo.UserID == msg.SenderID {
+			name = convo.Username
+			break
+		}
+	}
+	body := strings.Join(strings.Fields(msg.Body), " ")
+	if r := []rune(body); len(r) > notificationBodyLen {
+		body = string(r[:notificationBodyLen-1]) + "…"
+	}
+	return notify.Notification{Title: cmp.Or(name, "New message") + " · Letschat", Body: body}
+}
@@ -0,0 +1,137 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// names of the notifiers, see New
+const (
+	Bell       = "bell"        // rings the terminal bell, tmux flags the window of the bell
+	OSC9       = "osc9"        // the OSC 9 desktop notification, e.g. iTerm2, WezTerm & Windows Terminal
+	OSC777     = "osc777"      // the OSC 777 desktop notification, e.g. kitty, foot & the VTE based terminals
+	NotifySend = "notify-send" // the freedesktop notification daemon, linux only
+	Command    = "cmd"         // the command of the user, see New
+)
+
+// Notification is what the user is notified of, e.g. the sender & the body of a msg received
+type Notification struct {
+	Title, Body string
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// New returns the notifiers with the comma separated names, the escape sequences are written to w, the Command one runs
+// cmd in the shell with the notification in the LETSCHAT_TITLE & LETSCHAT_BODY environment variables
+func New(names, cmd string, w io.Writer) ([]Notifier, error) {
+	tw := &termWriter{w: w, tmux: os.Getenv("TMUX") != ""}
+	var notifiers []Notifier
+	for _, name := range strings.Split(names, ",") {
+		switch name = strings.TrimSpace(name); name {
+		case "":
+		case Bell:
+			notifiers = append(notifiers, bell{tw})
+		case OSC9:
+			notifiers = append(notifiers, osc9{tw})
+		case OSC777:
+			notifiers = append(notifiers, osc777{tw})
+		case NotifySend:
+			if runtime.GOOS != "linux" {
+				return nil, errors.New("notify-send is only supported on linux")
+			}
+			notifiers = append(notifiers, notifySend{})
+		case Command:
+			if strings.TrimSpace(cmd) == "" {
+				return nil, errors.New("the command to notify with is not provided")
+			}
+			notifiers = append(notifiers, command{cmd})
+		default:
+			return nil, fmt.Errorf("unknown notifier %q", name)
+		}
+	}
+	return notifiers, nil
+}
+
+type bell struct{ w *termWriter }
+
+func (b bell) Notify(context.Context, Notification) error {
+	return b.w.write("\a", false)
+}
+
+type osc9 struct{ w *termWriter }
+
+func (o osc9) Notify(_ context.Context, n Notification) error {
+	return o.w.write(fmt.Sprintf("\x1b]9;%v: %v\a", sanitize(n.Title), sanitize(n.Body)), true)
+}
+
+type osc777 struct{ w *termWriter }
+
+func (o osc777) Notify(_ context.Context, n Notification) error {
+	// the fields are separated by semicolons
+	title := strings.ReplaceAll(sanitize(n.Title), ";", ",")
+	return o.w.write(fmt.Sprintf("\x1b]777;notify;%v;%v\a", title, sanitize(n.Body)), true)
+}
+
+type notifySend struct{}
+
+func (notifySend) Notify(ctx context.Context, n Notification) error {
+	// a title or body starting with a dash, e.g. "-u critical", is not taken as an option
+	return exec.CommandContext(ctx, "notify-send", "--app-name=Letschat", "--", n.Title, n.Body).Run()
+}
+
+type command struct{ cmd string }
+
+func (c command) Notify(ctx context.Context, n Notification) error {
+	cmd := exec.CommandContext(ctx, "sh", "-c", c.cmd)
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", c.cmd)
+	}
+	// passed in the environment, never interpolated into the command
+	cmd.Env = append(os.Environ(), "LETSCHAT_TITLE="+n.Title, "LETSCHAT_BODY="+n.Body)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("notify command: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// Helpers & Stuff -----------------------------------------------------------------------------------------------------
+
+// termWriter writes the escape sequences to the terminal, one at a time
+type termWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+	// inside tmux, which only passes the escape sequences it does not know of through to the terminal if wrapped
+	tmux bool
+}
+
+func (t *termWriter) write(seq string, passthrough bool) error {
+	if t.tmux && passthrough {
+		seq = "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, err := io.WriteString(t.w, seq)
+	return err
+}
+
+// sanitize drops the control characters, any of them would end the escape sequence early
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' {
+			return ' '
+		}
+		if r < 0x20 || r == 0x7f || (r >= 0x80 && r < 0xa0) {
+			return -1
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,57 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Schedule is a daily span of the local time, e.g. the quiet hours in which the notifications are held back, it spans
+// midnight if it ends before it starts
+type Schedule struct {
+	// since midnight
+	from, to time.Duration
+}
+
+// ParseSchedule parses the span in the "15:04-15:04" form, e.g. "22:00-07:30", nil if s is empty
+func ParseSchedule(s string) (*Schedule, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	from, to, ok := strings.Cut(s, "-")
+	if !ok {
+		return nil, fmt.Errorf("schedule %q must be in the form 22:00-07:30", s)
+	}
+	var sch Schedule
+	var err error
+	if sch.from, err = parseClock(from); err != nil {
+		return nil, err
+	}
+	if sch.to, err = parseClock(to); err != nil {
+		return nil, err
+	}
+	return &sch, nil
+}
+
+// Contains reports whether t is within the span, always false for a nil Schedule
+func (s *Schedule) Contains(t time.Time) bool {
+	if s == nil || s.from == s.to {
+		return false
+	}
+	t = t.Local()
+	clock := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	if s.from < s.to {
+		return clock >= s.from && clock < s.to
+	}
+	return clock >= s.from || clock < s.to
+}
+
+// Helpers & Stuff -----------------------------------------------------------------------------------------------------
+
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid clock time %q, must be like 07:30", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
@@ -17,7 +17,7 @@ func (c *Client) ResendOtp(email string) error {
 		slog.Error(err.Error())
 		return err
 	}
-	resp, err := http.DefaultClient.Post(generateOTP, "application/json", bytes.NewBuffer(jsonBytes))
+	resp, err := c.httpClient.Post(c.ep.generateOTP, "application/json", bytes.NewBuffer(jsonBytes))
 	if err != nil {
 		slog.Error(err.Error())
 		return err
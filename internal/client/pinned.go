@@ -0,0 +1,77 @@
+package client
+
+import (
+	"github.com/MuhamedUsman/letschat/internal/domain"
+	"log/slog"
+	"time"
+)
+
+// PinMsg pins the msg for both users of its conversation, or unpins it if not pin
+func (c *Client) PinMsg(msg *domain.Message, pin bool) error {
+	partnerID := msg.SenderID
+	if partnerID == c.CurrentUsr.ID {
+		partnerID = msg.ReceiverID
+	}
+	pinMsg := &domain.Message{
+		ID:         msg.ID,
+		SenderID:   c.CurrentUsr.ID,
+		ReceiverID: partnerID,
+		SentAt:     ptr(time.Now()),
+		Operation:  domain.UnpinMsg,
+	}
+	if pin {
+		pinMsg.Operation = domain.PinMsg
+		pinMsg.PinnedAt = pinMsg.SentAt
+	}
+	prevPinnedAt := msg.PinnedAt
+	if stored, err := c.repo.GetMsgByID(msg.ID); err == nil {
+		prevPinnedAt = stored.PinnedAt
+	}
+	c.undoOnRejection(msg.ID, func() error { return c.repo.SetMsgPinnedAt(msg.ID, prevPinnedAt) })
+	c.sentMsgs.msgs <- pinMsg
+	if !<-c.sentMsgs.done {
+		return ErrMsgNotSent
+	}
+	return c.repo.SetMsgPinnedAt(msg.ID, pinMsg.PinnedAt)
+}
+
+// StarMsg stars the msg for the current user only, or unstars it if not star, nothing is sent to the server
+func (c *Client) StarMsg(msgID string, star bool) error {
+	var starredAt *time.Time
+	if star {
+		starredAt = ptr(time.Now())
+	}
+	return c.repo.SetMsgStarredAt(msgID, starredAt)
+}
+
+// GetPinnedMessages returns the pinned msgs of the conversation with usrID, the latest pinned first
+func (c *Client) GetPinnedMessages(usrID string) ([]*domain.Message, error) {
+	return c.repo.GetPinnedMsgs(usrID)
+}
+
+// GetStarredMessages returns a page of the starred msgs of every conversation, the latest starred first
+func (c *Client) GetStarredMessages(page int) ([]*domain.MessageHit, *domain.Metadata, error) {
+	f := domain.Filter{
+		Page:     page,
+		PageSize: 50,
+	}
+	return c.repo.GetStarredMsgsAsPage(f)
+}
+
+// Helpers & Stuff -----------------------------------------------------------------------------------------------------
+
+// setMsgPinnedAt applies the received domain.PinMsg or domain.UnpinMsg, only to a msg of the conversation with its
+// sender, the msg may also be deleted here already
+func (c *Client) setMsgPinnedAt(msg *domain.Message) {
+	m, err := c.repo.GetMsgByID(msg.ID)
+	if err != nil || (m.SenderID != msg.SenderID && m.ReceiverID != msg.SenderID) {
+		return
+	}
+	var pinnedAt *time.Time
+	if msg.Operation == domain.PinMsg {
+		pinnedAt = msg.PinnedAt
+	}
+	if err = c.repo.SetMsgPinnedAt(msg.ID, pinnedAt); err != nil {
+		slog.Error(err.Error())
+	}
+}
@@ -0,0 +1,71 @@
+package client
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// the name of the theme chosen on this device is kept in themeFile, the custom themes are the files in themesDir, both
+// in the FilesDir, as the theme is of the terminal, not of the user logged in
+const (
+	themeFile = "theme"
+	themesDir = "themes"
+)
+
+// RawMsgs reports whether the current user has the msg bodies shown as typed, instead of rendered as markdown
+func (c *Client) RawMsgs() bool {
+	return c.rawMsgs.Load()
+}
+
+// SetRawMsgs persists the raw msgs preference of the current user, see RawMsgs
+func (c *Client) SetRawMsgs(raw bool) error {
+	if err := c.repo.SaveRawMsgs(c.CurrentUsr.ID, raw); err != nil {
+		return err
+	}
+	c.rawMsgs.Store(raw)
+	return nil
+}
+
+// Theme returns the name of the theme chosen on this device, empty if never chosen
+func (c *Client) Theme() string {
+	b, err := os.ReadFile(filepath.Join(c.FilesDir, themeFile))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		slog.Error(err.Error())
+	}
+	return strings.TrimSpace(string(b))
+}
+
+// SetTheme persists the name of the theme chosen on this device, see Theme
+func (c *Client) SetTheme(name string) error {
+	return os.WriteFile(filepath.Join(c.FilesDir, themeFile), []byte(name), 0o600)
+}
+
+// ThemesDir is the directory of the custom theme files of the tui
+func (c *Client) ThemesDir() string {
+	return filepath.Join(c.FilesDir, themesDir)
+}
+
+// Helpers & Stuff -----------------------------------------------------------------------------------------------------
+
+// loadPreferences loads the preferences of the current user, falling back to the defaults
+func (c *Client) loadPreferences() {
+	raw, err := c.repo.GetRawMsgs(c.CurrentUsr.ID)
+	if err != nil {
+		slog.Error(err.Error())
+	}
+	c.rawMsgs.Store(raw)
+	ids, err := c.repo.GetMutedConvos()
+	if err != nil {
+		slog.Error(err.Error())
+	}
+	muted := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		muted[id] = true
+	}
+	c.mutedMu.Lock()
+	c.muted = muted
+	c.mutedMu.Unlock()
+}
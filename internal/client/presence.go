@@ -0,0 +1,121 @@
+package client
+
+import (
+	"github.com/MuhamedUsman/letschat/internal/domain"
+	"slices"
+	"time"
+)
+
+// SetPresence sets the presence of the current user, as seen by the others, Offline cannot be set.
+// if not connected, it is sent once connected
+func (c *Client) SetPresence(status domain.PresenceStatus) error {
+	c.presenceMu.Lock()
+	c.chosenPresence = status
+	c.presenceMu.Unlock()
+	return c.writePresence()
+}
+
+// SetIdle reports the user as Away while idle, back to the chosen presence otherwise
+func (c *Client) SetIdle(idle bool) error {
+	c.presenceMu.Lock()
+	if c.idle == idle {
+		c.presenceMu.Unlock()
+		return nil
+	}
+	c.idle = idle
+	c.presenceMu.Unlock()
+	return c.writePresence()
+}
+
+// WatchPresences adds the presences of the discovered users to the ones received, next to the conversations
+func (c *Client) WatchPresences(usrIDs []string) error {
+	c.presenceMu.Lock()
+	c.discoveredUsrIDs = usrIDs
+	c.presenceMu.Unlock()
+	return c.subscribePresence()
+}
+
+// Presence returns the last known presence of the user, the ones not received yet are offline
+func (c *Client) Presence(usrID string) domain.Presence {
+	c.presenceMu.Lock()
+	defer c.presenceMu.Unlock()
+	if p, ok := c.presences[usrID]; ok {
+		return p
+	}
+	return domain.Presence{UserID: usrID}
+}
+
+// subscribePresence replaces the presence subscription of the ws conn with the users of the conversations & the
+// discovered ones, the server answers with their current presence, a PresenceMsg each
+func (c *Client) subscribePresence() error {
+	conn := c.wsConn.Load()
+	if conn == nil || c.WsConnState.Get() != Connected {
+		return nil
+	}
+	c.presenceMu.Lock()
+	ids := slices.Clone(c.discoveredUsrIDs)
+	c.presenceMu.Unlock()
+	for _, convo := range c.Conversations.Get() {
+		ids = append(ids, convo.UserID)
+	}
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+	if len(ids) > domain.MaxPresenceSubscriptions {
+		ids = ids[:domain.MaxPresenceSubscriptions]
+	}
+	sub := domain.PresenceSubscription{
+		Operation: domain.SubscribePresenceMsg,
+		UserIDs:   ids,
+	}
+	return writeWithTimeout(conn, 2*time.Second, sub)
+}
+
+// Helpers & Stuff -----------------------------------------------------------------------------------------------------
+
+// writePresence writes the effective presence to the ws conn, the server resets it to Online on every new conn,
+// so it is also written once connected, see wsConnectAndListenForMessages
+func (c *Client) writePresence() error {
+	conn := c.wsConn.Load()
+	if conn == nil || c.WsConnState.Get() != Connected {
+		return nil
+	}
+	status := c.effectivePresence()
+	msg := &domain.Message{
+		SenderID:  c.CurrentUsr.ID,
+		SentAt:    ptr(time.Now()),
+		Operation: domain.PresenceMsg,
+		Presence:  &status,
+	}
+	return writeWithTimeout(conn, 2*time.Second, msg)
+}
+
+func (c *Client) effectivePresence() domain.PresenceStatus {
+	c.presenceMu.Lock()
+	defer c.presenceMu.Unlock()
+	if c.idle && c.chosenPresence != domain.DoNotDisturb {
+		return domain.Away
+	}
+	return c.chosenPresence
+}
+
+func (c *Client) setUsrPresence(msg *domain.Message) {
+	if msg.Presence == nil {
+		return
+	}
+	var lastOnline *time.Time
+	if *msg.Presence == domain.Offline {
+		lastOnline = msg.SentAt
+	}
+	c.presenceMu.Lock()
+	c.presences[msg.SenderID] = domain.Presence{UserID: msg.SenderID, Status: *msg.Presence, LastOnline: lastOnline}
+	c.presenceMu.Unlock()
+	convos := c.Conversations.Get()
+	for i := range convos {
+		if convos[i].UserID == msg.SenderID {
+			convos[i].Presence = *msg.Presence
+			convos[i].LastOnline = lastOnline
+			c.Conversations.Write(convos)
+			break
+		}
+	}
+}
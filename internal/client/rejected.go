@@ -0,0 +1,74 @@
+package client
+
+import (
+	"github.com/MuhamedUsman/letschat/internal/domain"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// rejectionWindow is how long an op applied locally is undoable, the server rejects an op while processing its frame,
+// so its domain.ErrorMsg is back well within it
+const rejectionWindow = 30 * time.Second
+
+// undoableOps keep how to undo the ops applied locally before the server authorizes them, by the id of their msg
+type undoableOps struct {
+	mu   sync.Mutex
+	undo map[string]undoableOp
+}
+
+type undoableOp struct {
+	fn func() error
+	at time.Time
+}
+
+// undoOnRejection registers fn undoing the op on the msg with id, if the server rejects it
+func (c *Client) undoOnRejection(id string, fn func() error) {
+	c.undoable.mu.Lock()
+	defer c.undoable.mu.Unlock()
+	if c.undoable.undo == nil {
+		c.undoable.undo = make(map[string]undoableOp)
+	}
+	for k, op := range c.undoable.undo {
+		if time.Since(op.at) > rejectionWindow {
+			delete(c.undoable.undo, k)
+		}
+	}
+	c.undoable.undo[id] = undoableOp{fn: fn, at: time.Now()}
+}
+
+// undoRejected undoes the local op rejected by the ErrorMsg, if any, before the msg is broadcast on RecvMsgs, so the
+// tui reloads the restored state
+func (c *Client) undoRejected(msg *domain.Message) {
+	c.undoable.mu.Lock()
+	op, ok := c.undoable.undo[msg.ID]
+	delete(c.undoable.undo, msg.ID)
+	c.undoable.mu.Unlock()
+	if !ok || time.Since(op.at) > rejectionWindow {
+		return
+	}
+	if err := op.fn(); err != nil {
+		slog.Error("undoing the rejected message operation", "id", msg.ID, "err", err)
+	}
+}
+
+// Helpers & Stuff -----------------------------------------------------------------------------------------------------
+
+// restoreMsg saves stored back, along with its pin & its star
+func (c *Client) restoreMsg(stored *domain.Message) error {
+	if err := c.repo.SaveMsg(stored); err != nil {
+		return err
+	}
+	if stored.PinnedAt != nil {
+		if err := c.repo.SetMsgPinnedAt(stored.ID, stored.PinnedAt); err != nil {
+			return err
+		}
+	}
+	if stored.StarredAt != nil {
+		if err := c.repo.SetMsgStarredAt(stored.ID, stored.StarredAt); err != nil {
+			return err
+		}
+	}
+	c.getPopulateSaveConvosAndWriteToChan()
+	return nil
+}
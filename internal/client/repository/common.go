@@ -12,3 +12,16 @@ func parseTime(t *string) (*time.Time, error) {
 	}
 	return &ti, err
 }
+
+// scannedTime converts a scanned DATETIME column, the driver yields time.Time for the values it can parse & a string
+// otherwise
+func scannedTime(v any) *time.Time {
+	switch t := v.(type) {
+	case time.Time:
+		return &t
+	case string:
+		ti, _ := parseTime(&t)
+		return ti
+	}
+	return nil
+}
@@ -17,8 +17,10 @@ func NewLocalConversationRepository(db *DB) LocalConversationRepository {
 
 func (r LocalConversationRepository) SaveConversations(convos ...*domain.Conversation) error {
 	query := `
-		INSERT INTO conversation(user_id, username, user_email, last_online) 
-		VALUES (:user_id, :username, :user_email, :last_online)
+		INSERT INTO conversation(user_id, username, user_handle, user_email, last_online, 
+		                         status_text, status_emoji, status_expires_at, bio, disappear_after, user_is_bot) 
+		VALUES (:user_id, :username, :user_handle, :user_email, :last_online, 
+		        :status_text, :status_emoji, :status_expires_at, :bio, :disappear_after, :user_is_bot)
 	`
 	for _, convo := range convos {
 		_, err := r.db.NamedExec(query, convo)
@@ -39,13 +41,17 @@ func (r LocalConversationRepository) DeleteAllConversations() error {
 
 func (r LocalConversationRepository) GetConversationByUserID(id string) (*domain.Conversation, error) {
 	query := `
-		SELECT user_id, username, user_email, last_online
+		SELECT user_id, username, user_handle, user_email, last_online, status_text, status_emoji, status_expires_at, bio,
+		       disappear_after, user_is_bot
 		FROM conversation
 		WHERE user_id = :user_id  
 	`
 	var c domain.Conversation
-	var LastOnline any
-	args := []any{&c.UserID, &c.Username, &c.UserEmail, &LastOnline}
+	var LastOnline, statusExpiresAt any
+	args := []any{
+		&c.UserID, &c.Username, &c.UserHandle, &c.UserEmail, &LastOnline,
+		&c.StatusText, &c.StatusEmoji, &statusExpiresAt, &c.Bio, &c.DisappearAfter, &c.UserIsBot,
+	}
 	if err := r.db.QueryRow(query, id).Scan(args...); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, domain.ErrRecordNotFound
@@ -57,19 +63,25 @@ func (r LocalConversationRepository) GetConversationByUserID(id string) (*domain
 			c.LastOnline, _ = parseTime(&timeStr)
 		}
 	}
+	c.StatusExpiresAt = scannedTime(statusExpiresAt)
 	return &c, nil
 }
 
 func (r LocalConversationRepository) GetConversations() ([]*domain.Conversation, error) {
 	query := `
-		SELECT user_id, username, user_email, last_online FROM conversation
+		SELECT user_id, username, user_handle, user_email, last_online, status_text, status_emoji, status_expires_at, bio,
+		       disappear_after, user_is_bot
+		FROM conversation
 	`
 	rows, _ := r.db.Queryx(query)
 	convos := make([]*domain.Conversation, 0)
 	for rows.Next() {
 		var c domain.Conversation
-		var LastOnline any
-		args := []any{&c.UserID, &c.Username, &c.UserEmail, &LastOnline}
+		var LastOnline, statusExpiresAt any
+		args := []any{
+			&c.UserID, &c.Username, &c.UserHandle, &c.UserEmail, &LastOnline,
+			&c.StatusText, &c.StatusEmoji, &statusExpiresAt, &c.Bio, &c.DisappearAfter, &c.UserIsBot,
+		}
 		if err := rows.Scan(args...); err != nil {
 			return nil, err
 		}
@@ -78,6 +90,7 @@ func (r LocalConversationRepository) GetConversations() ([]*domain.Conversation,
 				c.LastOnline = &timeStr
 			}
 		}
+		c.StatusExpiresAt = scannedTime(statusExpiresAt)
 
 		convos = append(convos, &c)
 	}
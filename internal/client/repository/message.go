@@ -4,8 +4,12 @@ import (
 	"database/sql"
 	"errors"
 	"github.com/MuhamedUsman/letschat/internal/domain"
+	"strings"
+	"time"
 )
 
+var ErrSearchUnavailable = errors.New("searching the messages is unavailable, sqlite is built without FTS5")
+
 type LocalMessageRepository struct {
 	db *DB
 }
@@ -59,15 +63,34 @@ func (r LocalMessageRepository) getUnreadMsgsCountForConvo(convoId string) (int6
 	return msgCount, nil
 }
 
+// GetUnreadMsgsCount returns the count of the msgs received by the user with usrID & not read yet, of all the convos
+func (r LocalMessageRepository) GetUnreadMsgsCount(usrID string) (int64, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM message
+		WHERE receiver_id = $1 AND message.read_at IS NULL
+	`
+	var msgCount int64
+	if err := r.db.QueryRow(query, usrID).Scan(&msgCount); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, err
+	}
+	return msgCount, nil
+}
+
 func (r LocalMessageRepository) GetMsgByID(id string) (*domain.Message, error) {
 	query := `
-		SELECT id, sender_id, receiver_id, body, sent_at, delivered_at, read_at, version
+		SELECT id, sender_id, receiver_id, body, sent_at, delivered_at, read_at, version, expires_at, pinned_at,
+		       starred_at
 		FROM message
 		WHERE id = $1
 	`
 	var msg domain.Message
 	var SentAt, DeliveredAt, ReadAt *string
-	args := []any{&msg.ID, &msg.SenderID, &msg.ReceiverID, &msg.Body, &SentAt, &DeliveredAt, &ReadAt, &msg.Version}
+	var expiresAt, pinnedAt, starredAt any
+	args := []any{
+		&msg.ID, &msg.SenderID, &msg.ReceiverID, &msg.Body, &SentAt, &DeliveredAt, &ReadAt, &msg.Version, &expiresAt,
+		&pinnedAt, &starredAt,
+	}
 	if err := r.db.QueryRow(query, id).Scan(args...); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, domain.ErrRecordNotFound
@@ -77,13 +100,16 @@ func (r LocalMessageRepository) GetMsgByID(id string) (*domain.Message, error) {
 	msg.SentAt, _ = parseTime(SentAt)
 	msg.DeliveredAt, _ = parseTime(DeliveredAt)
 	msg.ReadAt, _ = parseTime(ReadAt)
+	msg.ExpiresAt = scannedTime(expiresAt)
+	msg.PinnedAt = scannedTime(pinnedAt)
+	msg.StarredAt = scannedTime(starredAt)
 	return &msg, nil
 }
 
 func (r LocalMessageRepository) SaveMsg(msg *domain.Message) error {
 	query := `
-		INSERT INTO message (id, sender_id, receiver_id, body, sent_at, delivered_at, read_at)
-		VALUES (:id, :sender_id, :receiver_id, :body, :sent_at, :delivered_at, :read_at)
+		INSERT INTO message (id, sender_id, receiver_id, body, sent_at, delivered_at, read_at, expires_at)
+		VALUES (:id, :sender_id, :receiver_id, :body, :sent_at, :delivered_at, :read_at, :expires_at)
 	`
 	_, err := r.db.NamedExec(query, msg)
 	return err
@@ -108,6 +134,24 @@ func (r LocalMessageRepository) UpdateMsg(msg *domain.Message) error {
 	return nil
 }
 
+// UpdateMsgsUpTo applies the watermark msg to every msg of the conversation sent up to msg.UpTo in a single UPDATE,
+// the fields already set are kept. Timestamps are compared in UTC to the millisecond, whatever their layout & zone is,
+// the server keeps them to the microsecond, so a msg sent in the same second after the watermark is not affected
+func (r LocalMessageRepository) UpdateMsgsUpTo(msg *domain.Message) error {
+	query := `
+		UPDATE message
+		SET delivered_at = COALESCE(delivered_at, $3),
+		    read_at = COALESCE(read_at, $4),
+		    version = version + 1
+		WHERE sender_id = $1 AND receiver_id = $2
+		AND strftime('%Y-%m-%d %H:%M:%f', sent_at) <= strftime('%Y-%m-%d %H:%M:%f', $5)
+		AND ((delivered_at IS NULL AND $3 IS NOT NULL) OR (read_at IS NULL AND $4 IS NOT NULL))
+	`
+	author, recipient := msg.Parties()
+	_, err := r.db.Exec(query, author, recipient, msg.DeliveredAt, msg.ReadAt, msg.UpTo)
+	return err
+}
+
 func (r LocalMessageRepository) DeleteMsg(id string) error {
 	query := `
 		DELETE FROM message WHERE id = $1
@@ -116,6 +160,31 @@ func (r LocalMessageRepository) DeleteMsg(id string) error {
 	return err
 }
 
+// DeleteExpiredMsgs deletes the msgs whose expires_at is passed by until, returns them with their parties only,
+// compared to the millisecond as in UpdateMsgsUpTo
+func (r LocalMessageRepository) DeleteExpiredMsgs(until time.Time) ([]*domain.Message, error) {
+	query := `
+		DELETE FROM message
+		WHERE expires_at IS NOT NULL
+		AND strftime('%Y-%m-%d %H:%M:%f', expires_at) <= strftime('%Y-%m-%d %H:%M:%f', $1)
+		RETURNING id, sender_id, receiver_id
+	`
+	rows, err := r.db.Query(query, until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	msgs := make([]*domain.Message, 0)
+	for rows.Next() {
+		var m domain.Message
+		if err = rows.Scan(&m.ID, &m.SenderID, &m.ReceiverID); err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, &m)
+	}
+	return msgs, rows.Err()
+}
+
 func (r LocalMessageRepository) DeleteAllForSenderAndReceiver(senderId, receiverId string) error {
 	query := `
 		DELETE FROM message 
@@ -130,7 +199,8 @@ func (r LocalMessageRepository) GetMsgsAsPage(
 	fil domain.Filter,
 ) ([]*domain.Message, *domain.Metadata, error) {
 	query := `
-		SELECT COUNT(*) OVER(), id, sender_id, receiver_id, body, sent_at, delivered_at, read_at, version
+		SELECT COUNT(*) OVER(), id, sender_id, receiver_id, body, sent_at, delivered_at, read_at, version, expires_at,
+		       pinned_at, starred_at
 		FROM message
 		WHERE sender_id = $1 OR receiver_id = $1
 		ORDER BY sent_at DESC
@@ -144,15 +214,197 @@ func (r LocalMessageRepository) GetMsgsAsPage(
 	for rows.Next() {
 		var m domain.Message
 		var SentAt, DeliveredAt, ReadAt *string
-		args = []any{&TotalRows, &m.ID, &m.SenderID, &m.ReceiverID, &m.Body, &SentAt, &DeliveredAt, &ReadAt, &m.Version}
+		var expiresAt, pinnedAt, starredAt any
+		args = []any{
+			&TotalRows, &m.ID, &m.SenderID, &m.ReceiverID, &m.Body, &SentAt, &DeliveredAt, &ReadAt, &m.Version, &expiresAt,
+			&pinnedAt, &starredAt,
+		}
 		if err := rows.Scan(args...); err != nil {
 			return nil, &domain.Metadata{}, err
 		}
 		m.SentAt, _ = parseTime(SentAt)
 		m.DeliveredAt, _ = parseTime(DeliveredAt)
 		m.ReadAt, _ = parseTime(ReadAt)
+		m.ExpiresAt = scannedTime(expiresAt)
+		m.PinnedAt = scannedTime(pinnedAt)
+		m.StarredAt = scannedTime(starredAt)
 		msgs = append(msgs, &m)
 	}
 	metadata := domain.CalculateMetadata(TotalRows, fil.PageSize, fil.Page)
 	return msgs, &metadata, nil
 }
+
+// SetMsgPinnedAt pins the msg at pinnedAt, or unpins it if nil
+func (r LocalMessageRepository) SetMsgPinnedAt(id string, pinnedAt *time.Time) error {
+	query := `
+		UPDATE message SET pinned_at = $2 WHERE id = $1
+	`
+	_, err := r.db.Exec(query, id, pinnedAt)
+	return err
+}
+
+// SetMsgStarredAt stars the msg at starredAt, or unstars it if nil
+func (r LocalMessageRepository) SetMsgStarredAt(id string, starredAt *time.Time) error {
+	query := `
+		UPDATE message SET starred_at = $2 WHERE id = $1
+	`
+	_, err := r.db.Exec(query, id, starredAt)
+	return err
+}
+
+// GetPinnedMsgs returns the pinned msgs of the conversation with convo, the latest pinned first
+func (r LocalMessageRepository) GetPinnedMsgs(convo string) ([]*domain.Message, error) {
+	query := `
+		SELECT id, sender_id, receiver_id, body, sent_at, pinned_at
+		FROM message
+		WHERE (sender_id = $1 OR receiver_id = $1) AND pinned_at IS NOT NULL
+		ORDER BY pinned_at DESC
+	`
+	rows, err := r.db.Query(query, convo)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	msgs := make([]*domain.Message, 0)
+	for rows.Next() {
+		var m domain.Message
+		var SentAt *string
+		var pinnedAt any
+		if err = rows.Scan(&m.ID, &m.SenderID, &m.ReceiverID, &m.Body, &SentAt, &pinnedAt); err != nil {
+			return nil, err
+		}
+		m.SentAt, _ = parseTime(SentAt)
+		m.PinnedAt = scannedTime(pinnedAt)
+		msgs = append(msgs, &m)
+	}
+	return msgs, rows.Err()
+}
+
+// GetStarredMsgsAsPage pages the starred msgs of every conversation, the latest starred first, as hits without a
+// snippet
+func (r LocalMessageRepository) GetStarredMsgsAsPage(fil domain.Filter) ([]*domain.MessageHit, *domain.Metadata, error) {
+	query := `
+		SELECT COUNT(*) OVER(), m.id, m.sender_id, m.receiver_id, m.body, m.sent_at, m.starred_at,
+		       COALESCE(c.username, '')
+		FROM message m
+		LEFT JOIN conversation c ON c.user_id IN (m.sender_id, m.receiver_id) -- only holds the partners
+		WHERE m.starred_at IS NOT NULL
+		ORDER BY m.starred_at DESC
+		LIMIT $1
+		OFFSET $2
+	`
+	rows, err := r.db.Query(query, fil.Limit(), fil.Offset())
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	var totalRows int
+	hits := make([]*domain.MessageHit, 0)
+	for rows.Next() {
+		h := domain.MessageHit{Message: new(domain.Message)}
+		var SentAt *string
+		var starredAt any
+		dest := []any{&totalRows, &h.ID, &h.SenderID, &h.ReceiverID, &h.Body, &SentAt, &starredAt, &h.Username}
+		if err = rows.Scan(dest...); err != nil {
+			return nil, nil, err
+		}
+		h.SentAt, _ = parseTime(SentAt)
+		h.StarredAt = scannedTime(starredAt)
+		hits = append(hits, &h)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	metadata := domain.CalculateMetadata(totalRows, fil.PageSize, fil.Page)
+	return hits, &metadata, nil
+}
+
+// Search pages the msgs matching the query, best matches first, of the conversation with convo or every conversation
+// if empty. Each term of the query matches as is, the last one as a prefix too, as the user may still be typing it
+func (r LocalMessageRepository) Search(
+	query, convo string,
+	fil domain.Filter,
+) ([]*domain.MessageHit, *domain.Metadata, error) {
+	if !r.db.searchable {
+		return nil, nil, ErrSearchUnavailable
+	}
+	match := ftsQuery(query)
+	if match == "" {
+		metadata := domain.CalculateMetadata(0, fil.PageSize, fil.Page)
+		return []*domain.MessageHit{}, &metadata, nil
+	}
+	// snippet can not be used along a window function, so the matches are selected first
+	stmt := `
+		WITH hit AS (
+			SELECT rowid, rank, snippet(message_fts, 0, char(2), char(3), '…', 12) snippet -- domain.SnippetMatch*
+			FROM message_fts
+			WHERE message_fts MATCH $1
+		)
+		SELECT COUNT(*) OVER(), m.id, m.sender_id, m.receiver_id, m.body, m.sent_at, m.delivered_at, m.read_at,
+		       m.version, hit.snippet, COALESCE(c.username, '')
+		FROM hit
+		JOIN message m ON m.rowid = hit.rowid
+		LEFT JOIN conversation c ON c.user_id IN (m.sender_id, m.receiver_id) -- only holds the partners
+		WHERE $2 = '' OR m.sender_id = $2 OR m.receiver_id = $2
+		ORDER BY hit.rank, m.sent_at DESC
+		LIMIT $3
+		OFFSET $4
+	`
+	args := []any{match, convo, fil.Limit(), fil.Offset()}
+	rows, err := r.db.Query(stmt, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	var totalRows int
+	hits := make([]*domain.MessageHit, 0)
+	for rows.Next() {
+		h := domain.MessageHit{Message: new(domain.Message)}
+		var SentAt, DeliveredAt, ReadAt *string
+		dest := []any{&totalRows, &h.ID, &h.SenderID, &h.ReceiverID, &h.Body, &SentAt, &DeliveredAt, &ReadAt,
+			&h.Version, &h.Snippet, &h.Username}
+		if err = rows.Scan(dest...); err != nil {
+			return nil, nil, err
+		}
+		h.SentAt, _ = parseTime(SentAt)
+		h.DeliveredAt, _ = parseTime(DeliveredAt)
+		h.ReadAt, _ = parseTime(ReadAt)
+		hits = append(hits, &h)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	metadata := domain.CalculateMetadata(totalRows, fil.PageSize, fil.Page)
+	return hits, &metadata, nil
+}
+
+// GetMsgOffset returns the count of the msgs of the conversation with convo sent after the msg, i.e. its offset in
+// the pages of GetMsgsAsPage
+func (r LocalMessageRepository) GetMsgOffset(convo, msgID string) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM message
+		WHERE (sender_id = $1 OR receiver_id = $1)
+		AND sent_at > (SELECT sent_at FROM message WHERE id = $2)
+	`
+	var offset int
+	if err := r.db.QueryRow(query, convo, msgID).Scan(&offset); err != nil {
+		return 0, err
+	}
+	return offset, nil
+}
+
+// Helpers & Stuff -----------------------------------------------------------------------------------------------------
+
+// ftsQuery quotes each term of the query, so no FTS5 syntax the user types is interpreted, the last term is also
+// matched as a prefix
+func ftsQuery(query string) string {
+	terms := strings.Fields(query)
+	for i, t := range terms {
+		terms[i] = `"` + strings.ReplaceAll(t, `"`, `""`) + `"`
+	}
+	if len(terms) > 0 {
+		terms[len(terms)-1] += "*"
+	}
+	return strings.Join(terms, " ")
+}
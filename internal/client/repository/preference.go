@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+)
+
+type LocalPreferenceRepository struct {
+	db *DB
+}
+
+func NewLocalPreferenceRepository(db *DB) LocalPreferenceRepository {
+	return LocalPreferenceRepository{db}
+}
+
+// GetRawMsgs reports whether the msg bodies are shown as typed for usrID, false if never set
+func (r LocalPreferenceRepository) GetRawMsgs(usrID string) (bool, error) {
+	query := `
+		SELECT raw_msgs FROM user_preference WHERE user_id = $1
+	`
+	var raw bool
+	err := r.db.QueryRow(query, usrID).Scan(&raw)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return raw, err
+}
+
+func (r LocalPreferenceRepository) SaveRawMsgs(usrID string, raw bool) error {
+	query := `
+		INSERT INTO user_preference (user_id, raw_msgs) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET raw_msgs = excluded.raw_msgs
+	`
+	_, err := r.db.Exec(query, usrID, raw)
+	return err
+}
+
+// GetMutedConvos returns the ids of the users whose conversations are muted
+func (r LocalPreferenceRepository) GetMutedConvos() ([]string, error) {
+	query := `
+		SELECT user_id FROM muted_conversation
+	`
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ids := make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r LocalPreferenceRepository) SaveMutedConvo(usrID string) error {
+	query := `
+		INSERT OR IGNORE INTO muted_conversation (user_id) VALUES ($1)
+	`
+	_, err := r.db.Exec(query, usrID)
+	return err
+}
+
+func (r LocalPreferenceRepository) DeleteMutedConvo(usrID string) error {
+	query := `
+		DELETE FROM muted_conversation WHERE user_id = $1
+	`
+	_, err := r.db.Exec(query, usrID)
+	return err
+}
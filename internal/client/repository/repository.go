@@ -4,6 +4,8 @@ type LocalRepository struct {
 	LocalUserRepository
 	LocalConversationRepository
 	LocalMessageRepository
+	LocalSyncRepository
+	LocalPreferenceRepository
 }
 
 func NewLocalRepository(db *DB) *LocalRepository {
@@ -11,5 +13,7 @@ func NewLocalRepository(db *DB) *LocalRepository {
 		LocalUserRepository:         newLocalUserRepository(db),
 		LocalConversationRepository: NewLocalConversationRepository(db),
 		LocalMessageRepository:      NewLocalMessageRepository(db),
+		LocalSyncRepository:         NewLocalSyncRepository(db),
+		LocalPreferenceRepository:   NewLocalPreferenceRepository(db),
 	}
 }
@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/mattn/go-sqlite3"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 )
 
@@ -42,17 +44,78 @@ const ( // Local Database tables for client side application
             last_online DATETIME
 		);
 	`
+	// columns added to the tables above, after their creation
+	conversationProfileColumns = `
+		status_text TEXT NOT NULL DEFAULT '',
+		status_emoji TEXT NOT NULL DEFAULT '',
+		status_expires_at DATETIME,
+		bio TEXT NOT NULL DEFAULT '',
+		user_handle TEXT,
+		disappear_after INTEGER NOT NULL DEFAULT 0,
+		user_is_bot BOOLEAN NOT NULL DEFAULT FALSE
+	`
+	messageExpiryColumns = `
+		expires_at DATETIME
+	`
+	// pinned for both users of the conversation, starred for the current user only
+	messageMarkColumns = `
+		pinned_at DATETIME,
+		starred_at DATETIME
+	`
+	// external content table over message.body, the triggers keep it in sync with the message table, it is keyed on
+	// the implicit rowid of message which only a VACUUM may renumber, so the index must be rebuilt after one
+	createMessageFTSTable = `
+		CREATE VIRTUAL TABLE IF NOT EXISTS message_fts USING fts5(
+			body,
+			content = 'message',
+			content_rowid = 'rowid',
+			tokenize = 'unicode61 remove_diacritics 2'
+		);
+		CREATE TRIGGER IF NOT EXISTS message_fts_ai AFTER INSERT ON message BEGIN
+			INSERT INTO message_fts (rowid, body) VALUES (new.rowid, new.body);
+		END;
+		CREATE TRIGGER IF NOT EXISTS message_fts_ad AFTER DELETE ON message BEGIN
+			INSERT INTO message_fts (message_fts, rowid, body) VALUES ('delete', old.rowid, old.body);
+		END;
+		CREATE TRIGGER IF NOT EXISTS message_fts_au AFTER UPDATE OF body ON message BEGIN
+			INSERT INTO message_fts (message_fts, rowid, body) VALUES ('delete', old.rowid, old.body);
+			INSERT INTO message_fts (rowid, body) VALUES (new.rowid, new.body);
+		END;
+	`
+	createSyncStateTable = `
+		-- single row, holding the cursor of the last server event persisted locally
+		CREATE TABLE IF NOT EXISTS sync_state (
+            id INTEGER PRIMARY KEY CHECK (id = 1),
+            cursor INTEGER NOT NULL DEFAULT 0
+		);
+		INSERT OR IGNORE INTO sync_state (id, cursor) VALUES (1, 0);
+	`
+	createUserPreferenceTable = `
+		-- preferences of the users logged-in on this client, a missing row is the defaults
+		CREATE TABLE IF NOT EXISTS user_preference (
+            user_id TEXT PRIMARY KEY,
+            raw_msgs BOOLEAN NOT NULL DEFAULT FALSE -- msg bodies shown as typed, not rendered as markdown
+		);
+	`
+	createMutedConversationTable = `
+		-- the conversations the current user is not notified of
+		CREATE TABLE IF NOT EXISTS muted_conversation (
+            user_id TEXT PRIMARY KEY
+		);
+	`
 )
 
 type DB struct {
 	*sqlx.DB
+	// false if sqlite is built without FTS5, the sqlite_fts5 build tag, the local search is then unavailable
+	searchable bool
 }
 
-func OpenDB(filesDir string, key int) (*DB, error) {
+// OpenDB opens the db of the account by its id on the profile by its storage name, each account has its own
+func OpenDB(filesDir, profile, account string) (*DB, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
-	name := fmt.Sprintf("Letschat%v.db", key)
-	db, err := sqlx.ConnectContext(ctx, "sqlite3", filepath.Join(filesDir, name))
+	db, err := sqlx.ConnectContext(ctx, "sqlite3", dbFilePath(filesDir, profile, account))
 	if err == nil {
 		db.SetMaxOpenConns(5)
 		db.SetMaxIdleConns(5)
@@ -61,11 +124,20 @@ func OpenDB(filesDir string, key int) (*DB, error) {
 	if err != nil && db != nil {
 		db.Close()
 	}
-	return &DB{db}, err
+	return &DB{DB: db}, err
 }
 
-func DeleteDBFile(filesDir string) error {
-	return os.Remove(filepath.Join(filesDir, "Letschat.db"))
+func DeleteDBFile(filesDir, profile, account string) error {
+	return os.Remove(dbFilePath(filesDir, profile, account))
+}
+
+// dbFilePath is Letschat<account>.db, or Letschat_<profile><account>.db if profile is not empty
+func dbFilePath(filesDir, profile, account string) string {
+	name := fmt.Sprintf("Letschat%v.db", account)
+	if profile != "" {
+		name = fmt.Sprintf("Letschat_%v%v.db", profile, account)
+	}
+	return filepath.Join(filesDir, name)
 }
 
 func (db *DB) RunMigrations() error {
@@ -80,5 +152,66 @@ func (db *DB) RunMigrations() error {
 	if _, err := db.ExecContext(ctx, createConversationTable); err != nil {
 		return err
 	}
+	if err := db.addColumns(ctx, "conversation", conversationProfileColumns); err != nil {
+		return err
+	}
+	if err := db.addColumns(ctx, "message", messageExpiryColumns); err != nil {
+		return err
+	}
+	if err := db.addColumns(ctx, "message", messageMarkColumns); err != nil {
+		return err
+	}
+	if _, err := db.ExecContext(ctx, createSyncStateTable); err != nil {
+		return err
+	}
+	if _, err := db.ExecContext(ctx, createUserPreferenceTable); err != nil {
+		return err
+	}
+	if _, err := db.ExecContext(ctx, createMutedConversationTable); err != nil {
+		return err
+	}
+	return db.createMessageFTS(ctx)
+}
+
+// createMessageFTS creates the full-text index of the messages, indexing the ones already persisted on creation
+func (db *DB) createMessageFTS(ctx context.Context) error {
+	var exists bool
+	query := `SELECT COUNT(*) > 0 FROM sqlite_master WHERE type = 'table' AND name = 'message_fts'`
+	if err := db.QueryRowContext(ctx, query).Scan(&exists); err != nil {
+		return err
+	}
+	if _, err := db.ExecContext(ctx, createMessageFTSTable); err != nil {
+		if strings.Contains(err.Error(), "no such module: fts5") {
+			slog.Warn("sqlite is built without FTS5, searching the messages is unavailable")
+			return nil
+		}
+		return err
+	}
+	db.searchable = true
+	if exists {
+		return nil
+	}
+	_, err := db.ExecContext(ctx, `INSERT INTO message_fts (message_fts) VALUES ('rebuild')`)
+	return err
+}
+
+// addColumns adds the comma separated column definitions to the table, skipping the existing ones, sqlite has no
+// ADD COLUMN IF NOT EXISTS
+func (db *DB) addColumns(ctx context.Context, table, columns string) error {
+	for _, def := range strings.Split(columns, ",") {
+		def = strings.TrimSpace(def)
+		name, _, _ := strings.Cut(def, " ")
+		var exists bool
+		query := `SELECT COUNT(*) > 0 FROM pragma_table_info(?) WHERE name = ?`
+		if err := db.QueryRowContext(ctx, query, table, name).Scan(&exists); err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		if _, err := db.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %v ADD COLUMN %v", table, def)); err != nil {
+			return err
+		}
+	}
 	return nil
 }
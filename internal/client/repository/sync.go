@@ -0,0 +1,27 @@
+package repository
+
+type LocalSyncRepository struct {
+	db *DB
+}
+
+func NewLocalSyncRepository(db *DB) LocalSyncRepository {
+	return LocalSyncRepository{db}
+}
+
+func (r LocalSyncRepository) GetSyncCursor() (int64, error) {
+	query := `
+		SELECT cursor FROM sync_state WHERE id = 1
+	`
+	var cursor int64
+	err := r.db.QueryRow(query).Scan(&cursor)
+	return cursor, err
+}
+
+// SaveSyncCursor only moves the cursor forward
+func (r LocalSyncRepository) SaveSyncCursor(cursor int64) error {
+	query := `
+		UPDATE sync_state SET cursor = MAX(cursor, $1) WHERE id = 1
+	`
+	_, err := r.db.Exec(query, cursor)
+	return err
+}
@@ -0,0 +1,119 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/MuhamedUsman/letschat/internal/domain"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// ScheduleMessage schedules the body to be sent to the receiver at sendAt by the server, see domain.ScheduledMessage
+func (c *Client) ScheduleMessage(receiverID, body string, sendAt time.Time) (*domain.ScheduledMessage, int, error) {
+	in := domain.ScheduledMessageInput{ReceiverID: &receiverID, Body: &body, SendAt: &sendAt}
+	return c.writeScheduledMessage(http.MethodPost, c.ep.scheduledMessages, in)
+}
+
+// UpdateScheduledMessage sets the body & the send time of the pending sm, http.StatusConflict if it is released or
+// edited in the meantime
+func (c *Client) UpdateScheduledMessage(sm *domain.ScheduledMessage) (*domain.ScheduledMessage, int, error) {
+	in := domain.ScheduledMessageInput{Body: &sm.Body, SendAt: &sm.SendAt, Version: &sm.Version}
+	return c.writeScheduledMessage(http.MethodPut, c.ep.scheduledMessages+"/"+sm.ID, in)
+}
+
+// GetScheduledMessages fetches the pending msgs to the receiver, due first
+func (c *Client) GetScheduledMessages(receiverID string) ([]*domain.ScheduledMessage, int, error) {
+	r, err := http.NewRequest(http.MethodGet, c.ep.scheduledMessages, nil)
+	if err != nil {
+		slog.Error(err.Error())
+		return nil, 0, ErrApplication
+	}
+	r.Header.Set("Authorization", "Bearer "+c.AuthToken)
+	v := r.URL.Query()
+	v.Set("receiverID", receiverID)
+	r.URL.RawQuery = v.Encode()
+	resp, err := c.httpClient.Do(r)
+	if err != nil {
+		slog.Error(err.Error())
+		return nil, http.StatusServiceUnavailable, getMostNestedError(err)
+	}
+	defer resp.Body.Close()
+	readBody, _ := io.ReadAll(resp.Body)
+	var res struct {
+		ScheduledMessages []*domain.ScheduledMessage `json:"scheduled_messages"`
+	}
+	if err = json.Unmarshal(readBody, &res); err != nil {
+		slog.Error(err.Error())
+		return nil, 0, ErrApplication
+	}
+	return res.ScheduledMessages, resp.StatusCode, nil
+}
+
+// CancelScheduledMessage deletes the pending msg, http.StatusNotFound if it is released already
+func (c *Client) CancelScheduledMessage(id string) (int, error) {
+	r, err := http.NewRequest(http.MethodDelete, c.ep.scheduledMessages+"/"+id, nil)
+	if err != nil {
+		slog.Error(err.Error())
+		return 0, ErrApplication
+	}
+	r.Header.Set("Authorization", "Bearer "+c.AuthToken)
+	resp, err := c.httpClient.Do(r)
+	if err != nil {
+		slog.Error(err.Error())
+		return http.StatusServiceUnavailable, getMostNestedError(err)
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// Helpers & Stuff -----------------------------------------------------------------------------------------------------
+
+func (c *Client) writeScheduledMessage(
+	method, url string,
+	in domain.ScheduledMessageInput,
+) (*domain.ScheduledMessage, int, error) {
+	jsonBytes, err := json.Marshal(in)
+	if err != nil {
+		slog.Error(err.Error())
+		return nil, 0, ErrApplication
+	}
+	r, err := http.NewRequest(method, url, bytes.NewBuffer(jsonBytes))
+	if err != nil {
+		slog.Error(err.Error())
+		return nil, 0, ErrApplication
+	}
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Authorization", "Bearer "+c.AuthToken)
+	resp, err := c.httpClient.Do(r)
+	if err != nil {
+		slog.Error(err.Error())
+		return nil, http.StatusServiceUnavailable, getMostNestedError(err)
+	}
+	defer resp.Body.Close()
+	readBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode == http.StatusUnprocessableEntity {
+		var ev struct {
+			Errors map[string]string `json:"errors"`
+		}
+		if err = json.Unmarshal(readBody, &ev); err != nil {
+			slog.Error(err.Error())
+			return nil, 0, ErrApplication
+		}
+		dev := domain.NewErrValidation()
+		dev.Errors = ev.Errors
+		return nil, resp.StatusCode, dev
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return nil, resp.StatusCode, nil
+	}
+	var res struct {
+		ScheduledMessage *domain.ScheduledMessage `json:"scheduled_message"`
+	}
+	if err = json.Unmarshal(readBody, &res); err != nil {
+		slog.Error(err.Error())
+		return nil, 0, ErrApplication
+	}
+	return res.ScheduledMessage, resp.StatusCode, nil
+}
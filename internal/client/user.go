@@ -12,7 +12,6 @@ import (
 	"log"
 	"log/slog"
 	"net/http"
-	"strconv"
 )
 
 // LoginState true -> successful login, false -> unauthorized requires login
@@ -26,13 +25,13 @@ func newLoginBroadcaster() *LoginBroadcaster {
 
 // Register will register the user & populate the *domain.UserRegister with validation errors
 // in case of http.StatusUnprocessableEntity
-func (*Client) Register(u *domain.UserRegister) error {
+func (c *Client) Register(u *domain.UserRegister) error {
 	body, err := json.Marshal(u)
 	if err != nil {
 		slog.Error(err.Error())
 		return err
 	}
-	resp, err := http.DefaultClient.Post(registerUser, "application/json", bytes.NewBuffer(body))
+	resp, err := c.httpClient.Post(c.ep.registerUser, "application/json", bytes.NewBuffer(body))
 	if err != nil {
 		slog.Error(err.Error())
 		return getMostNestedError(err)
@@ -66,7 +65,7 @@ func (c *Client) Login(u domain.UserAuth) error {
 		slog.Error(err.Error())
 		return err
 	}
-	res, err := http.DefaultClient.Post(authenticate, "application/json", bytes.NewBuffer(b))
+	res, err := c.httpClient.Post(c.ep.authenticate, "application/json", bytes.NewBuffer(b))
 	if err != nil {
 		slog.Error(err.Error())
 		return getMostNestedError(err)
@@ -131,7 +130,7 @@ func (c *Client) ActivateUser(otp string) error {
 		slog.Error(err.Error())
 		return err
 	}
-	res, err := http.Post(activateUser, "application/json", bytes.NewBuffer(jsonBytes))
+	res, err := c.httpClient.Post(c.ep.activateUser, "application/json", bytes.NewBuffer(jsonBytes))
 	if err != nil {
 		slog.Error(err.Error())
 		return getMostNestedError(err)
@@ -152,14 +151,14 @@ func (c *Client) UpdateUser(u domain.UserUpdate) (*domain.ErrValidation, int, er
 		slog.Error(err.Error())
 		return nil, 0, ErrApplication
 	}
-	req, err := http.NewRequest(http.MethodPut, updateUser, bytes.NewBuffer(jsonBytes))
+	req, err := http.NewRequest(http.MethodPut, c.ep.updateUser, bytes.NewBuffer(jsonBytes))
 	if err != nil {
 		slog.Error(err.Error())
 		return nil, 0, ErrApplication
 	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+c.AuthToken)
-	res, err := http.DefaultClient.Do(req)
+	res, err := c.httpClient.Do(req)
 	if err != nil {
 		slog.Error(err.Error())
 		return nil, http.StatusServiceUnavailable, getMostNestedError(err)
@@ -212,12 +211,13 @@ func (c *Client) UpdateUser(u domain.UserUpdate) (*domain.ErrValidation, int, er
 }
 
 type PagedUserResponse struct {
-	Metadata domain.Metadata `json:"metadata"`
-	Users    []domain.User   `json:"users"`
+	Metadata domain.CursorMetadata `json:"metadata"`
+	Users    []domain.User         `json:"users"`
 }
 
-func (c *Client) SearchUser(param string, page int) (*PagedUserResponse, int, error) {
-	r, err := http.NewRequest(http.MethodGet, searchUser, nil)
+// SearchUser fetches the page of the users after the cursor, an empty one for the first page
+func (c *Client) SearchUser(param, after string) (*PagedUserResponse, int, error) {
+	r, err := http.NewRequest(http.MethodGet, c.ep.searchUser, nil)
 	if err != nil {
 		slog.Error(err.Error())
 		return nil, 0, ErrApplication
@@ -225,9 +225,11 @@ func (c *Client) SearchUser(param string, page int) (*PagedUserResponse, int, er
 	r.Header.Set("Authorization", "Bearer "+c.AuthToken)
 	v := r.URL.Query()
 	v.Set("param", param)
-	v.Set("page", strconv.Itoa(page))
+	if after != "" {
+		v.Set("after", after)
+	}
 	r.URL.RawQuery = v.Encode()
-	resp, err := http.DefaultClient.Do(r)
+	resp, err := c.httpClient.Do(r)
 	if err != nil {
 		slog.Error(err.Error())
 		return nil, http.StatusServiceUnavailable, getMostNestedError(err)
@@ -243,13 +245,13 @@ func (c *Client) SearchUser(param string, page int) (*PagedUserResponse, int, er
 }
 
 func (c *Client) GetCurrentActiveUser() (*domain.User, int, error) {
-	r, err := http.NewRequest(http.MethodGet, getCurrentActiveUser, nil)
+	r, err := http.NewRequest(http.MethodGet, c.ep.getCurrentActiveUser, nil)
 	if err != nil {
 		slog.Error(err.Error())
 		return nil, 0, ErrApplication
 	}
 	r.Header.Set("Authorization", "Bearer "+c.AuthToken)
-	resp, err := http.DefaultClient.Do(r)
+	resp, err := c.httpClient.Do(r)
 	if err != nil {
 		slog.Error(err.Error())
 		return nil, http.StatusServiceUnavailable, getMostNestedError(err)
@@ -286,12 +288,15 @@ func (c *Client) manageUserLogins(shtdwnCtx context.Context) {
 		retrievedUsr, _ := c.repo.GetCurrentUser() // ignore the error
 		// delete the previous db
 		if retrievedUsr != nil && retrievedUsr.ID != u.ID {
+			if err := c.db.Close(); err != nil {
+				slog.Error(err.Error())
+			}
 			// ignore the error, missing file path, already deleted
-			if err := repository.DeleteDBFile(c.FilesDir); err != nil {
+			if err := repository.DeleteDBFile(c.FilesDir, c.Profile.storageName(), c.Account); err != nil {
 				slog.Error(err.Error())
 			}
 			// Opening a new conn to sqlite db will create a new file
-			db, err := repository.OpenDB(c.FilesDir, c.krm.key)
+			db, err := repository.OpenDB(c.FilesDir, c.Profile.storageName(), c.Account)
 			// very unlikely but if happens, there is no reason to continue normal application execution
 			if err != nil {
 				log.Fatal(err)
@@ -300,6 +305,7 @@ func (c *Client) manageUserLogins(shtdwnCtx context.Context) {
 			if err = c.db.RunMigrations(); err != nil {
 				log.Fatal(err)
 			}
+			c.repo = repository.NewLocalRepository(c.db)
 		} else {
 			if err := c.repo.DeletePreviousUser(); err != nil {
 				slog.Error("unable to delete previous user", "err", err.Error())
@@ -308,5 +314,6 @@ func (c *Client) manageUserLogins(shtdwnCtx context.Context) {
 		if err := c.repo.SaveCurrentUser(u); err != nil {
 			slog.Error("unable to save current user to local repo", "err", err.Error())
 		}
+		c.loadPreferences()
 	}
 }
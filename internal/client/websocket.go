@@ -2,7 +2,9 @@ package client
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"github.com/MuhamedUsman/letschat/internal/common"
 	"github.com/MuhamedUsman/letschat/internal/domain"
 	"github.com/MuhamedUsman/letschat/internal/sync"
 	"github.com/coder/websocket"
@@ -25,6 +27,9 @@ const (
 	Connected
 )
 
+// resyncInterval is how often the events relayed live are synced again, persisting the cursor past them
+const resyncInterval = time.Minute
+
 type WsConnBroadcaster = sync.Broadcaster[WsConnState]
 
 func newWsConnBroadcaster() *WsConnBroadcaster {
@@ -40,9 +45,10 @@ func (c *Client) wsConnectAndListenForMessages(shtdwnCtx context.Context) {
 	opts := &websocket.DialOptions{
 		CompressionMode: websocket.CompressionContextTakeover,
 		HTTPHeader:      h,
+		HTTPClient:      c.httpClient,
 	}
-	conn, r, err := websocket.Dial(context.Background(), subscribeTo, opts)
-	c.wsConn = conn
+	conn, r, err := websocket.Dial(context.Background(), c.ep.subscribeTo, opts)
+	c.wsConn.Store(conn)
 	if err != nil {
 		if r != nil && r.StatusCode == http.StatusUnauthorized {
 			c.LoginState.Write(false)
@@ -60,10 +66,33 @@ func (c *Client) wsConnectAndListenForMessages(shtdwnCtx context.Context) {
 		return
 	}
 	c.WsConnState.Write(Connected)
-	errChan := make(chan error)
+	// buffered for all three goroutines, so the ones still running after the first error do not block forever
+	errChan := make(chan error, 3)
+	hbCtx, cancel := context.WithCancel(shtdwnCtx)
+	defer cancel()
+	hb := common.NewHeartbeat(c.HeartbeatInterval, c.MaxMissedHeartbeats)
+	c.heartbeat.Store(hb)
 	go func() { errChan <- c.handleSentMessages(conn, shtdwnCtx) }()
 	go func() { errChan <- c.handleReceiveMessages(conn, shtdwnCtx) }()
+	// otherwise a dead NAT mapping leaves us Connected, without receiving anything
+	go func() {
+		if err := hb.Run(hbCtx, conn); err != nil {
+			errChan <- err
+		}
+	}()
+	if err = c.requestSync(conn); err != nil {
+		slog.Error(err.Error())
+	}
+	go c.resyncPeriodically(hbCtx, conn)
+	if c.effectivePresence() != domain.Online {
+		if err = c.writePresence(); err != nil {
+			slog.Error(err.Error())
+		}
+	}
 	if err = <-errChan; err != nil {
+		if hint, ok := goingAwayHint(err); ok {
+			c.reconnectHint.Store(int64(hint))
+		}
 		if shtdwnCtx.Err() == nil && c.LoginState.Get() { // In case the shtdwnCtx is canceled we do not signal a Disconnect
 			c.WsConnState.Write(Disconnected)
 		}
@@ -86,6 +115,9 @@ func (c *Client) handleReceiveMessages(conn *websocket.Conn, shtdwnCtx context.C
 		if err := wsjson.Read(shtdwnCtx, conn, &msg); err != nil {
 			return err
 		}
+		if msg.Operation == domain.ErrorMsg {
+			c.undoRejected(&msg)
+		}
 		c.RecvMsgs.Write(&msg)
 	}
 }
@@ -112,6 +144,50 @@ func (c *Client) handleSentMessages(conn *websocket.Conn, shtdwnCtx context.Cont
 	}
 }
 
+// RTT returns the round trip time of the last heartbeat of the ws conn, zero if not connected or not measured yet
+func (c *Client) RTT() time.Duration {
+	hb := c.heartbeat.Load()
+	if hb == nil || c.WsConnState.Get() != Connected {
+		return 0
+	}
+	return hb.RTT()
+}
+
+// requestSync asks the server for every event after the last one persisted locally, the cursor is only persisted
+// once the server signals the end of the sync with domain.SyncDoneMsg, the events before it are all streamed in order
+func (c *Client) requestSync(conn *websocket.Conn) error {
+	c.synced.Store(false)
+	since, err := c.repo.GetSyncCursor()
+	if err != nil {
+		return err
+	}
+	req := domain.SyncRequest{
+		Operation: domain.SyncRequestMsg,
+		Since:     since,
+	}
+	return writeWithTimeout(conn, 2*time.Second, req)
+}
+
+// resyncPeriodically syncs again every resyncInterval while events are relayed live, till ctx is done, the server
+// acknowledges the events up to the persisted cursor & replays the ones after it, those already applied are skipped
+func (c *Client) resyncPeriodically(ctx context.Context, conn *websocket.Conn) {
+	ticker := time.NewTicker(resyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if !c.synced.Load() || !c.liveEvents.Swap(false) {
+				continue
+			}
+			if err := c.requestSync(conn); err != nil {
+				slog.Error(err.Error())
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
 // AttemptWsReconnectOnDisconnect must be run in a separate go routine, principal -> finite state machine
 func (c *Client) attemptWsReconnectOnDisconnect(shtdwnCtx context.Context) {
 	token, ch := c.WsConnState.Subscribe()
@@ -119,6 +195,8 @@ func (c *Client) attemptWsReconnectOnDisconnect(shtdwnCtx context.Context) {
 	attempt := 1
 	maxAttempts := 5
 	maxDelay := 40 * time.Second
+	// the reconnect suggested by the server is not a failed attempt
+	hinted := false
 	for {
 		select {
 		case s := <-ch:
@@ -127,20 +205,27 @@ func (c *Client) attemptWsReconnectOnDisconnect(shtdwnCtx context.Context) {
 			case Disconnected:
 				c.WsConnState.Write(WaitingForConnection)
 			case Idle:
-				if c.wsConn != nil {
-					c.wsConn.CloseNow()
+				if conn := c.wsConn.Load(); conn != nil {
+					conn.CloseNow()
 				}
 				attempt = 0
 				// do nothing, will be the case when user is logging in or signing up
 			case WaitingForConnection:
-				// After 5th retry
-				if attempt == maxAttempts {
-					c.WsConnState.Write(Disconnected)
-					return
+				var delay time.Duration
+				if hint := time.Duration(c.reconnectHint.Swap(0)); hint > 0 {
+					// jitter, so all the clients of a restarting server do not reconnect at once
+					delay = hint + rand.N(hint)
+					hinted = true
+				} else {
+					// After 5th retry
+					if attempt == maxAttempts {
+						c.WsConnState.Write(Disconnected)
+						return
+					}
+					// reconnecting after backoff time
+					delay = exponentialBackoff(attempt, maxDelay)
 				}
-				// reconnecting after backoff time
-				expbackoff := exponentialBackoff(attempt, maxDelay)
-				t := time.NewTimer(expbackoff)
+				t := time.NewTimer(delay)
 				select {
 				case <-t.C:
 					c.WsConnState.Write(Connecting)
@@ -150,7 +235,10 @@ func (c *Client) attemptWsReconnectOnDisconnect(shtdwnCtx context.Context) {
 				}
 			case Connecting:
 				go c.wsConnectAndListenForMessages(shtdwnCtx)
-				attempt++
+				if !hinted {
+					attempt++
+				}
+				hinted = false
 			case Connected:
 				attempt = 0
 			}
@@ -172,7 +260,20 @@ func exponentialBackoff(attempt int, maxDelay time.Duration) time.Duration {
 	return delay
 }
 
-func writeWithTimeout(conn *websocket.Conn, t time.Duration, msg *domain.Message) error {
+// goingAwayHint returns the reconnect delay suggested by the server, if it closed the conn while shutting down
+func goingAwayHint(err error) (time.Duration, bool) {
+	var ce websocket.CloseError
+	if !errors.As(err, &ce) || ce.Code != websocket.StatusGoingAway {
+		return 0, false
+	}
+	var ga domain.GoingAway
+	if err = json.Unmarshal([]byte(ce.Reason), &ga); err != nil || ga.ReconnectAfter <= 0 {
+		return 0, false
+	}
+	return time.Duration(ga.ReconnectAfter) * time.Millisecond, true
+}
+
+func writeWithTimeout(conn *websocket.Conn, t time.Duration, msg any) error {
 	ctx, cancel := context.WithTimeout(context.Background(), t)
 	defer cancel()
 	return wsjson.Write(ctx, conn, msg)
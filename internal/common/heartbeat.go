@@ -0,0 +1,69 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"github.com/coder/websocket"
+	"net"
+	"sync/atomic"
+	"time"
+)
+
+var ErrHeartbeatMissed = errors.New("websocket peer missed too many heartbeats")
+
+// Heartbeat pings a websocket conn every interval, a ping not answered within the interval is missed,
+// once maxMissed pings in a row are missed, the conn is considered stale & closed.
+// Pongs are only read while some goroutine is reading the conn, which is the case on both ends
+type Heartbeat struct {
+	interval  time.Duration
+	maxMissed int
+	rtt       atomic.Int64
+}
+
+func NewHeartbeat(interval time.Duration, maxMissed int) *Heartbeat {
+	return &Heartbeat{
+		interval:  interval,
+		maxMissed: maxMissed,
+	}
+}
+
+// Run blocks until ctx is done, returns nil in that case, or the error that ended the conn,
+// ErrHeartbeatMissed if it was closed by the heartbeat
+func (h *Heartbeat) Run(ctx context.Context, conn *websocket.Conn) error {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+	missed := 0
+	for {
+		select {
+		case <-ticker.C:
+			pingCtx, cancel := context.WithTimeout(ctx, h.interval)
+			start := time.Now()
+			err := conn.Ping(pingCtx)
+			cancel()
+			switch {
+			case err == nil:
+				missed = 0
+				h.rtt.Store(int64(time.Since(start)))
+			case ctx.Err() != nil, errors.Is(err, net.ErrClosed):
+				// closed by the peer, the reading goroutine reports why
+				return nil
+			case errors.Is(err, context.DeadlineExceeded):
+				missed++
+				if missed >= h.maxMissed {
+					// the peer is unresponsive, no point in waiting for the close handshake
+					conn.CloseNow()
+					return ErrHeartbeatMissed
+				}
+			default:
+				return err
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// RTT returns the round trip time of the last answered ping, zero if none is answered yet
+func (h *Heartbeat) RTT() time.Duration {
+	return time.Duration(h.rtt.Load())
+}
@@ -0,0 +1,63 @@
+package domain
+
+import "time"
+
+const (
+	// MaxBotsPerOwner bounds the bots a single user may own
+	MaxBotsPerOwner = 20
+	// BotEmailDomain is the domain of the placeholder emails of the bots, they never receive any mail
+	BotEmailDomain = "bots.letschat.invalid"
+	// MaxBotUpdatesTimeout bounds the long-poll of GET /v1/bot/updates, MaxBotUpdatesLimit the updates it returns
+	MaxBotUpdatesTimeout = 50 * time.Second
+	MaxBotUpdatesLimit   = 100
+)
+
+// BotRegister is the input of a user creating a bot it owns, the handle is required, so the bot can be discovered
+type BotRegister struct {
+	Name   string `json:"name"`
+	Handle string `json:"handle"`
+}
+
+// Bot is a bot along its api key, the key is only ever returned once created or rotated
+type Bot struct {
+	User *User  `json:"user"`
+	Key  string `json:"key,omitempty"`
+}
+
+// BotMessageInput is a msg sent by a bot over POST /v1/bot/messages, instead of the websocket
+type BotMessageInput struct {
+	ReceiverID *string `json:"receiverID"`
+	Body       *string `json:"body"`
+}
+
+func (b *BotRegister) ValidateBotRegister() *ErrValidation {
+	ev := NewErrValidation()
+	ValidateName(b.Name, ev)
+	b.Handle = NormalizeHandle(b.Handle)
+	ValidateHandle(b.Handle, ev)
+	return ev
+}
+
+// Email is the placeholder email of the bot, unique as the handle is
+func (b *BotRegister) Email() string {
+	return b.Handle + "@" + BotEmailDomain
+}
+
+func (i BotMessageInput) ValidateBotMessageInput() *ErrValidation {
+	ev := NewErrValidation()
+	ev.Evaluate(i.ReceiverID != nil && rgxUUID.MatchString(*i.ReceiverID), "receiverID", "must be a valid UUID")
+	ev.Evaluate(i.Body != nil && *i.Body != "", "body", "must be provided")
+	ev.Evaluate(i.Body == nil || len(*i.Body) <= 4000, "body", "must be no more than 4000 bytes long")
+	return ev
+}
+
+// MessageSent is the domain.CreateMsg of the input, as if the bot has sent it over the websocket at t
+func (i BotMessageInput) MessageSent(id string, t time.Time) MessageSent {
+	return MessageSent{
+		ID:         &id,
+		ReceiverID: *i.ReceiverID,
+		Body:       i.Body,
+		SentAt:     &t,
+		Operation:  CreateMsg,
+	}
+}
@@ -9,17 +9,47 @@ type Conversation struct {
 	SenderID   string `json:"-"              db:"sender_id"`
 	ReceiverID string `json:"-"              db:"receiver_id"`
 	// Below given attributes will only be used on TUI (frontend side)
-	UserID    string `json:"userID"          db:"user_id"`
-	Username  string `json:"username"        db:"username"`
-	UserEmail string `json:"userEmail"       db:"user_email"`
+	UserID   string `json:"userID"          db:"user_id"`
+	Username string `json:"username"        db:"username"`
+	// without the leading @, nil if not chosen yet
+	UserHandle *string `json:"userHandle,omitempty" db:"user_handle"`
+	UserEmail  string  `json:"userEmail"       db:"user_email"`
 	// status of user other than the currently logged-in user, can be either sender or receiver
-	LastOnline *time.Time `json:"lastOnline" db:"last_online"`
+	LastOnline *time.Time     `json:"lastOnline" db:"last_online"`
+	Presence   PresenceStatus `json:"presence"   db:"-"`
+	// profile of the user, see Profile
+	StatusText      string     `json:"statusText"                db:"status_text"`
+	StatusEmoji     string     `json:"statusEmoji"               db:"status_emoji"`
+	StatusExpiresAt *time.Time `json:"statusExpiresAt,omitempty" db:"status_expires_at"`
+	Bio             string     `json:"bio"                       db:"bio"`
+	// the user is a bot, see User.IsBot
+	UserIsBot bool `json:"userIsBot" db:"user_is_bot"`
+	// seconds after which the msgs of the conversation disappear for both users, zero if they do not
+	DisappearAfter int64 `json:"disappearAfter" db:"disappear_after"`
 	// latest msg to display under user's name in TUI, only used on frontend side
 	LatestMsg       *string    `json:"-"`
 	LatestMsgSentAt *time.Time `json:"-"`
 	UnreadMsgsCount int64      `json:"-"`
 }
 
+// ClearExpiredStatus clears the status of the user once its StatusExpiresAt has passed
+func (c *Conversation) ClearExpiredStatus() {
+	if StatusExpired(c.StatusExpiresAt) {
+		c.StatusText, c.StatusEmoji, c.StatusExpiresAt = "", "", nil
+	}
+}
+
+// SetProfile updates the user of the conversation with its pushed Profile
+func (c *Conversation) SetProfile(p Profile) {
+	c.Username = p.Name
+	c.UserHandle = p.Handle
+	c.UserEmail = p.Email
+	c.StatusText = p.StatusText
+	c.StatusEmoji = p.StatusEmoji
+	c.StatusExpiresAt = p.StatusExpiresAt
+	c.Bio = p.Bio
+}
+
 type ConvoDesc struct {
 	Body            *string    `db:"body"`
 	SentAt          *time.Time `db:"sent_at"`
@@ -30,10 +60,14 @@ type ConversationService interface {
 	CreateConversation(ctx context.Context, senderID, receiverID string) (bool, error)
 	GetConversations(ctx context.Context) ([]*Conversation, error)
 	ConversationExists(ctx context.Context, senderID, receiverID string) (bool, error)
+	SetDisappearAfter(ctx context.Context, senderID, receiverID string, secs int64) error
+	GetDisappearAfter(ctx context.Context, senderID, receiverID string) (int64, error)
 }
 
 type ConversationRepository interface {
 	CreateConversation(ctx context.Context, senderID, receiverID string) (bool, error)
 	GetConversations(ctx context.Context, usrID string) ([]*Conversation, error)
 	ConversationExists(ctx context.Context, senderID, receiverID string) (bool, error)
+	SetDisappearAfter(ctx context.Context, senderID, receiverID string, secs int64) error
+	GetDisappearAfter(ctx context.Context, senderID, receiverID string) (int64, error)
 }
@@ -3,11 +3,13 @@ package domain
 import "errors"
 
 var (
-	ErrDuplicateEmail = errors.New("duplicate email")
-	ErrRecordNotFound = errors.New("record not found")
-	ErrEditConflict   = errors.New("edit conflict")
-	ErrAlreadyActive  = errors.New("user already active")
-	ErrInactive       = errors.New("user inactive")
+	ErrDuplicateEmail  = errors.New("duplicate email")
+	ErrDuplicateHandle = errors.New("duplicate handle")
+	ErrRecordNotFound  = errors.New("record not found")
+	ErrEditConflict    = errors.New("edit conflict")
+	ErrAlreadyActive   = errors.New("user already active")
+	ErrInactive        = errors.New("user inactive")
+	ErrForbidden       = errors.New("operation not permitted")
 )
 
 type ErrValidation struct {
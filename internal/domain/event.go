@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// SyncRequest is written by the client once connected, asking the server to stream every event after Since,
+// Since is the cursor of the last event the client has persisted, zero if it has none
+type SyncRequest struct {
+	Operation MsgOperation `json:"operation"`
+	Since     int64        `json:"since"`
+}
+
+// EventRepository is a per-user append-only log of the msgs relayed to the users,
+// every event is a Message stamped with a monotonically increasing Cursor
+type EventRepository interface {
+	Append(ctx context.Context, usrID string, m *Message) (int64, error)
+	GetSince(ctx context.Context, usrID string, since int64, limit int) ([]*Message, error)
+	DeleteUntil(ctx context.Context, usrID string, cursor int64) error
+	DeleteExpired(ctx context.Context, until time.Time) (int64, error)
+}
@@ -1,9 +1,13 @@
 package domain
 
 import (
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"github.com/google/uuid"
 	"math"
 	"slices"
+	"strconv"
 	"strings"
 )
 
@@ -68,3 +72,47 @@ func CalculateMetadata(totalRecords, pageSize, currentPage int) Metadata {
 		TotalRecords: totalRecords,
 	}
 }
+
+// CursorFilter is the keyset counterpart of Filter, After being the cursor of the last row already seen, empty for
+// the first page
+type CursorFilter struct {
+	After    string
+	PageSize int
+}
+
+func ValidateCursorFilter(ev *ErrValidation, f *CursorFilter) {
+	ev.Evaluate(f.PageSize > 0, "size", "must be greater than zero")
+	ev.Evaluate(f.PageSize <= 100, "size", "must be a max of 100")
+	if f.After != "" {
+		_, _, err := DecodeCursor(f.After)
+		ev.Evaluate(err == nil, "after", "invalid cursor")
+	}
+}
+
+type CursorMetadata struct {
+	PageSize   int    `json:"pageSize,omitempty"`
+	NextCursor string `json:"nextCursor,omitempty"` // empty once there are no more records
+}
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// EncodeCursor makes an opaque cursor out of the rank of a row and its id, the id breaking ties
+func EncodeCursor(rank float64, id string) string {
+	s := strconv.FormatFloat(rank, 'f', -1, 64) + "|" + id
+	return base64.RawURLEncoding.EncodeToString([]byte(s))
+}
+
+func DecodeCursor(cursor string) (rank float64, id string, err error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, "", errInvalidCursor
+	}
+	r, id, ok := strings.Cut(string(b), "|")
+	if !ok || uuid.Validate(id) != nil {
+		return 0, "", errInvalidCursor
+	}
+	if rank, err = strconv.ParseFloat(r, 64); err != nil {
+		return 0, "", errInvalidCursor
+	}
+	return rank, id, nil
+}
@@ -27,9 +27,11 @@ const (
 	// the receiving side will delete the msg, before sending this confirmation.
 	// not to be persisted
 	DeleteConfirmMsg
-	// OnlineMsg indicates the user is online; a msg with this OP must not be persisted
+	// OnlineMsg indicates the user is online; a msg with this OP must not be persisted.
+	// superseded by PresenceMsg, no longer written by the server
 	OnlineMsg
-	// OfflineMsg indicates the user is offline; a msg with this OP must not be persisted
+	// OfflineMsg indicates the user is offline; a msg with this OP must not be persisted.
+	// superseded by PresenceMsg, no longer written by the server
 	OfflineMsg
 	// TypingMsg indicates the user is typing; a msg with this OP must not be persisted
 	TypingMsg
@@ -37,8 +39,50 @@ const (
 	// not to be persisted, as we only want to send this for conversations' online users
 	// offline ones will fetch from the server, when the TUI starts
 	SyncConvosMsg
+	// ErrorMsg is written back by the server to the sender of a msg it has rejected, Errors holds the reasons
+	// not to be persisted
+	ErrorMsg
+	// SyncRequestMsg is written by the client to get every event after its last persisted cursor, see SyncRequest
+	SyncRequestMsg
+	// SyncDoneMsg is written by the server once all the events asked by SyncRequestMsg are streamed,
+	// its Cursor is the cursor of the last streamed event; not to be persisted
+	SyncDoneMsg
+	// DeliveredUpToMsg indicates the receiver has received every msg of the conversation sent up to UpTo
+	DeliveredUpToMsg
+	// ReadUpToMsg indicates the receiver has read every msg of the conversation sent up to UpTo
+	ReadUpToMsg
+	// PresenceMsg written by the client sets its own Presence, written by the server it is the settled Presence of
+	// the sender, SentAt being the last online time if offline; not to be persisted
+	PresenceMsg
+	// SubscribePresenceMsg is written by the client with the UserIDs whose PresenceMsg it wants to receive,
+	// replacing the previous ones; not to be persisted
+	SubscribePresenceMsg
+	// ProfileMsg is written by the server to the conversation partners of the sender once it updates its Profile;
+	// not to be persisted
+	ProfileMsg
+	// ScheduledSentMsg is appended by the server to the event log of the sender of a ScheduledMessage once it is
+	// released, carrying the msg it was sent as; the receiver gets it as a regular CreateMsg
+	ScheduledSentMsg
+	// DisappearingMsg is written by either user of a conversation to set its DisappearAfter for both of them
+	DisappearingMsg
+	// ExpiredMsg is never written over the connection, the client relays the msgs it has purged once their ExpiresAt
+	// has passed with it
+	ExpiredMsg
+	// PinMsg is written by either user of a conversation to pin the msg with its ID for both of them, at PinnedAt
+	PinMsg
+	// UnpinMsg is written by either user of a conversation to unpin the msg with its ID for both of them
+	UnpinMsg
+)
+
+// Bounds of the DisappearAfter of a conversation in seconds, zero turns the disappearing msgs off
+const (
+	MinDisappearAfter = 30
+	MaxDisappearAfter = 90 * 24 * 60 * 60
 )
 
+// MaxPresenceSubscriptions bounds the users a single SubscribePresenceMsg may subscribe to
+const MaxPresenceSubscriptions = 500
+
 var (
 	rgxUUID = regexp.MustCompile("^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-4[0-9a-fA-F]{3}-[89abAB][0-9a-fA-F]{3}-[0-9a-fA-F]{12}$")
 )
@@ -53,22 +97,106 @@ type Message struct {
 	ReadAt      *time.Time   `json:"read_at,omitempty"      db:"read_at"`
 	Version     int          `json:"-"`
 	Operation   MsgOperation `json:"operation"              db:"operation"`
+	// cursor of the event this msg is relayed as, zero for the msgs that are not part of the event log
+	Cursor int64 `json:"cursor,omitempty" db:"cursor"`
+	// only populated for ErrorMsg frames
+	Errors map[string]string `json:"errors,omitempty" db:"-"`
+	// watermark of DeliveredUpToMsg & ReadUpToMsg, the sent_at of the latest msg they apply to
+	UpTo *time.Time `json:"up_to,omitempty" db:"up_to"`
+	// only for PresenceMsg
+	Presence *PresenceStatus `json:"presence,omitempty" db:"-"`
+	// only for ProfileMsg
+	Profile *Profile `json:"profile,omitempty" db:"-"`
+	// set on the msgs of the conversations with disappearing msgs on, the msg is purged by both sides once passed
+	ExpiresAt *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	// only for DisappearingMsg, in seconds
+	DisappearAfter *int64 `json:"disappear_after,omitempty" db:"disappear_after"`
+	// set once the msg is pinned by either user, sent with PinMsg
+	PinnedAt *time.Time `json:"pinned_at,omitempty" db:"pinned_at"`
+	// set once the msg is starred by the current user, starring is private to the client & never sent
+	StarredAt *time.Time `json:"-" db:"-"`
+}
+
+// Parties returns the author & the recipient of the msg, rows with DeliveredMsg & ReadMsg Ops (and their watermarks)
+// are written by the recipient of the original msg, so for these Ops sender & receiver are swapped
+func (m *Message) Parties() (author, recipient string) {
+	switch m.Operation {
+	case DeliveredMsg, ReadMsg, DeliveredUpToMsg, ReadUpToMsg:
+		return m.ReceiverID, m.SenderID
+	default:
+		return m.SenderID, m.ReceiverID
+	}
+}
+
+// Snippets of MessageHit mark each matched term in between these, for the client to highlight
+const (
+	SnippetMatchStart = "\x02"
+	SnippetMatchEnd   = "\x03"
+)
+
+// MessageHit is a msg matching the local search of the client, Snippet is the part of its body around the matches
+type MessageHit struct {
+	*Message
+	Snippet string
+	// of the conversation partner, empty if the conversation is no longer there
+	Username string
 }
 
 type MsgChan chan *Message
 
+// MessageOwner is who a msg is between, kept after the msg is acknowledged & deleted from the server
+type MessageOwner struct {
+	ID         string `db:"id"`
+	SenderID   string `db:"sender_id"`
+	ReceiverID string `db:"receiver_id"`
+}
+
 type MessageService interface {
 	PopulateMessage(m MessageSent, sndr *User) *Message
 	ProcessSentMessages(ctx context.Context, m *Message) error
-	GetUnDeliveredMessages(ctx context.Context, c MsgChan) error
 	SaveMessage(ctx context.Context, m *Message) error
+	AuthorizeMessage(ctx context.Context, m *Message) error
+	AppendEvent(ctx context.Context, m *Message) error
+	AppendEventFor(ctx context.Context, usrID string, m *Message) error
+	StreamEventsSince(ctx context.Context, since int64, fn func(events []*Message) error) (int64, error)
+	// GetEventsSince returns at most limit events of the user in the context after the since cursor
+	GetEventsSince(ctx context.Context, since int64, limit int) ([]*Message, error)
+	AckEvents(ctx context.Context, cursor int64) error
+	PurgeExpiredMessages(ctx context.Context, until time.Time) (int64, error)
 }
 
 type MessageRepository interface {
 	GetByID(ctx context.Context, id string, op MsgOperation) (*Message, error)
-	GetUnDeliveredMessages(ctx context.Context, rcvrID string, op MsgOperation, c MsgChan) error
+	// ClaimOwner records o as who the msg with its id is between, unless it is recorded already, returns the record
+	ClaimOwner(ctx context.Context, o MessageOwner) (*MessageOwner, error)
+	GetOwner(ctx context.Context, id string) (*MessageOwner, error)
 	InsertMessage(ctx context.Context, m *Message) error
 	DeleteMessage(ctx context.Context, mID string) error
+	// DeleteCreatedUpTo deletes the CreateMsg rows from senderID to receiverID sent up to upTo
+	DeleteCreatedUpTo(ctx context.Context, senderID, receiverID string, upTo time.Time) (int64, error)
+	DeleteExpired(ctx context.Context, until time.Time) (int64, error)
+}
+
+// IsEvent reports whether msgs with this Op are appended to the event log of their receiver,
+// ScheduledSentMsg is the exception appended to the event log of its sender, see MessageService.AppendEventFor
+func (op MsgOperation) IsEvent() bool {
+	switch op {
+	case CreateMsg, DeliveredMsg, ReadMsg, DeleteMsg, DeliveredUpToMsg, ReadUpToMsg, DisappearingMsg, PinMsg, UnpinMsg:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsWatermark reports whether msgs with this Op apply to every msg of the conversation up to their UpTo,
+// instead of a single msg
+func (op MsgOperation) IsWatermark() bool {
+	return op == DeliveredUpToMsg || op == ReadUpToMsg
+}
+
+// HasGeneratedID reports whether msgs with this Op are given their ID by the server, instead of the client
+func (op MsgOperation) HasGeneratedID() bool {
+	return op == CreateMsg || op == DisappearingMsg || op.IsWatermark()
 }
 
 // DTO
@@ -81,4 +209,76 @@ type MessageSent struct {
 	DeliveredAt *time.Time   `json:"delivered_at"`
 	ReadAt      *time.Time   `json:"read_at"`
 	Operation   MsgOperation `json:"operation"`
+	// only for SyncRequestMsg, see SyncRequest
+	Since *int64 `json:"since"`
+	// only for DeliveredUpToMsg & ReadUpToMsg
+	UpTo *time.Time `json:"up_to"`
+	// only for PresenceMsg
+	Presence *PresenceStatus `json:"presence"`
+	// only for SubscribePresenceMsg
+	UserIDs []string `json:"userIDs"`
+	// only for DisappearingMsg
+	DisappearAfter *int64 `json:"disappear_after"`
+	// only for PinMsg
+	PinnedAt *time.Time `json:"pinned_at"`
+}
+
+func (m MessageSent) ValidateMessageSent() *ErrValidation {
+	ev := NewErrValidation()
+	// these Ops are not about a msg between two users
+	switch m.Operation {
+	case SyncRequestMsg:
+		ev.Evaluate(m.Since != nil, "since", "must be provided")
+		ev.Evaluate(m.Since == nil || *m.Since >= 0, "since", "must not be negative")
+		return ev
+	case PresenceMsg:
+		ev.Evaluate(m.Presence != nil, "presence", "must be provided")
+		ev.Evaluate(m.Presence == nil || (*m.Presence >= Online && *m.Presence <= DoNotDisturb),
+			"presence", "must be online, away or do not disturb")
+		return ev
+	case SubscribePresenceMsg:
+		ev.Evaluate(len(m.UserIDs) <= MaxPresenceSubscriptions, "userIDs", "must not be more than 500")
+		for _, id := range m.UserIDs {
+			if !rgxUUID.MatchString(id) {
+				ev.AddError("userIDs", "must be valid UUIDs")
+				break
+			}
+		}
+		return ev
+	}
+	ev.Evaluate(rgxUUID.MatchString(m.ReceiverID), "receiverID", "must be a valid UUID")
+	switch m.Operation {
+	case CreateMsg:
+		ev.Evaluate(m.Body != nil && *m.Body != "", "body", "must be provided")
+		ev.Evaluate(m.Body == nil || len(*m.Body) <= 4000, "body", "must be no more than 4000 bytes long")
+		ev.Evaluate(m.SentAt != nil, "sent_at", "must be provided")
+	case DeliveredMsg:
+		ev.Evaluate(m.DeliveredAt != nil, "delivered_at", "must be provided")
+	case ReadMsg:
+		ev.Evaluate(m.ReadAt != nil, "read_at", "must be provided")
+	case DeliveredUpToMsg:
+		ev.Evaluate(m.DeliveredAt != nil, "delivered_at", "must be provided")
+		ev.Evaluate(m.UpTo != nil, "up_to", "must be provided")
+	case ReadUpToMsg:
+		ev.Evaluate(m.ReadAt != nil, "read_at", "must be provided")
+		ev.Evaluate(m.UpTo != nil, "up_to", "must be provided")
+	case DisappearingMsg:
+		ev.Evaluate(m.DisappearAfter != nil, "disappear_after", "must be provided")
+		ev.Evaluate(m.DisappearAfter == nil || *m.DisappearAfter == 0 ||
+			(*m.DisappearAfter >= MinDisappearAfter && *m.DisappearAfter <= MaxDisappearAfter),
+			"disappear_after", "must be zero or between 30 seconds and 90 days")
+	case PinMsg:
+		ev.Evaluate(m.PinnedAt != nil, "pinned_at", "must be provided")
+	case DeliveredConfirmMsg, ReadConfirmMsg, DeleteMsg, DeleteConfirmMsg, TypingMsg, UnpinMsg:
+	default:
+		// OnlineMsg, OfflineMsg, SyncConvosMsg, ErrorMsg & SyncDoneMsg are only written by the server,
+		// ExpiredMsg is never written
+		ev.AddError("operation", "invalid operation")
+	}
+	if m.ID != nil {
+		ev.Evaluate(rgxUUID.MatchString(*m.ID), "id", "must be a valid UUID")
+	} else {
+		ev.Evaluate(m.Operation.HasGeneratedID(), "id", "must be provided")
+	}
+	return ev
 }
@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type PresenceStatus int
+
+const (
+	Offline PresenceStatus = iota
+	Online
+	Away
+	DoNotDisturb
+)
+
+func (s PresenceStatus) String() string {
+	switch s {
+	case Online:
+		return "online"
+	case Away:
+		return "away"
+	case DoNotDisturb:
+		return "do not disturb"
+	default:
+		return "offline"
+	}
+}
+
+// Presence of a user, LastOnline is only set while Offline
+type Presence struct {
+	UserID     string         `json:"userID"`
+	Status     PresenceStatus `json:"status"`
+	LastOnline *time.Time     `json:"lastOnline,omitempty"`
+}
+
+// PresenceChange is a settled transition of Presence, to be pushed to the subscribers in To
+type PresenceChange struct {
+	To       []string
+	Presence Presence
+}
+
+type PresenceService interface {
+	// RunPresence periodically persists the in-memory presences, it must be run in a separate goroutine,
+	// on return every connected user is persisted as offline
+	RunPresence(shtdwnCtx context.Context)
+	ConnectPresence(usrID string)
+	DisconnectPresence(usrID string)
+	// SetPresence sets the status chosen by a connected user, Offline cannot be chosen
+	SetPresence(usrID string, status PresenceStatus)
+	GetPresences(ctx context.Context, usrIDs ...string) ([]Presence, error)
+	// SubscribePresence replaces the users whose presence changes are pushed to the subscriber
+	SubscribePresence(subscriberID string, usrIDs []string)
+	UnsubscribePresence(subscriberID string)
+	PresenceChanges() <-chan PresenceChange
+}
+
+// PresenceSubscription is written by the client to subscribe to the presences of UserIDs, see SubscribePresenceMsg
+type PresenceSubscription struct {
+	Operation MsgOperation `json:"operation"`
+	UserIDs   []string     `json:"userIDs"`
+}
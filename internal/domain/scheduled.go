@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// MaxScheduleAhead bounds how far in the future a msg may be scheduled
+const MaxScheduleAhead = 365 * 24 * time.Hour
+
+// ScheduledMessage is a msg held by the server until SendAt, then released as a CreateMsg of its sender with the
+// same ID, through the same path as the msgs sent over the ws conn
+type ScheduledMessage struct {
+	ID         string    `json:"id"`
+	SenderID   string    `json:"senderID"   db:"sender_id"`
+	ReceiverID string    `json:"receiverID" db:"receiver_id"`
+	Body       string    `json:"body"`
+	SendAt     time.Time `json:"send_at"    db:"send_at"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	Version    int       `json:"version"`
+}
+
+// MessageSent is the msg sm is released as, sent at t
+func (sm *ScheduledMessage) MessageSent(t time.Time) MessageSent {
+	return MessageSent{
+		ID:         &sm.ID,
+		ReceiverID: sm.ReceiverID,
+		Body:       &sm.Body,
+		SentAt:     &t,
+		Operation:  CreateMsg,
+	}
+}
+
+type ScheduledMessageService interface {
+	ScheduleMessage(ctx context.Context, sm *ScheduledMessage) error
+	// GetScheduledMessages returns the pending msgs of the sender to receiverID, or to anyone if empty, due first
+	GetScheduledMessages(ctx context.Context, senderID, receiverID string) ([]*ScheduledMessage, error)
+	GetScheduledMessage(ctx context.Context, id, senderID string) (*ScheduledMessage, error)
+	UpdateScheduledMessage(ctx context.Context, sm *ScheduledMessage) error
+	CancelScheduledMessage(ctx context.Context, id, senderID string) error
+	GetDueScheduledMessages(ctx context.Context, limit int) ([]*ScheduledMessage, error)
+	// ClaimScheduledMessage removes sm for it to be released, false if it was edited or cancelled in the meantime
+	ClaimScheduledMessage(ctx context.Context, sm *ScheduledMessage) (bool, error)
+}
+
+type ScheduledMessageRepository interface {
+	Insert(ctx context.Context, sm *ScheduledMessage) error
+	GetByID(ctx context.Context, id, senderID string) (*ScheduledMessage, error)
+	GetBySender(ctx context.Context, senderID, receiverID string) ([]*ScheduledMessage, error)
+	// Update returns ErrEditConflict if the version of sm is not the persisted one
+	Update(ctx context.Context, sm *ScheduledMessage) error
+	Delete(ctx context.Context, id, senderID string) error
+	GetDue(ctx context.Context, until time.Time, limit int) ([]*ScheduledMessage, error)
+	// DeleteVersion deletes sm only if its version is still the persisted one
+	DeleteVersion(ctx context.Context, id string, version int) (bool, error)
+}
+
+// DTOs
+
+type ScheduledMessageInput struct {
+	ReceiverID *string    `json:"receiverID"`
+	Body       *string    `json:"body"`
+	SendAt     *time.Time `json:"send_at"`
+	// only for an update, the one the client has edited
+	Version *int `json:"version"`
+}
+
+// ValidateScheduledMessageInput validates the input to schedule a msg, or to update one if update, where the nil
+// fields are left as is
+func (i ScheduledMessageInput) ValidateScheduledMessageInput(update bool) *ErrValidation {
+	ev := NewErrValidation()
+	if update {
+		ev.Evaluate(i.ReceiverID == nil, "receiverID", "must not be changed")
+		ev.Evaluate(i.Version != nil, "version", "must be provided")
+	} else {
+		ev.Evaluate(i.ReceiverID != nil && rgxUUID.MatchString(*i.ReceiverID), "receiverID", "must be a valid UUID")
+		ev.Evaluate(i.Body != nil, "body", "must be provided")
+		ev.Evaluate(i.SendAt != nil, "send_at", "must be provided")
+	}
+	if i.Body != nil {
+		ev.Evaluate(*i.Body != "", "body", "must be provided")
+		ev.Evaluate(len(*i.Body) <= 4000, "body", "must be no more than 4000 bytes long")
+	}
+	if i.SendAt != nil {
+		ev.Evaluate(i.SendAt.After(time.Now()), "send_at", "must be in the future")
+		ev.Evaluate(time.Until(*i.SendAt) <= MaxScheduleAhead, "send_at", "must be within a year")
+	}
+	return ev
+}
@@ -9,8 +9,10 @@ import (
 const (
 	ScopeActivation        = "activation"
 	ScopeAuthentication    = "authentication"
+	ScopeBot               = "bot" // the api keys of the bots, long-lived until rotated by their owner
 	ScopeActivationTTL     = 15 * time.Minute
 	ScopeAuthenticationTTL = 7 * 24 * time.Hour
+	ScopeBotTTL            = 10 * 365 * 24 * time.Hour
 )
 
 var (
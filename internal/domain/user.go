@@ -2,24 +2,44 @@ package domain
 
 import (
 	"context"
+	"github.com/rivo/uniseg"
 	"regexp"
+	"slices"
+	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 var (
+	// starts with a letter, lowercase as handles are case-insensitive, see NormalizeHandle
+	RgxHandle     = regexp.MustCompile("^[a-z][a-z0-9_]{2,19}$")
 	RgxEmail      = regexp.MustCompile("^[a-zA-Z0-9.!#$%&'*+/=?^_`{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$")
 	AnonymousUser = &User{}
+	// handles no user may choose, as they are mistaken for the application or the routes
+	ReservedHandles = []string{
+		"admin", "administrator", "api", "activate", "bot", "current", "everyone", "help", "here", "letschat",
+		"me", "moderator", "null", "root", "staff", "support", "system", "undefined",
+	}
 )
 
 type User struct {
 	ID         string     `json:"id"`
 	Name       string     `json:"name"`
+	Handle     *string    `json:"handle,omitempty" db:"handle"` // nil until chosen, without the leading @
 	Email      string     `json:"email"`
 	Password   []byte     `json:"-"`
 	Activated  bool       `json:"-"`
 	LastOnline *time.Time `json:"lastOnline,omitempty" db:"last_online"`
 	CreatedAt  time.Time  `json:"createdAt"  db:"created_at"`
 	Version    int        `json:"-"`
+	// Profile related, the status is cleared once expired, see ClearExpiredStatus
+	StatusText      string     `json:"statusText"                db:"status_text"`
+	StatusEmoji     string     `json:"statusEmoji"               db:"status_emoji"`
+	StatusExpiresAt *time.Time `json:"statusExpiresAt,omitempty" db:"status_expires_at"`
+	Bio             string     `json:"bio"                       db:"bio"`
+	// Bot related, a bot is owned by the human user BotOwnerID, see BotRegister
+	IsBot      bool    `json:"isBot"                db:"is_bot"`
+	BotOwnerID *string `json:"botOwnerID,omitempty" db:"bot_owner_id"`
 	// Websocket related
 	Messages  MsgChan `json:"-"`
 	CloseSlow func()  `json:"-"`
@@ -29,13 +49,19 @@ type UserService interface {
 	RegisterUser(ctx context.Context, u *UserRegister) (string, error)
 	ExistsUser(ctx context.Context, email string) (bool, error)
 	GetByUniqueField(ctx context.Context, fieldValue string) (*User, error)
+	// GetByHandle resolves the current handle of a user, or a previous one until its redirect expires,
+	// in that case redirected is true
+	GetByHandle(ctx context.Context, handle string) (u *User, redirected bool, err error)
 	UpdateUser(ctx context.Context, u *UserUpdate) error
-	UpdateUserOnlineStatus(ctx context.Context, usr *User, online bool) error
 	GetForToken(ctx context.Context, scope string, plainToken string) (*User, error)
 	ActivateUser(ctx context.Context, user *User) error
 	AuthenticateUser(ctx context.Context, u *UserAuth) (string, error)
-	GetByQuery(ctx context.Context, queryParam string, filter Filter) ([]*User, *Metadata, error)
+	// GetByQuery ranks the users on name, handle & email prefix together for the searching user usrID
+	GetByQuery(ctx context.Context, usrID, queryParam string, filter CursorFilter) ([]*User, *CursorMetadata, error)
 	SetOnlineUsersLastSeen(ctx context.Context, t time.Time) error
+	// RegisterBot registers an activated bot owned by the user ownerID, returns its id
+	RegisterBot(ctx context.Context, ownerID string, b *BotRegister) (string, error)
+	GetBots(ctx context.Context, ownerID string) ([]*User, error)
 }
 
 type UserRepository interface {
@@ -45,14 +71,31 @@ type UserRepository interface {
 	UpdateUser(ctx context.Context, u *User) error
 	GetForToken(ctx context.Context, scope string, hash []byte) (*User, error)
 	ActivateUser(ctx context.Context, user *User) error
-	GetByQuery(ctx context.Context, paramName string, paramValue string, filter Filter) ([]*User, *Metadata, error)
+	// GetByQuery excludes usrID itself, boosting its conversation partners
+	GetByQuery(ctx context.Context, usrID, queryParam string, filter CursorFilter) ([]*User, *CursorMetadata, error)
 	SetOnlineUsersLastSeen(ctx context.Context, t time.Time) error
+	// SetLastOnline sets last_online without a version bump, nil meaning online
+	SetLastOnline(ctx context.Context, usrID string, t *time.Time) error
+	GetLastOnline(ctx context.Context, usrIDs []string) (map[string]*time.Time, error)
+	// GetByHandleRedirect returns the user a previous handle redirects to, if not expired
+	GetByHandleRedirect(ctx context.Context, handle string) (*User, error)
+	// HandleTaken reports whether the handle is the one of another user than exceptUsrID, or redirects to one
+	HandleTaken(ctx context.Context, handle, exceptUsrID string) (bool, error)
+	// AddHandleRedirect redirects the previous handle to the user until expiresAt
+	AddHandleRedirect(ctx context.Context, handle, usrID string, expiresAt time.Time) error
+	// DeleteHandleRedirect is called once the user takes its previous handle back
+	DeleteHandleRedirect(ctx context.Context, handle string) error
+	// RegisterBot inserts u activated, owned by its BotOwnerID
+	RegisterBot(ctx context.Context, u *User) (string, error)
+	GetBots(ctx context.Context, ownerID string) ([]*User, error)
 }
 
 // DTOs
 
 type UserRegister struct {
-	Name     string `json:"name"`
+	Name string `json:"name"`
+	// optional, may be chosen later with UserUpdate
+	Handle   string `json:"handle"`
 	Email    string `json:"email"`
 	Password string `json:"password"`
 }
@@ -65,21 +108,84 @@ type UserAuth struct {
 type UserUpdate struct {
 	ID              string  `json:"id"`
 	Name            string  `json:"name"`
+	Handle          *string `json:"handle"`
 	Email           string  `json:"email"`
 	NewPassword     *string `json:"newPassword"`
 	CurrentPassword *string `json:"currentPassword"`
+	// nil fields are left as is, setting any of StatusText & StatusEmoji also sets StatusExpiresAt, nil meaning never
+	StatusText      *string    `json:"statusText"`
+	StatusEmoji     *string    `json:"statusEmoji"`
+	StatusExpiresAt *time.Time `json:"statusExpiresAt"`
+	Bio             *string    `json:"bio"`
+}
+
+// Profile is the public part of User, pushed to the conversation partners with ProfileMsg
+type Profile struct {
+	Name            string     `json:"name"`
+	Handle          *string    `json:"handle,omitempty"`
+	Email           string     `json:"email"`
+	StatusText      string     `json:"statusText"`
+	StatusEmoji     string     `json:"statusEmoji"`
+	StatusExpiresAt *time.Time `json:"statusExpiresAt,omitempty"`
+	Bio             string     `json:"bio"`
 }
 
 func (u *User) IsAnonymousUser() bool {
 	return u == AnonymousUser
 }
 
+// ClearExpiredStatus clears the status once its StatusExpiresAt has passed
+func (u *User) ClearExpiredStatus() {
+	if StatusExpired(u.StatusExpiresAt) {
+		u.StatusText, u.StatusEmoji, u.StatusExpiresAt = "", "", nil
+	}
+}
+
+func (u *User) Profile() Profile {
+	return Profile{
+		Name:            u.Name,
+		Handle:          u.Handle,
+		Email:           u.Email,
+		StatusText:      u.StatusText,
+		StatusEmoji:     u.StatusEmoji,
+		StatusExpiresAt: u.StatusExpiresAt,
+		Bio:             u.Bio,
+	}
+}
+
+func StatusExpired(expiresAt *time.Time) bool {
+	return expiresAt != nil && !expiresAt.After(time.Now())
+}
+
+func ValidateStatus(text, emoji string, expiresAt *time.Time, ev *ErrValidation) {
+	ev.Evaluate(utf8.RuneCountInString(text) <= 80, "statusText", "must be no more than 80 characters long")
+	ev.Evaluate(emoji == "" || isEmoji(emoji), "statusEmoji", "must be a single emoji")
+	ev.Evaluate(!StatusExpired(expiresAt), "statusExpiresAt", "must be in the future")
+}
+
+func ValidateBio(bio string, ev *ErrValidation) {
+	ev.Evaluate(utf8.RuneCountInString(bio) <= 160, "bio", "must be no more than 160 characters long")
+}
+
 func ValidateName(name string, ev *ErrValidation) {
 	ev.Evaluate(name != "", "name", "must be provided")
 	ev.Evaluate(len(name) >= 3, "name", "must be 3 bytes long")
 	ev.Evaluate(len(name) <= 30, "name", "must be no more than 30 bytes long")
 }
 
+// NormalizeHandle trims the leading @ & lowercases the handle, they are case-insensitive
+func NormalizeHandle(handle string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(handle), "@"))
+}
+
+// ValidateHandle validates a handle normalized by NormalizeHandle
+func ValidateHandle(handle string, ev *ErrValidation) {
+	ev.Evaluate(handle != "", "handle", "must be provided")
+	ev.Evaluate(handle == "" || RgxHandle.MatchString(handle), "handle",
+		"must be 3-20 letters, digits or underscores, starting with a letter")
+	ev.Evaluate(!slices.Contains(ReservedHandles, handle), "handle", "is reserved")
+}
+
 func ValidateEmail(email string, ev *ErrValidation) {
 	ev.Evaluate(email != "", "email", "must be provided")
 	if len(email) > 254 || !RgxEmail.MatchString(email) {
@@ -98,3 +204,44 @@ func ValidPlainPasswordWithKey(pass string, ev *ErrValidation, errKey string) {
 	ev.Evaluate(pass == "" || len(pass) >= 8, errKey, "must be at least 8 bytes long")
 	ev.Evaluate(len(pass) <= 72, errKey, "must no be more than 72 bytes long")
 }
+
+// Helpers & Stuff ----------------------------------------------------------------------------------------------------
+
+// isEmoji reports whether s is a single grapheme cluster of emoji code points, e.g. a flag, a keycap, a skin tone or
+// a ZWJ sequence like 👩‍💻
+func isEmoji(s string) bool {
+	cluster, rest, _, _ := uniseg.FirstGraphemeClusterInString(s, -1)
+	if cluster == "" || rest != "" {
+		return false
+	}
+	runes := []rune(cluster)
+	// a keycap, e.g. 1️⃣, is a digit, # or * followed by the keycap mark
+	if strings.ContainsRune("0123456789#*", runes[0]) {
+		return slices.Contains(runes, '\u20E3')
+	}
+	for i, r := range runes {
+		if !isPictographic(r) && (i == 0 || !isEmojiComponent(r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// isPictographic covers the blocks of the emojis, & the older symbols presented as ones
+func isPictographic(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF: // the emoji blocks, the flags & the skin tones are in it too
+	case r >= 0x2190 && r <= 0x21FF, r >= 0x2300 && r <= 0x23FF, r >= 0x2600 && r <= 0x27BF, r >= 0x2B00 && r <= 0x2BFF:
+	case r == 0x00A9, r == 0x00AE, r == 0x203C, r == 0x2049, r == 0x2122, r == 0x2139, r == 0x24C2,
+		r == 0x25AA, r == 0x25AB, r == 0x25B6, r == 0x25C0, r >= 0x25FB && r <= 0x25FE,
+		r == 0x2934, r == 0x2935, r == 0x3030, r == 0x303D, r == 0x3297, r == 0x3299:
+	default:
+		return false
+	}
+	return true
+}
+
+// isEmojiComponent covers the code points joining or modifying the pictographs of a cluster
+func isEmojiComponent(r rune) bool {
+	return r == 0x200D || r == 0xFE0E || r == 0xFE0F || r == 0x20E3 || (r >= 0xE0020 && r <= 0xE007F)
+}
@@ -0,0 +1,19 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Watermark holds how far a user has received & read the msgs of its conversation with the peer
+type Watermark struct {
+	UserID        string     `json:"userID"          db:"user_id"`
+	PeerID        string     `json:"peerID"          db:"peer_id"`
+	DeliveredUpTo *time.Time `json:"delivered_up_to" db:"delivered_up_to"`
+	ReadUpTo      *time.Time `json:"read_up_to"      db:"read_up_to"`
+}
+
+type WatermarkRepository interface {
+	// Advance moves the watermark of the sender of m with its receiver forward to m.UpTo, never backwards
+	Advance(ctx context.Context, m *Message) error
+}
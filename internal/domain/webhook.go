@@ -0,0 +1,146 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"slices"
+	"time"
+)
+
+const (
+	WebhookEventCreate   = "create"
+	WebhookEventRead     = "read"
+	WebhookEventDelete   = "delete"
+	WebhookEventPresence = "presence"
+	// MaxWebhooksPerUser bounds the webhooks a single user or bot may subscribe
+	MaxWebhooksPerUser = 10
+	// MaxWebhookDeliveries bounds the delivery log returned at once, newest first
+	MaxWebhookDeliveries = 100
+)
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	// WebhookDeliveryFailed is a delivery out of attempts, it is kept in the log only
+	WebhookDeliveryFailed = "failed"
+)
+
+var WebhookEvents = []string{WebhookEventCreate, WebhookEventRead, WebhookEventDelete, WebhookEventPresence}
+
+// Webhook is subscribed by a user or a bot, the events it is subscribed to are POSTed to its URL as a
+// WebhookPayload, signed with its Secret
+type Webhook struct {
+	ID     string   `json:"id"`
+	UserID string   `json:"userID"           db:"user_id"`
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	// only ever returned once created
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"createdAt"        db:"created_at"`
+}
+
+// WebhookDelivery is a WebhookPayload queued for a Webhook, retried with an exponential backoff until delivered or
+// out of attempts, the log of the deliveries is kept for a while either way
+type WebhookDelivery struct {
+	ID             int64           `json:"id"`
+	WebhookID      string          `json:"webhookID"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"nextAttemptAt"`
+	ResponseStatus *int            `json:"responseStatus,omitempty"`
+	LastError      string          `json:"lastError,omitempty"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	DeliveredAt    *time.Time      `json:"deliveredAt,omitempty"`
+	// of the webhook, only for the delivery worker
+	URL    string `json:"-"`
+	Secret string `json:"-"`
+}
+
+// WebhookPayload is the body POSTed to the webhooks, with either the Message or the Presence of the event
+type WebhookPayload struct {
+	Event     string    `json:"event"`
+	CreatedAt time.Time `json:"createdAt"`
+	Message   *Message  `json:"message,omitempty"`
+	Presence  *Presence `json:"presence,omitempty"`
+}
+
+type WebhookService interface {
+	CreateWebhook(ctx context.Context, w *Webhook) error
+	GetWebhooks(ctx context.Context, usrID string) ([]*Webhook, error)
+	GetWebhook(ctx context.Context, id, usrID string) (*Webhook, error)
+	DeleteWebhook(ctx context.Context, id, usrID string) error
+	// EnqueueWebhookDeliveries queues p to the webhooks of usrID subscribed to its event
+	EnqueueWebhookDeliveries(ctx context.Context, usrID string, p WebhookPayload) error
+	// EnqueuePartnerWebhookDeliveries queues p to the webhooks subscribed to its event, of the users having a
+	// conversation with usrID
+	EnqueuePartnerWebhookDeliveries(ctx context.Context, usrID string, p WebhookPayload) error
+	GetWebhookDeliveries(ctx context.Context, webhookID string, limit int) ([]*WebhookDelivery, error)
+	// ClaimDueWebhookDeliveries leases the due deliveries to the caller, a delivery not completed within the lease
+	// is due again
+	ClaimDueWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*WebhookDelivery, error)
+	// CompleteWebhookDelivery records the attempt of d, responseStatus is zero if there was no response,
+	// a failed attempt is retried after the backoff, until out of attempts
+	CompleteWebhookDelivery(ctx context.Context, d *WebhookDelivery, responseStatus int, err error) error
+	// PurgeWebhookDeliveries deletes the delivered & failed deliveries created before until
+	PurgeWebhookDeliveries(ctx context.Context, until time.Time) (int64, error)
+}
+
+type WebhookRepository interface {
+	Insert(ctx context.Context, w *Webhook) error
+	GetByUser(ctx context.Context, usrID string) ([]*Webhook, error)
+	GetByID(ctx context.Context, id, usrID string) (*Webhook, error)
+	Delete(ctx context.Context, id, usrID string) error
+	EnqueueDeliveries(ctx context.Context, usrID, event string, payload []byte) error
+	EnqueuePartnerDeliveries(ctx context.Context, usrID, event string, payload []byte) error
+	GetDeliveries(ctx context.Context, webhookID string, limit int) ([]*WebhookDelivery, error)
+	ClaimDueDeliveries(ctx context.Context, now, leasedUntil time.Time, limit int) ([]*WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, d *WebhookDelivery) error
+	DeleteDeliveriesUntil(ctx context.Context, until time.Time) (int64, error)
+}
+
+// WebhookEvent is the webhook event msgs with this Op are delivered as, empty if they are not
+func (op MsgOperation) WebhookEvent() string {
+	switch op {
+	case CreateMsg:
+		return WebhookEventCreate
+	case ReadMsg, ReadUpToMsg:
+		return WebhookEventRead
+	case DeleteMsg:
+		return WebhookEventDelete
+	default:
+		return ""
+	}
+}
+
+// DTOs
+
+type WebhookInput struct {
+	URL    *string  `json:"url"`
+	Events []string `json:"events"`
+}
+
+func (i WebhookInput) ValidateWebhookInput() *ErrValidation {
+	ev := NewErrValidation()
+	ev.Evaluate(i.URL != nil && *i.URL != "", "url", "must be provided")
+	if i.URL != nil && *i.URL != "" {
+		u, err := url.Parse(*i.URL)
+		ev.Evaluate(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+			"url", "must be an absolute http or https url")
+		ev.Evaluate(len(*i.URL) <= 2048, "url", "must be no more than 2048 bytes long")
+	}
+	ev.Evaluate(len(i.Events) > 0, "events", "must be provided")
+	for j, e := range i.Events {
+		if !slices.Contains(WebhookEvents, e) {
+			ev.AddError("events", "must be create, read, delete or presence")
+			break
+		}
+		if slices.Contains(i.Events[:j], e) {
+			ev.AddError("events", "must not contain duplicates")
+			break
+		}
+	}
+	return ev
+}
@@ -0,0 +1,9 @@
+package domain
+
+// GoingAway is the json reason of the close frame with websocket.StatusGoingAway, written to every subscriber
+// while the server shuts down, a close reason is limited to 123 bytes, so it must be kept short
+type GoingAway struct {
+	Reason string `json:"reason"`
+	// suggested delay in milliseconds before reconnecting, jitter is added by the client
+	ReconnectAfter int64 `json:"reconnectAfterMs"`
+}
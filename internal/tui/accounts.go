@@ -0,0 +1,216 @@
+package tui
+
+import (
+	"fmt"
+	"github.com/MuhamedUsman/letschat/internal/client"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	zone "github.com/lrstanley/bubblezone"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AccountsModel is the overlay switching between the accounts, each with the count of its unread msgs, or adding one
+// on any of the profiles, the accounts other than the active one keep running in the background
+type AccountsModel struct {
+	accounts *client.Accounts
+	clients  []*client.Client
+	unread   []int64
+	// over the clients, then the profiles to add an account on
+	cursor int
+	show   bool
+	// the counts of a TabContainerModel built before switching accounts are dropped, see accountsUnreadMsg
+	id int
+}
+
+// accountsUnreadMsg carries the counts of the unread msgs of the clients, by their index, the next counts are
+// scheduled if tick
+type accountsUnreadMsg struct {
+	id      int
+	clients []*client.Client
+	unread  []int64
+	tick    bool
+}
+
+// switchAccountMsg makes the TabContainerModel show the account of c
+type switchAccountMsg struct{ c *client.Client }
+
+var lastAccountsModelID int
+
+func NewAccountsModel(accounts *client.Accounts) AccountsModel {
+	lastAccountsModelID++
+	return AccountsModel{accounts: accounts, clients: accounts.List(), id: lastAccountsModelID}
+}
+
+func (m AccountsModel) Init() tea.Cmd {
+	return m.countUnread(0, true)
+}
+
+func (m AccountsModel) Update(msg tea.Msg) (AccountsModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case accountsUnreadMsg:
+		if msg.id != m.id {
+			return m, nil
+		}
+		m.clients, m.unread = msg.clients, msg.unread
+		m.cursor = min(m.cursor, m.entries()-1)
+		if msg.tick {
+			return m, m.countUnread(5*time.Second, true)
+		}
+
+	case tea.KeyMsg:
+		if !m.show {
+			return m, nil
+		}
+		switch {
+		case msg.String() == "up", msg.String() == "k":
+			m.cursor = max(0, m.cursor-1)
+		case msg.String() == "down", msg.String() == "j":
+			m.cursor = min(m.entries()-1, m.cursor+1)
+		case msg.String() == "enter":
+			return m, m.selectEntry()
+		case key.Matches(msg, keys.Accounts.Remove):
+			return m, m.removeAccount()
+		}
+
+	case tea.MouseMsg:
+		if !m.show || msg.Button != tea.MouseButtonLeft || msg.Action != tea.MouseActionRelease {
+			return m, nil
+		}
+		for i := range m.entries() {
+			if zone.Get(accountZoneID(i)).InBounds(msg) {
+				m.cursor = i
+				return m, m.selectEntry()
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m AccountsModel) View() string {
+	active := m.accounts.Active()
+	var sb strings.Builder
+	sb.WriteString(helpTitleStyle.Render("👥 ACCOUNTS"))
+	sb.WriteString("\n")
+	for i, c := range m.clients {
+		line := renderAccount(c, i, c == active, m.cursor == i)
+		if i < len(m.unread) && m.unread[i] > 0 {
+			line += accountUnreadStyle.Render(fmt.Sprintf("%d⁕", m.unread[i]))
+		}
+		sb.WriteString(zone.Mark(accountZoneID(i), line))
+		sb.WriteString("\n")
+	}
+	for i, p := range m.accounts.Profiles() {
+		i += len(m.clients)
+		s := accountAddStyle
+		if m.cursor == i {
+			s = accountSelStyle
+		}
+		sb.WriteString(zone.Mark(accountZoneID(i), s.Render("+ ADD AN ACCOUNT ON "+p)))
+		sb.WriteString("\n")
+	}
+	rm := keysHelp(keys.Accounts.Remove)
+	footer := fmt.Sprintf("↑/↓ to move, ENTER to switch or add, %v to remove, ESC to close", rm)
+	sb.WriteString(helpFooterStyle.Render(footer))
+	return lipgloss.PlaceHorizontal(terminalWidth-2, lipgloss.Center, sb.String())
+}
+
+func (m *AccountsModel) toggle() {
+	m.show = !m.show
+	m.cursor = max(0, slices.Index(m.clients, m.accounts.Active()))
+}
+
+// othersUnread returns the count of the unread msgs of the accounts other than the active one
+func (m AccountsModel) othersUnread() int64 {
+	var n int64
+	active := m.accounts.Active()
+	for i, c := range m.clients {
+		if c != active && i < len(m.unread) {
+			n += m.unread[i]
+		}
+	}
+	return n
+}
+
+// Helpers & Stuff -----------------------------------------------------------------------------------------------------
+
+func renderAccount(c *client.Client, i int, active, sel bool) string {
+	s := accountStyle
+	if sel {
+		s = accountSelStyle
+	}
+	name, meta := "logged out", c.Profile.Name
+	if u := c.CurrentUsr; u != nil {
+		name, meta = u.Name, u.Email+" · "+c.Profile.Name
+	}
+	if active {
+		meta += " · active"
+	}
+	return s.Render(strconv.Itoa(i+1)+". "+name) + accountMetaStyle.Render(meta)
+}
+
+func accountZoneID(i int) string {
+	return "account" + strconv.Itoa(i)
+}
+
+// entries is the count of the accounts & of the profiles to add an account on
+func (m AccountsModel) entries() int {
+	return len(m.clients) + len(m.accounts.Profiles())
+}
+
+// selectEntry switches to the account under the cursor, or adds one on the profile under it
+func (m *AccountsModel) selectEntry() tea.Cmd {
+	if m.cursor < len(m.clients) {
+		c := m.clients[m.cursor]
+		if c == m.accounts.Active() {
+			return nil
+		}
+		m.show = false
+		return func() tea.Msg { return switchAccountMsg{c} }
+	}
+	p := m.accounts.Profiles()[m.cursor-len(m.clients)]
+	m.show = false
+	return func() tea.Msg {
+		c, err := m.accounts.Add(p)
+		if err != nil {
+			return &errMsg{err: err.Error()}
+		}
+		return switchAccountMsg{c}
+	}
+}
+
+func (m *AccountsModel) removeAccount() tea.Cmd {
+	if m.cursor >= len(m.clients) || m.clients[m.cursor] == m.accounts.Active() {
+		return nil
+	}
+	c := m.clients[m.cursor]
+	return tea.Sequence(
+		func() tea.Msg {
+			if err := m.accounts.Remove(c); err != nil {
+				return &errMsg{err: err.Error()}
+			}
+			return nil
+		},
+		m.countUnread(0, false),
+	)
+}
+
+// countUnread counts the unread msgs of each account after d
+func (m AccountsModel) countUnread(d time.Duration, tick bool) tea.Cmd {
+	id, accounts := m.id, m.accounts
+	count := func() tea.Msg {
+		clients := accounts.List()
+		unread := make([]int64, len(clients))
+		for i, c := range clients {
+			unread[i] = c.UnreadMsgsCount()
+		}
+		return accountsUnreadMsg{id: id, clients: clients, unread: unread, tick: tick}
+	}
+	if d == 0 {
+		return count
+	}
+	return tea.Tick(d, func(time.Time) tea.Msg { return count() })
+}
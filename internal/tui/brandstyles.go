@@ -6,6 +6,9 @@ import (
 
 // Every width calculation going to be experimental (visually) to some extent
 
+// the colors & the styles built from them are set by applyTheme, once on startup & each time the theme is switched,
+// so each section below declares its styles & builds them in its style func
+
 var ( // Global Styling
 
 	// These will be updated by any of the activeTab TabContainerModel
@@ -13,57 +16,38 @@ var ( // Global Styling
 	terminalHeight int
 	terminalFocus  *bool // only read the msg once the terminal in focus
 
-	primaryColor           = lipgloss.AdaptiveColor{Light: "#4b3b00", Dark: "#FFC700"}
-	primarySubtleDarkColor = lipgloss.AdaptiveColor{Light: "#6c5300", Dark: "#8b7000"}
-	primaryContrastColor   = lipgloss.AdaptiveColor{Light: "#FFC700", Dark: "#4b3b00"}
-	dangerColor            = lipgloss.AdaptiveColor{Light: "#ff7b4e", Dark: "#FF5C00"}
-	dangerDarkColor        = lipgloss.AdaptiveColor{Light: "#b65d3e", Dark: "#a34a00"}
-	whiteColor             = lipgloss.AdaptiveColor{Light: "#202020", Dark: "#E5D6A8"}
-	blackColor             = lipgloss.AdaptiveColor{Light: "#E5D6A8", Dark: "#202020"}
-	darkGreyColor          = lipgloss.AdaptiveColor{Light: "#808080", Dark: "#404040"}
-	lightGreyColor         = lipgloss.AdaptiveColor{Light: "#404040", Dark: "#afafaf"}
-	redColor               = lipgloss.AdaptiveColor{Light: "#FF0000", Dark: "#FF0000"}
-	orangeColor            = lipgloss.AdaptiveColor{Light: "#ffa000", Dark: "#ffa000"}
-	greenColor             = lipgloss.AdaptiveColor{Light: "#00a300", Dark: "#00ff00"}
+	primaryColor           lipgloss.AdaptiveColor
+	primarySubtleDarkColor lipgloss.AdaptiveColor
+	primaryContrastColor   lipgloss.AdaptiveColor
+	dangerColor            lipgloss.AdaptiveColor
+	dangerDarkColor        lipgloss.AdaptiveColor
+	whiteColor             lipgloss.AdaptiveColor
+	blackColor             lipgloss.AdaptiveColor
+	darkGreyColor          lipgloss.AdaptiveColor
+	lightGreyColor         lipgloss.AdaptiveColor
+	redColor               lipgloss.AdaptiveColor
+	orangeColor            lipgloss.AdaptiveColor
+	greenColor             lipgloss.AdaptiveColor
+
+	letschatLogo string
+)
 
+func styleGlobal() {
 	letschatLogo = lipgloss.NewStyle().
-			Border(lipgloss.InnerHalfBlockBorder(), true).
-			BorderForeground(primaryColor).
-			Background(primaryColor).
-			Foreground(primaryContrastColor).
-			Width(10).
-			MarginBottom(2).
-			Align(lipgloss.Center).
-			Italic(true).
-			Render("Letschat")
-)
+		Border(lipgloss.InnerHalfBlockBorder(), true).
+		BorderForeground(primaryColor).
+		Background(primaryColor).
+		Foreground(primaryContrastColor).
+		Width(10).
+		MarginBottom(2).
+		Align(lipgloss.Center).
+		Italic(true).
+		Render("Letschat")
+}
 
 var ( // Form Styling
 
-	inputStyle = lipgloss.NewStyle().
-			Border(lipgloss.NormalBorder(), false, false, true, false).
-			BorderForeground(darkGreyColor).
-			Foreground(darkGreyColor).
-			Padding(0, 2, 0, 3).
-			Margin(1, 0, 1, 0).
-			Align(lipgloss.Center)
-	activeInputStyle = inputStyle.
-				Border(lipgloss.ThickBorder(), false, false, true, false).
-				BorderForeground(primaryColor).
-				Foreground(primaryColor)
-
-	btnInputStyle = inputStyle.
-			Border(lipgloss.HiddenBorder()).
-			MarginBottom(0)
-	activeBtnInputStyle = btnInputStyle.
-				Foreground(primaryContrastColor)
-
-	buttonStyle = lipgloss.NewStyle().
-			Background(darkGreyColor).
-			Foreground(whiteColor).
-			Width(10).
-			Align(lipgloss.Center).
-			Inline(true)
+	inputStyle, activeInputStyle, btnInputStyle, activeBtnInputStyle, buttonStyle lipgloss.Style
 
 	activeButtonStyleWithColor = func(foreground, background lipgloss.AdaptiveColor) lipgloss.Style {
 		return buttonStyle.
@@ -71,13 +55,8 @@ var ( // Form Styling
 			Background(background)
 	}
 
-	formContainer = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder(), true).
-			BorderForeground(primaryColor).
-			Width(70).
-			Height(25).
-			Align(lipgloss.Center).
-			AlignVertical(lipgloss.Center)
+	formContainer lipgloss.Style
+
 	formContainerCentered = func(content string) string {
 		return lipgloss.Place(terminalWidth, terminalHeight,
 			lipgloss.Center, lipgloss.Center,
@@ -86,27 +65,61 @@ var ( // Form Styling
 			lipgloss.WithWhitespaceForeground(darkGreyColor))
 	}
 
+	infoTxtStyle, otpInputStyle lipgloss.Style
+)
+
+func styleForms() {
+	inputStyle = lipgloss.NewStyle().
+		Border(lipgloss.NormalBorder(), false, false, true, false).
+		BorderForeground(darkGreyColor).
+		Foreground(darkGreyColor).
+		Padding(0, 2, 0, 3).
+		Margin(1, 0, 1, 0).
+		Align(lipgloss.Center)
+	activeInputStyle = inputStyle.
+		Border(lipgloss.ThickBorder(), false, false, true, false).
+		BorderForeground(primaryColor).
+		Foreground(primaryColor)
+
+	btnInputStyle = inputStyle.
+		Border(lipgloss.HiddenBorder()).
+		MarginBottom(0)
+	activeBtnInputStyle = btnInputStyle.
+		Foreground(primaryContrastColor)
+
+	buttonStyle = lipgloss.NewStyle().
+		Background(darkGreyColor).
+		Foreground(whiteColor).
+		Width(10).
+		Align(lipgloss.Center).
+		Inline(true)
+
+	formContainer = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder(), true).
+		BorderForeground(primaryColor).
+		Width(70).
+		Height(25).
+		Align(lipgloss.Center).
+		AlignVertical(lipgloss.Center)
+
 	infoTxtStyle = lipgloss.NewStyle().
-			Margin(1, 0, 2, 0).
-			Padding(0, 1, 0, 1).
-			AlignHorizontal(lipgloss.Center).
-			Foreground(whiteColor)
+		Margin(1, 0, 2, 0).
+		Padding(0, 1, 0, 1).
+		AlignHorizontal(lipgloss.Center).
+		Foreground(whiteColor)
 
 	otpInputStyle = lipgloss.NewStyle().
-			Border(lipgloss.ThickBorder(), false, false, true, false).
-			BorderForeground(darkGreyColor).
-			Padding(0, 1, 0, 1).
-			Margin(1, 0, 1, 0).
-			Width(10).
-			Align(lipgloss.Center)
-)
+		Border(lipgloss.ThickBorder(), false, false, true, false).
+		BorderForeground(darkGreyColor).
+		Padding(0, 1, 0, 1).
+		Margin(1, 0, 1, 0).
+		Width(10).
+		Align(lipgloss.Center)
+}
 
 var ( // Tab Container Styling
 
-	tabContainer = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder(), false, true, true, true).
-			BorderForeground(primaryColor).
-			AlignHorizontal(lipgloss.Left)
+	tabContainer lipgloss.Style
 
 	activeTabBorder = lipgloss.Border{
 		Top:         "─",
@@ -130,6 +143,17 @@ var ( // Tab Container Styling
 		BottomRight: "┴",
 	}
 
+	tab, activeTab, tabGap, tabGapLeft, tabGapRight lipgloss.Style
+
+	statusTextStyle, errContainerStyle, errHeaderStyle, errDescStyle, spinnerStyle lipgloss.Style
+)
+
+func styleTabContainer() {
+	tabContainer = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder(), false, true, true, true).
+		BorderForeground(primaryColor).
+		AlignHorizontal(lipgloss.Left)
+
 	tab = lipgloss.NewStyle().
 		Border(tabBorder, true).
 		BorderForeground(primaryColor).
@@ -137,7 +161,7 @@ var ( // Tab Container Styling
 		Padding(0, 1)
 
 	activeTab = tab.Border(activeTabBorder, true).
-			Foreground(primaryColor)
+		Foreground(primaryColor)
 
 	tabGap = lipgloss.NewStyle().
 		BorderForeground(primaryColor).
@@ -145,47 +169,82 @@ var ( // Tab Container Styling
 		Padding(0, 1).
 		Align(lipgloss.Center)
 
-	tabGapLeft  = tabGap.Border(lipgloss.Border{Bottom: "─", BottomLeft: "╭", BottomRight: "─"})
+	tabGapLeft = tabGap.Border(lipgloss.Border{Bottom: "─", BottomLeft: "╭", BottomRight: "─"})
 	tabGapRight = tabGap.Border(lipgloss.Border{Bottom: "─", BottomRight: "╮", BottomLeft: "─"})
 
 	statusTextStyle = lipgloss.NewStyle().
-			Padding(0, 2).
-			Foreground(lightGreyColor).
-			Background(primaryContrastColor).
-			Italic(true).
-			Align(lipgloss.Center)
+		Padding(0, 2).
+		Foreground(lightGreyColor).
+		Background(primaryContrastColor).
+		Italic(true).
+		Align(lipgloss.Center)
 
 	errContainerStyle = lipgloss.NewStyle().
-				Border(lipgloss.RoundedBorder(), true).
-				BorderForeground(dangerColor).
-				Foreground(dangerColor).
-				Width(61).
-				Padding(1, 2)
+		Border(lipgloss.RoundedBorder(), true).
+		BorderForeground(dangerColor).
+		Foreground(dangerColor).
+		Width(61).
+		Padding(1, 2)
 
 	errHeaderStyle = lipgloss.NewStyle().
-			Background(dangerColor).
-			Foreground(whiteColor).
-			Padding(0, 1)
+		Background(dangerColor).
+		Foreground(whiteColor).
+		Padding(0, 1)
 
 	errDescStyle = lipgloss.NewStyle().
-			Foreground(dangerColor).
-			MarginTop(1)
+		Foreground(dangerColor).
+		MarginTop(1)
 
 	spinnerStyle = lipgloss.NewStyle().
-			Foreground(primaryColor)
-)
+		Foreground(primaryColor)
+}
 
 var ( // Discover Styling
 
+	activeDiscoverBar, discoverTableStyle, discoverLoadMoreStyle lipgloss.Style
+)
+
+func styleDiscover() {
 	activeDiscoverBar = activeInputStyle.Width(71).
-				Border(lipgloss.RoundedBorder()).
-				Align(lipgloss.Center)
+		Border(lipgloss.RoundedBorder()).
+		Align(lipgloss.Center)
 
 	discoverTableStyle = lipgloss.NewStyle().
-				BorderStyle(lipgloss.RoundedBorder()).
-				BorderForeground(primaryColor)
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(primaryColor)
+
+	discoverLoadMoreStyle = lipgloss.NewStyle().Foreground(primaryColor).Faint(true)
+}
+
+var ( // Search Styling
+
+	searchListStyle, searchStarredTitleStyle, searchInfoStyle lipgloss.Style
+
+	searchHitTimestampStyle, searchHitMatchStyle lipgloss.Style
 )
 
+func styleSearch() {
+	searchListStyle = lipgloss.NewStyle().
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(primaryColor).
+		Padding(1, 1, 0, 1)
+
+	searchStarredTitleStyle = lipgloss.NewStyle().
+		Foreground(primaryColor).
+		Bold(true)
+
+	searchInfoStyle = lipgloss.NewStyle().
+		Foreground(primarySubtleDarkColor).
+		Margin(1, 1, 0, 1)
+
+	searchHitTimestampStyle = lipgloss.NewStyle().
+		Foreground(primarySubtleDarkColor)
+
+	searchHitMatchStyle = lipgloss.NewStyle().
+		Foreground(primaryContrastColor).
+		Background(primaryColor)
+}
+
 var ( // Conversation Styling
 
 	// updated by TabContainerModel so we can keep the verticalDivider proportional to the gap
@@ -195,27 +254,53 @@ var ( // Conversation Styling
 	// its simply vertical space between main container view, so can be used by different components to render height
 	conversationHeight = func() int { return terminalHeight - 4 }
 
+	conversationContainerStyle, conversationSearchBarStyle, conversationActiveSearchBarStyle lipgloss.Style
+
+	conversationAgoTimestampStyle lipgloss.Style
+
+	conversationOnlineIndicator, conversationAwayIndicator, conversationDNDIndicator string
+
+	conversationBotBadge, conversationMutedBadge string
+)
+
+func styleConversations() {
 	conversationContainerStyle = lipgloss.NewStyle().
-					Padding(0, 1).
-					BorderStyle(lipgloss.NormalBorder()).
-					BorderRight(true).
-					BorderForeground(darkGreyColor)
+		Padding(0, 1).
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderRight(true).
+		BorderForeground(darkGreyColor)
 
 	conversationSearchBarStyle = lipgloss.NewStyle().
-					Border(lipgloss.RoundedBorder(), true).
-					Padding(0, 1).
-					BorderForeground(primarySubtleDarkColor)
+		Border(lipgloss.RoundedBorder(), true).
+		Padding(0, 1).
+		BorderForeground(primarySubtleDarkColor)
 
 	conversationActiveSearchBarStyle = conversationSearchBarStyle.
-						BorderForeground(primaryColor)
+		BorderForeground(primaryColor)
 
 	conversationOnlineIndicator = lipgloss.NewStyle().
-					Foreground(greenColor).
-					Render("🌟")
+		Foreground(greenColor).
+		Render("🌟")
 
 	conversationAgoTimestampStyle = lipgloss.NewStyle().
-					Foreground(orangeColor)
-)
+		Foreground(orangeColor)
+
+	conversationAwayIndicator = lipgloss.NewStyle().
+		Foreground(orangeColor).
+		Render("away")
+
+	conversationDNDIndicator = lipgloss.NewStyle().
+		Foreground(redColor).
+		Render("busy")
+
+	conversationBotBadge = lipgloss.NewStyle().
+		Foreground(primarySubtleDarkColor).
+		Render(" 🤖")
+
+	conversationMutedBadge = lipgloss.NewStyle().
+		Foreground(primarySubtleDarkColor).
+		Render(" 🔕")
+}
 
 var (
 	tabGapRightWithTabsWidth int
@@ -224,22 +309,19 @@ var (
 
 	chatContainerStyle = lipgloss.NewStyle()
 
-	chatHeaderStyle = lipgloss.NewStyle().
-			BorderStyle(lipgloss.NormalBorder()).
-			BorderBottom(true).
-			BorderForeground(darkGreyColor).
-			Foreground(primaryColor).
-			Bold(true).
-			Margin(1, 3, 0, 3)
+	chatHeaderHandleStyle, chatHeaderTimerStyle, chatPinnedStripStyle, chatPinnedCountStyle lipgloss.Style
+
+	chatHeaderProfileStyle, chatHeaderStyle lipgloss.Style
 
 	chatHeaderHeight, chatTextareaHeight int // used by ChatModel.chatViewport for its height calculations
 
-	chatTxtareaStyle = lipgloss.NewStyle().
-				BorderStyle(lipgloss.NormalBorder()).
-				BorderTop(true).
-				BorderForeground(darkGreyColor).
-				Margin(0, 3).
-				Padding(1, 0)
+	chatTxtareaStyle, chatFindBarStyle, chatScheduleBarStyle lipgloss.Style
+
+	chatScheduledBubbleStyle = lipgloss.NewStyle().
+					Faint(true).
+					Italic(true)
+
+	chatFindStatusStyle, chatFindMatchStyle lipgloss.Style
 
 	chatBubbleContainer = lipgloss.NewStyle().
 				Margin(0, 1)
@@ -266,17 +348,7 @@ var (
 		BottomRight: "╯",
 	}
 
-	chatBubbleLStyle = lipgloss.NewStyle().
-				Border(chatBubbleLBorder, true).
-				BorderForeground(whiteColor).
-				Foreground(whiteColor).
-				Padding(0, 1)
-
-	chatBubbleRStyle = lipgloss.NewStyle().
-				Border(chatBubbleRBorder, true).
-				BorderForeground(primaryColor).
-				Padding(0, 1).
-				Foreground(primaryColor)
+	chatBubbleLStyle, chatBubbleRStyle lipgloss.Style
 
 	chatMenuBtnContainerStyle = lipgloss.NewStyle().
 					Margin(0, 2)
@@ -286,27 +358,78 @@ var (
 				Padding(0, 2)
 )
 
-var ( // Message Info Styling
+func styleChat() {
+	chatHeaderHandleStyle = lipgloss.NewStyle().
+		Foreground(primarySubtleDarkColor).
+		Bold(false)
 
-	msgInfoHeaderStyle = lipgloss.NewStyle().
-				Background(primaryContrastColor).
-				Foreground(primaryColor).
-				Margin(2, 5, 0, 5).
-				Padding(0, 2).
-				Italic(true)
+	chatHeaderTimerStyle = lipgloss.NewStyle().
+		Foreground(orangeColor).
+		Bold(false)
 
-	msgInfoBodyStyle = lipgloss.NewStyle().
-				BorderStyle(lipgloss.ThickBorder()).
-				BorderLeft(true).
-				BorderForeground(primaryColor).
-				Foreground(primaryColor).
-				Margin(2, 5, 0, 5).
-				PaddingLeft(2).
-				Italic(true)
+	chatPinnedStripStyle = lipgloss.NewStyle().
+		Foreground(whiteColor).
+		Padding(0, 1)
 
-	msgInfoFooterStyle = lipgloss.NewStyle().
-				Margin(2, 5).
-				Foreground(primarySubtleDarkColor)
+	chatPinnedCountStyle = lipgloss.NewStyle().
+		Foreground(primaryColor).
+		Bold(true)
+
+	chatHeaderProfileStyle = lipgloss.NewStyle().
+		Foreground(primarySubtleDarkColor).
+		Bold(false).
+		Italic(true)
+
+	chatHeaderStyle = lipgloss.NewStyle().
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderBottom(true).
+		BorderForeground(darkGreyColor).
+		Foreground(primaryColor).
+		Bold(true).
+		Margin(1, 3, 0, 3)
+
+	chatTxtareaStyle = lipgloss.NewStyle().
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderTop(true).
+		BorderForeground(darkGreyColor).
+		Margin(0, 3).
+		Padding(1, 0)
+
+	chatFindBarStyle = lipgloss.NewStyle().
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderBottom(true).
+		BorderForeground(darkGreyColor).
+		Margin(0, 3)
+
+	chatScheduleBarStyle = lipgloss.NewStyle().
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderTop(true).
+		BorderForeground(darkGreyColor).
+		Margin(0, 3)
+
+	chatFindStatusStyle = lipgloss.NewStyle().
+		Foreground(primarySubtleDarkColor)
+
+	chatFindMatchStyle = lipgloss.NewStyle().
+		Foreground(primaryContrastColor).
+		Background(orangeColor)
+
+	chatBubbleLStyle = lipgloss.NewStyle().
+		Border(chatBubbleLBorder, true).
+		BorderForeground(whiteColor).
+		Foreground(whiteColor).
+		Padding(0, 1)
+
+	chatBubbleRStyle = lipgloss.NewStyle().
+		Border(chatBubbleRBorder, true).
+		BorderForeground(primaryColor).
+		Padding(0, 1).
+		Foreground(primaryColor)
+}
+
+var ( // Message Info Styling
+
+	msgInfoHeaderStyle, msgInfoBodyStyle, msgInfoFooterStyle lipgloss.Style
 
 	msgInfoContainerBtn = lipgloss.NewStyle().
 				Margin(2, 5, 1, 5)
@@ -314,88 +437,215 @@ var ( // Message Info Styling
 	msgInfoBtnStyle = lipgloss.NewStyle().
 			MarginRight(1).
 			Padding(0, 2)
+
+	msgInfoCodeBlockStyle, msgInfoSelCodeBlockStyle, msgInfoCodeBlockTitleStyle lipgloss.Style
 )
 
+func styleMsgInfo() {
+	msgInfoHeaderStyle = lipgloss.NewStyle().
+		Background(primaryContrastColor).
+		Foreground(primaryColor).
+		Margin(2, 5, 0, 5).
+		Padding(0, 2).
+		Italic(true)
+
+	msgInfoBodyStyle = lipgloss.NewStyle().
+		BorderStyle(lipgloss.ThickBorder()).
+		BorderLeft(true).
+		BorderForeground(primaryColor).
+		Foreground(primaryColor).
+		Margin(2, 5, 0, 5).
+		PaddingLeft(2).
+		Italic(true)
+
+	msgInfoFooterStyle = lipgloss.NewStyle().
+		Margin(2, 5).
+		Foreground(primarySubtleDarkColor)
+
+	msgInfoCodeBlockStyle = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder(), true).
+		BorderForeground(darkGreyColor).
+		Foreground(lightGreyColor).
+		Margin(1, 5, 0, 5).
+		Padding(0, 1)
+
+	msgInfoSelCodeBlockStyle = msgInfoCodeBlockStyle.BorderForeground(orangeColor)
+
+	msgInfoCodeBlockTitleStyle = lipgloss.NewStyle().
+		Foreground(primarySubtleDarkColor).
+		Italic(true)
+}
+
 var ( // Preferences Styles
 
 	updateProfileWidth = func() int { return tabGapLeftWidth + 14 }
 	usageWidth         = func() int { return tabGapRightWithTabsWidth - 18 }
 
+	verticalDivider, sectionTitleStyle lipgloss.Style
+
+	themePickerStyle, themePickerLabelStyle, themePickerArrowStyle, themePickerNameStyle lipgloss.Style
+)
+
+func stylePreferences() {
 	verticalDivider = lipgloss.NewStyle().
-			BorderStyle(lipgloss.NormalBorder()).
-			BorderRight(true).
-			BorderForeground(darkGreyColor)
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderRight(true).
+		BorderForeground(darkGreyColor)
 
 	sectionTitleStyle = lipgloss.NewStyle().
-				Border(lipgloss.InnerHalfBlockBorder(), true).
-				BorderForeground(primaryContrastColor).
-				Background(primaryContrastColor).
-				Foreground(primaryColor).
-				Margin(2, 0, 1, 0).
-				Padding(0, 2).
-				Italic(true)
+		Border(lipgloss.InnerHalfBlockBorder(), true).
+		BorderForeground(primaryContrastColor).
+		Background(primaryContrastColor).
+		Foreground(primaryColor).
+		Margin(2, 0, 1, 0).
+		Padding(0, 2).
+		Italic(true)
+
+	themePickerStyle = lipgloss.NewStyle().
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderBottom(true).
+		BorderForeground(darkGreyColor).
+		MarginTop(1)
+
+	themePickerLabelStyle = lipgloss.NewStyle().
+		Foreground(primarySubtleDarkColor).
+		Italic(true)
+
+	themePickerArrowStyle = lipgloss.NewStyle().
+		Foreground(primaryColor).
+		Padding(0, 1)
+
+	themePickerNameStyle = lipgloss.NewStyle().
+		Background(primaryColor).
+		Foreground(primaryContrastColor).
+		Padding(0, 2)
+}
+
+var ( // Help Styles
+
+	helpTitleStyle, helpKeyStyle, helpUnboundKeyStyle, helpDescStyle, helpNameStyle, helpFooterStyle lipgloss.Style
 )
 
+func styleHelp() {
+	helpTitleStyle = lipgloss.NewStyle().
+		Background(primaryContrastColor).
+		Foreground(primaryColor).
+		Margin(1, 0).
+		Padding(0, 2).
+		Italic(true)
+
+	helpKeyStyle = lipgloss.NewStyle().
+		Foreground(primaryColor).
+		Bold(true).
+		PaddingRight(3)
+
+	helpUnboundKeyStyle = helpKeyStyle.
+		Foreground(darkGreyColor).
+		Bold(false)
+
+	helpDescStyle = lipgloss.NewStyle().
+		Foreground(whiteColor)
+
+	helpNameStyle = lipgloss.NewStyle().
+		Foreground(darkGreyColor).
+		PaddingLeft(2)
+
+	helpFooterStyle = lipgloss.NewStyle().
+		Foreground(primarySubtleDarkColor).
+		MarginTop(2).
+		Italic(true)
+}
+
+var ( // Accounts Styles
+
+	accountStyle, accountSelStyle, accountMetaStyle, accountUnreadStyle, accountAddStyle lipgloss.Style
+)
+
+func styleAccounts() {
+	accountStyle = lipgloss.NewStyle().
+		Foreground(whiteColor).
+		PaddingLeft(2)
+
+	accountSelStyle = accountStyle.
+		Foreground(primaryColor).
+		Bold(true)
+
+	accountMetaStyle = lipgloss.NewStyle().
+		Foreground(darkGreyColor).
+		PaddingLeft(2)
+
+	accountUnreadStyle = lipgloss.NewStyle().
+		Foreground(orangeColor).
+		Bold(true).
+		PaddingLeft(2)
+
+	accountAddStyle = accountStyle.
+		Foreground(primarySubtleDarkColor).
+		Italic(true)
+}
+
 var ( // Update Profile Form Styles
 
+	updateProfileInputHeaderStyle, updateProfileInputHeaderDangerStyle lipgloss.Style
+
+	updateProfileInputFieldStyle, updateProfileInputFieldDangerStyle lipgloss.Style
+
+	updateProfileFormStyle = lipgloss.NewStyle().
+				Margin(1, 0, 0, 3)
+
+	updateProfileFromBlurBtnStyle, updateProfileFormActiveBtnStyle, updateProfileFormDangerBtnStyle lipgloss.Style
+
+	updateProfileFormSuccessStyle, logoutPromptStyle lipgloss.Style
+)
+
+func styleUpdateProfile() {
 	updateProfileInputHeaderStyle = lipgloss.NewStyle().
-					Foreground(primarySubtleDarkColor).
-					MarginLeft(1)
+		Foreground(primarySubtleDarkColor).
+		MarginLeft(1)
 
 	updateProfileInputHeaderDangerStyle = updateProfileInputHeaderStyle.Foreground(dangerDarkColor)
 
 	updateProfileInputFieldStyle = lipgloss.NewStyle().
-					Border(lipgloss.RoundedBorder(), true).
-					BorderForeground(primaryContrastColor).
-					Padding(0, 1)
+		Border(lipgloss.RoundedBorder(), true).
+		BorderForeground(primaryContrastColor).
+		Padding(0, 1)
 
 	updateProfileInputFieldDangerStyle = updateProfileInputFieldStyle.BorderForeground(dangerDarkColor)
 
-	updateProfileFormStyle = lipgloss.NewStyle().
-				Margin(1, 0, 0, 3)
-
 	updateProfileFromBlurBtnStyle = lipgloss.NewStyle().
-					Background(darkGreyColor).
-					Foreground(lightGreyColor).
-					MarginTop(1).
-					Padding(0, 2)
+		Background(darkGreyColor).
+		Foreground(lightGreyColor).
+		MarginTop(1).
+		Padding(0, 2)
 
 	updateProfileFormActiveBtnStyle = updateProfileFromBlurBtnStyle.
-					Background(primaryColor).
-					Foreground(primaryContrastColor)
+		Background(primaryColor).
+		Foreground(primaryContrastColor)
 
 	updateProfileFormDangerBtnStyle = updateProfileFromBlurBtnStyle.
-					Background(dangerColor).
-					Foreground(whiteColor)
+		Background(dangerColor).
+		Foreground(whiteColor)
 
 	updateProfileFormSuccessStyle = lipgloss.NewStyle().
-					Foreground(greenColor).
-					MarginTop(2).
-					Align(lipgloss.Center).
-					Faint(true).
-					Italic(true).
-					SetString("Account settings updated successfully!")
+		Foreground(greenColor).
+		MarginTop(2).
+		Align(lipgloss.Center).
+		Faint(true).
+		Italic(true).
+		SetString("Account settings updated successfully!")
 
 	logoutPromptStyle = updateProfileFormSuccessStyle.
-				Foreground(dangerDarkColor).
-				Faint(false).
-				Italic(false).
-				SetString("Login to another account,")
-)
+		Foreground(dangerDarkColor).
+		Faint(false).
+		Italic(false).
+		SetString("Login to another account,")
+}
 
 var ( // Bunny Stying
 
-	bunnyColor = lipgloss.AdaptiveColor{Light: "#602c1a", Dark: "#6d4534"}
-
-	bunnyText = lipgloss.NewStyle().
-			Foreground(primaryColor).
-			Align(lipgloss.Center).
-			MarginTop(1).
-			Render(" Houston, we have a problem.\nNo results in this banner hole!")
+	bunnyColor lipgloss.AdaptiveColor
 
-	bunny = lipgloss.NewStyle().
-		Foreground(bunnyColor).
-		Render(lipgloss.JoinVertical(lipgloss.Center), b, bunnyText)
+	bunnyText, bunny string
 
 	b = `
 ....▓▓▓▓
@@ -417,14 +667,30 @@ var ( // Bunny Stying
 `
 )
 
+func styleBunny() {
+	bunnyText = lipgloss.NewStyle().
+		Foreground(primaryColor).
+		Align(lipgloss.Center).
+		MarginTop(1).
+		Render(" Houston, we have a problem.\nNo results in this banner hole!")
+
+	bunny = lipgloss.NewStyle().
+		Foreground(bunnyColor).
+		Render(lipgloss.JoinVertical(lipgloss.Center), b, bunnyText)
+}
+
 var (
+	banner string
+
+	r       = "    __         __            __          __ \n   / /   ___  / /___________/ /_  ____ _/ /_\n  / /   / _ \\/ __/ ___/ ___/ __ \\/ __ `/ __/\n / /___/  __/ /_(__  ) /__/ / / / /_/ / /_  \n/_____/\\___/\\__/____/\\___/_/ /_/\\__,_/\\__/  \n          "
+	credits = lipgloss.NewStyle().Italic(true).Render("Made with ♥️ by Muhammad Usman")
+)
+
+func styleBanner() {
 	banner = lipgloss.NewStyle().
 		Foreground(primaryColor).
 		MarginTop(1).
 		Blink(true).
 		SetString(r).
 		Render(credits)
-
-	r       = "    __         __            __          __ \n   / /   ___  / /___________/ /_  ____ _/ /_\n  / /   / _ \\/ __/ ___/ ___/ __ \\/ __ `/ __/\n / /___/  __/ /_(__  ) /__/ / / / /_/ / /_  \n/_____/\\___/\\__/____/\\___/_/ /_/\\__,_/\\__/  \n          "
-	credits = lipgloss.NewStyle().Italic(true).Render("Made with ♥️ by Muhammad Usman")
-)
+}
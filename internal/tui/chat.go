@@ -1,14 +1,19 @@
 package tui
 
 import (
+	"errors"
+	"fmt"
 	"github.com/MuhamedUsman/letschat/internal/client"
 	"github.com/MuhamedUsman/letschat/internal/domain"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/google/uuid"
 	zone "github.com/lrstanley/bubblezone"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 )
@@ -18,6 +23,8 @@ const (
 	chatMenu            = "chatMenu"
 	menuGotoFirstMsgBtn = "menuGotoFirstMsgBtn"
 	menuClearConvoBtn   = "menuClearConvoBtn"
+	menuDisappearBtn    = "menuDisappearBtn"
+	chatPinnedStrip     = "chatPinnedStrip"
 	chatViewport        = "chatViewport"
 	chatTxtarea         = "chatTxtarea"
 )
@@ -27,17 +34,30 @@ type ChatModel struct {
 	chatViewport   ChatViewportModel
 	focus          bool
 	prevChatLength int
-	// menu buttons, -1 -> None Selected | 0 -> Goto First Msg | 1 -> Clear Conversation
+	// menu buttons, -1 -> None Selected | 0 -> Goto First Msg | 1 -> Clear Conversation | 2 -> Disappearing Msgs
 	menuBtnIdx int
-	client     *client.Client
+	// in-chat find bar, open from ctrl+f until esc or the selected user changes
+	findTxtInput textinput.Model
+	findOpen     bool
+	findUsrID    string
+	// schedule bar of the compose box, open from ctrl+g until esc, the msg is scheduled or the selected user changes
+	schedTxtInput textinput.Model
+	schedOpen     bool
+	schedUsrID    string
+	schedStatus   string
+	// the pending msg being edited, nil while scheduling a new one
+	schedEditing *domain.ScheduledMessage
+	client       *client.Client
 }
 
 func InitialChatModel(c *client.Client) ChatModel {
 	return ChatModel{
-		chatTxtarea:  newChatTxtArea(),
-		chatViewport: InitialChatViewport(c),
-		menuBtnIdx:   -1,
-		client:       c,
+		chatTxtarea:   newChatTxtArea(),
+		chatViewport:  InitialChatViewport(c),
+		findTxtInput:  newChatFindTxtInput(),
+		schedTxtInput: newChatSchedTxtInput(),
+		menuBtnIdx:    -1,
+		client:        c,
 	}
 }
 
@@ -46,11 +66,20 @@ func (m ChatModel) Init() tea.Cmd {
 }
 
 func (m ChatModel) Update(msg tea.Msg) (ChatModel, tea.Cmd) {
+	if m.findOpen && m.findUsrID != selUserID {
+		m.closeFind()
+	}
+	if m.schedOpen && m.schedUsrID != selUserID {
+		m.closeSchedule()
+	}
+
 	if !m.focus {
 		m.chatViewport.focus = false
 		m.chatTxtarea.Blur()
+		m.findTxtInput.Blur()
+		m.schedTxtInput.Blur()
 		m.updateChatTxtareaAndViewportDimensions()
-	} else if m.chatTxtarea.Focused() {
+	} else if m.chatTxtarea.Focused() || m.findTxtInput.Focused() || m.schedTxtInput.Focused() {
 		m.chatViewport.focus = false
 	} else {
 		m.chatViewport.focus = true
@@ -63,33 +92,83 @@ func (m ChatModel) Update(msg tea.Msg) (ChatModel, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.updateChatTxtareaAndViewportDimensions()
 
+	case themeChangedMsg:
+		restyleChatTxtArea(&m.chatTxtarea)
+		restyleTxtInput(&m.findTxtInput)
+		restyleTxtInput(&m.schedTxtInput)
+
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+t":
+		switch {
+		case key.Matches(msg, keys.Conversations.FocusTyping):
 			typingCmd = m.chatTxtarea.Focus()
+			m.findTxtInput.Blur()
+			m.schedTxtInput.Blur()
 			m.menuBtnIdx = -1
 			m.updateChatTxtareaAndViewportDimensions()
-		case "ctrl+o":
+		case key.Matches(msg, keys.Chat.Options):
 			m.menuBtnIdx = 0
-		case "left":
-			if m.menuBtnIdx == 1 {
+		case msg.String() == "left":
+			if m.menuBtnIdx > 0 {
 				m.menuBtnIdx--
 			}
-		case "right":
-			if m.menuBtnIdx == 0 {
+		case msg.String() == "right":
+			if m.menuBtnIdx > -1 && m.menuBtnIdx < 2 {
 				m.menuBtnIdx++
 			}
-		case "tab":
-			if m.menuBtnIdx > -1 && m.menuBtnIdx <= 1 {
-				m.menuBtnIdx = (m.menuBtnIdx + 1) % 2
+		case msg.String() == "tab":
+			if m.menuBtnIdx > -1 && m.menuBtnIdx <= 2 {
+				m.menuBtnIdx = (m.menuBtnIdx + 1) % 3
 			}
-		case "esc", "ctrl+f":
+		case msg.String() == "esc", key.Matches(msg, keys.Global.Find):
 			if m.menuBtnIdx != -1 {
 				m.menuBtnIdx = -1
 			}
 			m.chatTxtarea.Blur()
 			m.updateChatTxtareaAndViewportDimensions()
-		case "enter":
+			if msg.String() == "esc" && m.findOpen {
+				m.closeFind()
+			}
+			if msg.String() == "esc" && m.schedOpen {
+				m.closeSchedule()
+			}
+			if key.Matches(msg, keys.Global.Find) && m.focus && selUserID != "" {
+				m.findOpen = true
+				m.findUsrID = selUserID
+				m.schedTxtInput.Blur()
+				return m, m.findTxtInput.Focus()
+			}
+		case key.Matches(msg, keys.Chat.SendLater):
+			if m.focus && selUserID != "" {
+				return m, m.openSchedule(m.schedEditing)
+			}
+		case key.Matches(msg, keys.Chat.Unschedule):
+			if m.schedTxtInput.Focused() && m.schedEditing != nil {
+				return m, m.cancelScheduled(m.schedEditing.ID)
+			}
+		case key.Matches(msg, keys.Chat.RawText):
+			if m.focus && selUserID != "" {
+				return m, m.chatViewport.toggleRawMsgs()
+			}
+		case key.Matches(msg, keys.Chat.NextMatch, keys.Chat.PrevMatch):
+			if m.findOpen && m.focus && !m.findTxtInput.Focused() && !m.chatTxtarea.Focused() &&
+				!m.schedTxtInput.Focused() {
+				step := 1 // to the older match
+				if key.Matches(msg, keys.Chat.PrevMatch) {
+					step = -1
+				}
+				return m, m.chatViewport.nextFindMatch(step)
+			}
+		case msg.String() == "enter":
+			if m.schedTxtInput.Focused() {
+				return m, m.scheduleMessage()
+			}
+			if m.chatTxtarea.Focused() && m.schedOpen { // the msg is to be scheduled, not sent
+				return m, m.openSchedule(m.schedEditing)
+			}
+			if m.findTxtInput.Focused() {
+				m.findTxtInput.Blur() // so n & N move through the matches
+				return m, m.chatViewport.find(strings.TrimSpace(m.findTxtInput.Value()))
+			}
 			if m.chatTxtarea.Focused() {
 				s := m.chatTxtarea.Value()
 				s = strings.TrimSpace(s)
@@ -107,6 +186,8 @@ func (m ChatModel) Update(msg tea.Msg) (ChatModel, tea.Cmd) {
 			case 1:
 				m.menuBtnIdx = -1
 				return m, m.deleteAllMsgsForConvo(m.client.CurrentUsr.ID, selUserID)
+			case 2: // kept open, so the presets can be cycled through
+				return m, m.cycleDisappearAfter()
 			}
 		}
 
@@ -135,6 +216,9 @@ func (m ChatModel) Update(msg tea.Msg) (ChatModel, tea.Cmd) {
 			if zone.Get(menuClearConvoBtn).InBounds(msg) {
 				m.menuBtnIdx = 1
 			}
+			if zone.Get(menuDisappearBtn).InBounds(msg) {
+				m.menuBtnIdx = 2
+			}
 		default:
 		}
 
@@ -144,6 +228,12 @@ func (m ChatModel) Update(msg tea.Msg) (ChatModel, tea.Cmd) {
 			m.menuBtnIdx = 0
 		}
 
+		if zone.Get(chatPinnedStrip).InBounds(msg) &&
+			msg.Button == tea.MouseButtonLeft &&
+			msg.Action == tea.MouseActionRelease {
+			return m, m.chatViewport.gotoPinnedMsg()
+		}
+
 		if !zone.Get(chatHeaderContainer).InBounds(msg) {
 			m.menuBtnIdx = -1
 		}
@@ -158,6 +248,21 @@ func (m ChatModel) Update(msg tea.Msg) (ChatModel, tea.Cmd) {
 			m.updateChatTxtareaAndViewportDimensions()
 		}
 
+	case editScheduledMsg:
+		m.chatTxtarea.SetValue(msg.sm.Body)
+		m.schedTxtInput.SetValue(msg.sm.SendAt.In(time.Local).Format(schedTimeLayout))
+		return m, m.openSchedule(msg.sm)
+
+	case scheduledMsgs:
+		if msg.done && msg.usrID == m.schedUsrID {
+			m.chatTxtarea.Reset()
+			m.closeSchedule()
+		}
+
+	case scheduleFailed:
+		m.schedStatus = string(msg)
+		return m, nil
+
 	case echoTypingMsg:
 		var cmd tea.Cmd
 		if m.prevChatLength < m.chatTxtarea.Length() && !selUserTyping {
@@ -169,20 +274,36 @@ func (m ChatModel) Update(msg tea.Msg) (ChatModel, tea.Cmd) {
 
 	}
 
-	return m, tea.Batch(typingCmd, m.handleChatTextareaUpdate(msg), m.handleChatViewportUpdate(msg))
+	return m, tea.Batch(
+		typingCmd,
+		m.handleChatTextareaUpdate(msg),
+		m.handleFindTxtInputUpdate(msg),
+		m.handleSchedTxtInputUpdate(msg),
+		m.handleChatViewportUpdate(msg),
+	)
 }
 
 func (m ChatModel) View() string {
 	if selUsername == "" {
 		return lipgloss.Place(chatWidth(), chatHeight(), lipgloss.Center, lipgloss.Center, banner)
 	}
-	h := renderChatHeader(selUsername, selUserTyping)
+	disappearAfter := m.client.DisappearAfter(selUserID)
+	h := renderChatHeader(selUsername, m.selUserHandle(), m.selUserProfile(), disappearAfter, selUserTyping)
 	if m.menuBtnIdx != -1 {
-		h = renderMenuBtns(m.menuBtnIdx)
+		h = renderMenuBtns(m.menuBtnIdx, disappearAfter)
+	}
+	if pin, i, n := m.chatViewport.currentPin(); pin != nil {
+		h = lipgloss.JoinVertical(lipgloss.Left, h, renderPinnedStrip(pin, i, n))
+	}
+	if m.findOpen {
+		h = lipgloss.JoinVertical(lipgloss.Left, h, renderFindBar(m.findTxtInput.View(), m.chatViewport.findStatus()))
 	}
 	chatHeaderHeight = lipgloss.Height(h)
 	ta := zone.Mark(chatTxtarea, m.chatTxtarea.View())
 	ta = renderChatTextarea(ta, m.chatTxtarea.Focused())
+	if m.schedOpen {
+		ta = lipgloss.JoinVertical(lipgloss.Left, renderScheduleBar(m.schedTxtInput.View(), m.schedHint()), ta)
+	}
 	chatTextareaHeight = lipgloss.Height(ta)
 	m.chatViewport.chatVp.Height = chatHeight() - (chatHeaderHeight + chatTextareaHeight)
 	if m.chatTxtarea.Focused() { // only works after setting chatVp height
@@ -207,16 +328,76 @@ func newChatTxtArea() textarea.Model {
 	ta.CharLimit = 1000
 	ta.ShowLineNumbers = false
 	ta.SetHeight(0)
-	ta.KeyMap.InsertNewline.SetKeys("ctrl+n") // default: enter, but it will be used to send msg
+	ta.KeyMap.InsertNewline.SetKeys(keys.Chat.NewLine.Keys()...) // default: enter, but it will be used to send msg
 	ta.FocusedStyle.CursorLine = lipgloss.NewStyle()
+	restyleChatTxtArea(&ta)
+	return ta
+}
+
+// restyleChatTxtArea colors ta after the active theme, on creation & on themeChangedMsg
+func restyleChatTxtArea(ta *textarea.Model) {
 	ta.Cursor.Style = lipgloss.NewStyle().Foreground(primaryColor)
 	ta.FocusedStyle.Base = lipgloss.NewStyle().Foreground(whiteColor)
 	ta.BlurredStyle.Base = lipgloss.NewStyle().Foreground(whiteColor)
-	return ta
 }
 
-func renderChatHeader(name string, typing bool) string {
+func newChatFindTxtInput() textinput.Model {
+	ti := textinput.New()
+	ti.Placeholder = "Find in chat..."
+	ti.Prompt = "🔎 "
+	ti.CharLimit = 64
+	restyleTxtInput(&ti)
+	return ti
+}
+
+func newChatSchedTxtInput() textinput.Model {
+	ti := textinput.New()
+	ti.Placeholder = "in 2h30m · 17:30 · 2026-01-02 09:00"
+	ti.Prompt = "⏰ "
+	ti.CharLimit = 32
+	restyleTxtInput(&ti)
+	return ti
+}
+
+// renderFindBar renders the find input with the "n of m" of the current match on its right
+func renderFindBar(input, status string) string {
+	c := chatFindBarStyle.Width(chatWidth())
+	status = chatFindStatusStyle.Render(status)
+	w := max(0, chatWidth()-c.GetHorizontalFrameSize()-lipgloss.Width(status))
+	input = lipgloss.NewStyle().Width(w).MaxHeight(1).Render(input)
+	return c.Render(lipgloss.JoinHorizontal(lipgloss.Top, input, status))
+}
+
+// renderScheduleBar renders the send time input above the compose box, with the keys or the failure on its right
+func renderScheduleBar(input, hint string) string {
+	c := chatScheduleBarStyle.Width(chatWidth())
+	hint = chatFindStatusStyle.Render(hint)
+	w := max(0, chatWidth()-c.GetHorizontalFrameSize()-lipgloss.Width(hint))
+	input = lipgloss.NewStyle().Width(w).MaxHeight(1).Render(input)
+	return c.Render(lipgloss.JoinHorizontal(lipgloss.Top, input, hint))
+}
+
+func (m ChatModel) schedHint() string {
+	switch {
+	case m.schedStatus != "":
+		return m.schedStatus
+	case m.schedEditing != nil:
+		return "enter: save · alt+c: unschedule · esc"
+	default:
+		return "enter: schedule · esc"
+	}
+}
+
+// renderChatHeader renders the name of the selected user, with its profile under it if not empty, & a timer badge if
+// the disappearing msgs are on
+func renderChatHeader(name, handle, profile string, disappearAfter time.Duration, typing bool) string {
 	c := chatHeaderStyle.Width(chatWidth())
+	if handle != "" {
+		name += chatHeaderHandleStyle.Render(" " + handle)
+	}
+	if disappearAfter > 0 {
+		name += chatHeaderTimerStyle.Render(" ⏱ " + formatDisappearAfter(disappearAfter))
+	}
 	menu := zone.Mark(chatMenu, "⚙️")
 	sub := c.GetHorizontalFrameSize() + lipgloss.Width(name) + lipgloss.Width(menu)
 	menuMarginLeft := max(0, c.GetWidth()-sub)
@@ -224,7 +405,51 @@ func renderChatHeader(name string, typing bool) string {
 		MarginLeft(menuMarginLeft).
 		Render(menu)
 	name = lipgloss.NewStyle().Blink(typing).Render(name)
-	return zone.Mark(chatHeaderContainer, c.Render(name, menu))
+	if profile == "" {
+		return zone.Mark(chatHeaderContainer, c.Render(name, menu))
+	}
+	profile = chatHeaderProfileStyle.Width(chatWidth() - c.GetHorizontalFrameSize()).Render(profile)
+	header := lipgloss.JoinVertical(lipgloss.Left, name+" "+menu, profile)
+	return zone.Mark(chatHeaderContainer, c.Render(header))
+}
+
+// renderPinnedStrip renders the first line of the pin under the header, clicking it scrolls to the pinned msg
+func renderPinnedStrip(pin *domain.Message, i, n int) string {
+	body, _, _ := strings.Cut(pin.Body, "\n")
+	count := chatPinnedCountStyle.Render(fmt.Sprintf("📌 %d/%d ", i+1, n))
+	s := chatPinnedStripStyle.
+		Width(chatWidth()).
+		MaxHeight(1).
+		Render(count + body)
+	return zone.Mark(chatPinnedStrip, s)
+}
+
+// selUserHandle is the rendered handle of the selected user
+func (m ChatModel) selUserHandle() string {
+	for _, convo := range m.client.Conversations.Get() {
+		if convo.UserID == selUserID {
+			return renderHandle(convo.UserHandle)
+		}
+	}
+	return renderHandle(&selDiscUserHandle)
+}
+
+// selUserProfile is the status & bio of the selected user, one per line
+func (m ChatModel) selUserProfile() string {
+	for _, convo := range m.client.Conversations.Get() {
+		if convo.UserID != selUserID {
+			continue
+		}
+		status := renderProfileStatus(convo.StatusEmoji, convo.StatusText, convo.StatusExpiresAt)
+		lines := make([]string, 0, 2)
+		for _, l := range []string{status, convo.Bio} {
+			if l != "" {
+				lines = append(lines, l)
+			}
+		}
+		return strings.Join(lines, "\n")
+	}
+	return ""
 }
 
 func renderChatTextarea(ta string, padding bool) string {
@@ -237,20 +462,18 @@ func renderChatTextarea(ta string, padding bool) string {
 	return cStyle.Render(ta)
 }
 
-func renderMenuBtns(selection int) string {
+func renderMenuBtns(selection int, disappearAfter time.Duration) string {
 	if selection == -1 {
 		return ""
 	}
 
-	gotoFirstMsgBtn := renderGotoFirstMsgBtn(true)
-	clearConvoBtn := renderClearConvoBtn(false)
-	if selection == 1 {
-		gotoFirstMsgBtn = renderGotoFirstMsgBtn(false)
-		clearConvoBtn = renderClearConvoBtn(true)
-	}
+	gotoFirstMsgBtn := renderGotoFirstMsgBtn(selection == 0)
+	clearConvoBtn := renderClearConvoBtn(selection == 1)
+	disappearBtn := renderDisappearBtn(selection == 2, disappearAfter)
 	gotoFirstMsgBtn = zone.Mark(menuGotoFirstMsgBtn, gotoFirstMsgBtn)
 	clearConvoBtn = zone.Mark(menuClearConvoBtn, clearConvoBtn)
-	btnContainer := chatMenuBtnContainerStyle.Render(gotoFirstMsgBtn, clearConvoBtn)
+	disappearBtn = zone.Mark(menuDisappearBtn, disappearBtn)
+	btnContainer := chatMenuBtnContainerStyle.Render(gotoFirstMsgBtn, clearConvoBtn, disappearBtn)
 
 	c := chatHeaderStyle.Width(chatWidth())
 	content := lipgloss.PlaceHorizontal(chatWidth()-c.GetHorizontalFrameSize(), lipgloss.Center, btnContainer)
@@ -284,12 +507,76 @@ func renderClearConvoBtn(focus bool) string {
 		Render("CLEAR CONVERSATION")
 }
 
+func renderDisappearBtn(focus bool, disappearAfter time.Duration) string {
+	bg := primaryColor
+	fg := primaryContrastColor
+	if !focus {
+		bg = darkGreyColor
+		fg = lightGreyColor
+	}
+	return chatMenuBtnStyle.
+		Background(bg).
+		Foreground(fg).
+		Render("⏱ DISAPPEARING: " + strings.ToUpper(formatDisappearAfter(disappearAfter)))
+}
+
 func (m *ChatModel) handleChatTextareaUpdate(msg tea.Msg) tea.Cmd {
 	var cmd tea.Cmd
 	m.chatTxtarea, cmd = m.chatTxtarea.Update(msg)
 	return cmd
 }
 
+func (m *ChatModel) handleFindTxtInputUpdate(msg tea.Msg) tea.Cmd {
+	if !m.findOpen {
+		return nil
+	}
+	var cmd tea.Cmd
+	m.findTxtInput, cmd = m.findTxtInput.Update(msg)
+	return cmd
+}
+
+func (m *ChatModel) handleSchedTxtInputUpdate(msg tea.Msg) tea.Cmd {
+	if !m.schedOpen {
+		return nil
+	}
+	var cmd tea.Cmd
+	m.schedTxtInput, cmd = m.schedTxtInput.Update(msg)
+	return cmd
+}
+
+// openSchedule focuses the schedule bar, editing the pending sm or scheduling a new msg if nil
+func (m *ChatModel) openSchedule(sm *domain.ScheduledMessage) tea.Cmd {
+	if !m.schedOpen || m.schedEditing != sm {
+		m.schedStatus = ""
+	}
+	m.schedOpen = true
+	m.schedUsrID = selUserID
+	m.schedEditing = sm
+	m.chatTxtarea.Blur()
+	m.findTxtInput.Blur()
+	m.updateChatTxtareaAndViewportDimensions()
+	return m.schedTxtInput.Focus()
+}
+
+func (m *ChatModel) closeSchedule() {
+	if m.schedEditing != nil { // the body of the edited msg is not to be sent
+		m.chatTxtarea.Reset()
+	}
+	m.schedOpen = false
+	m.schedEditing = nil
+	m.schedStatus = ""
+	m.schedTxtInput.Blur()
+	m.schedTxtInput.Reset()
+}
+
+func (m *ChatModel) closeFind() {
+	m.findOpen = false
+	m.findTxtInput.Blur()
+	m.findTxtInput.Reset()
+	m.chatViewport.clearFind()
+	m.chatViewport.chatVp.SetContent(m.chatViewport.renderChatViewport())
+}
+
 func (m *ChatModel) handleChatViewportUpdate(msg tea.Msg) tea.Cmd {
 	var cmd tea.Cmd
 	m.chatViewport, cmd = m.chatViewport.Update(msg)
@@ -315,6 +602,7 @@ func (m *ChatModel) sendMessage(msg string) tea.Cmd {
 		Body:       msg,
 		SentAt:     &t,
 		Operation:  domain.CreateMsg,
+		ExpiresAt:  m.client.ExpiresAt(selUserID, t),
 	}
 	return func() tea.Msg {
 		if m.client.WsConnState.Get() != client.Connected {
@@ -329,6 +617,53 @@ func (m *ChatModel) sendMessage(msg string) tea.Cmd {
 	}
 }
 
+// scheduleMessage schedules the msg in the compose box at the time of the schedule bar, or saves the edited one
+func (m *ChatModel) scheduleMessage() tea.Cmd {
+	body := strings.TrimSpace(m.chatTxtarea.Value())
+	if body == "" {
+		m.schedStatus = "type a message first"
+		return nil
+	}
+	sendAt, err := parseSendAt(m.schedTxtInput.Value(), time.Now())
+	if err != nil {
+		m.schedStatus = err.Error()
+		return nil
+	}
+	m.schedStatus = "scheduling..."
+	usrID := selUserID
+	var editing *domain.ScheduledMessage
+	if m.schedEditing != nil {
+		sm := *m.schedEditing
+		sm.Body, sm.SendAt = body, sendAt
+		editing = &sm
+	}
+	return func() tea.Msg {
+		var code int
+		var err error
+		if editing != nil {
+			_, code, err = m.client.UpdateScheduledMessage(editing)
+		} else {
+			_, code, err = m.client.ScheduleMessage(usrID, body, sendAt)
+		}
+		if failure := scheduleFailure(code, err); failure != nil {
+			return failure
+		}
+		return fetchScheduledMsgs(m.client, usrID, true)
+	}
+}
+
+func (m *ChatModel) cancelScheduled(id string) tea.Cmd {
+	m.schedStatus = "unscheduling..."
+	usrID := selUserID
+	return func() tea.Msg {
+		code, err := m.client.CancelScheduledMessage(id)
+		if failure := scheduleFailure(code, err); failure != nil {
+			return failure
+		}
+		return fetchScheduledMsgs(m.client, usrID, true)
+	}
+}
+
 func (m *ChatModel) sendTypingStatus() tea.Cmd {
 	t := time.Now()
 	msgToSnd := domain.Message{
@@ -344,6 +679,25 @@ func (m *ChatModel) sendTypingStatus() tea.Cmd {
 	}
 }
 
+// cycleDisappearAfter sets the disappearing msgs of the selected conversation to the preset after the current one
+func (m ChatModel) cycleDisappearAfter() tea.Cmd {
+	usrID := selUserID
+	// the server only keeps the setting on an existing conversation
+	if !slices.ContainsFunc(m.client.Conversations.Get(), func(c *domain.Conversation) bool { return c.UserID == usrID }) {
+		return nil
+	}
+	next := nextDisappearPreset(m.client.DisappearAfter(usrID))
+	return func() tea.Msg {
+		if err := m.client.SetDisappearAfter(usrID, next); err != nil {
+			return &errMsg{
+				err:  "Unable to change the disappearing messages, no connection.",
+				code: http.StatusRequestTimeout,
+			}
+		}
+		return nil
+	}
+}
+
 func (m ChatModel) deleteAllMsgsForConvo(currUsrId, selUsrId string) tea.Cmd {
 	return func() tea.Msg {
 		if err := m.client.DeleteForMeAllMsgsForConversation(currUsrId, selUsrId); err != nil {
@@ -355,3 +709,90 @@ func (m ChatModel) deleteAllMsgsForConvo(currUsrId, selUsrId string) tea.Cmd {
 		return clearConvoSuccess{}
 	}
 }
+
+const schedTimeLayout = "2006-01-02 15:04"
+
+// parseSendAt parses the send time of a scheduled msg, either a duration from now optionally prefixed with "in",
+// a clock time of today, or of tomorrow if passed already, or a local date & time in schedTimeLayout
+func parseSendAt(s string, now time.Time) (time.Time, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return time.Time{}, errors.New("type when to send it")
+	}
+	if d, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(s, "in "))); err == nil {
+		if d <= 0 {
+			return time.Time{}, errors.New("must be in the future")
+		}
+		return now.Add(d).Truncate(time.Second), nil
+	}
+	for _, layout := range []string{"15:04", "3:04pm", "3pm"} {
+		t, err := time.ParseInLocation(layout, strings.ReplaceAll(s, " ", ""), time.Local)
+		if err != nil {
+			continue
+		}
+		t = time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, time.Local)
+		if !t.After(now) {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+	t, err := time.ParseInLocation(schedTimeLayout, s, time.Local)
+	if err != nil {
+		return time.Time{}, errors.New("unknown time, e.g. in 2h · 17:30")
+	}
+	if !t.After(now) {
+		return time.Time{}, errors.New("must be in the future")
+	}
+	return t, nil
+}
+
+// scheduleFailure is the msg for a failed request on a scheduled msg, nil if it has not failed
+func scheduleFailure(code int, err error) tea.Msg {
+	var ev *domain.ErrValidation
+	switch {
+	case errors.As(err, &ev):
+		for field, reason := range ev.Errors {
+			return scheduleFailed(strings.ReplaceAll(field, "_", " ") + " " + reason)
+		}
+		return scheduleFailed("invalid message")
+	case err != nil:
+		return &errMsg{err: err.Error(), code: code}
+	case code == http.StatusUnauthorized:
+		return requireAuthMsg{}
+	case code == http.StatusNotFound, code == http.StatusConflict:
+		return scheduleFailed("sent or changed meanwhile")
+	case code >= http.StatusBadRequest:
+		return &errMsg{err: "Unable to schedule the message", code: code}
+	}
+	return nil
+}
+
+// disappearPresets are cycled through by the disappearing msgs menu button, off first
+var disappearPresets = []time.Duration{0, 5 * time.Minute, time.Hour, 24 * time.Hour, 7 * 24 * time.Hour}
+
+// nextDisappearPreset is the preset after cur, off after the last one or if cur is not one of them
+func nextDisappearPreset(cur time.Duration) time.Duration {
+	i := slices.Index(disappearPresets, cur)
+	if i == -1 {
+		return 0
+	}
+	return disappearPresets[(i+1)%len(disappearPresets)]
+}
+
+// formatDisappearAfter formats d in its largest whole unit, e.g. 1d, 5m, off if zero
+func formatDisappearAfter(d time.Duration) string {
+	const day, week = 24 * time.Hour, 7 * 24 * time.Hour
+	switch {
+	case d == 0:
+		return "off"
+	case d%week == 0:
+		return fmt.Sprintf("%dw", d/week)
+	case d%day == 0:
+		return fmt.Sprintf("%dd", d/day)
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d%time.Minute == 0:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	}
+	return fmt.Sprintf("%ds", d/time.Second)
+}
@@ -5,12 +5,14 @@ import (
 	"github.com/MuhamedUsman/letschat/internal/client"
 	"github.com/MuhamedUsman/letschat/internal/domain"
 	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/timer"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	zone "github.com/lrstanley/bubblezone"
 	"log/slog"
+	"maps"
 	"net/http"
 	"slices"
 	"strings"
@@ -22,13 +24,44 @@ const (
 	infoDialogCopyBtn           = "infoDialogCopyBtn"
 	infoDialogDelForMeBtn       = "infoDialogDelForMeBtn"
 	infoDialogDelForEveryoneBtn = "infoDialogDelForEveryoneBtn"
+	infoDialogPinBtn            = "infoDialogPinBtn"
+	infoDialogStarBtn           = "infoDialogStarBtn"
+	infoDialogCodeBlock         = "infoDialogCodeBlock" // suffixed with the index of the block
 )
 
+// pinCycleInterval is how long each pin is shown on the pinned strip of the chat header
+const pinCycleInterval = 4 * time.Second
+
 type msgPage struct {
 	msgs []*domain.Message
 	meta *domain.Metadata
 }
 
+// scheduledMsgs are the pending scheduled msgs to usrID, done if fetched after one of them is changed by the user
+type scheduledMsgs struct {
+	usrID string
+	sms   []*domain.ScheduledMessage
+	done  bool
+}
+
+// pinnedMsgs are the pinned msgs of the conversation with usrID, the latest pinned first
+type pinnedMsgs struct {
+	usrID string
+	msgs  []*domain.Message
+}
+
+// msgStarred is the msg with id starred or unstarred by the user
+type msgStarred struct {
+	id      string
+	starred bool
+}
+
+// pinCycleMsg moves the pinned strip to the next pin
+type pinCycleMsg struct{}
+
+// rawMsgsToggled is the raw msgs preference of the user, once toggled
+type rawMsgsToggled bool
+
 type msgBroadcast struct {
 	ch    <-chan *domain.Message
 	token int
@@ -43,9 +76,28 @@ type ChatViewportModel struct {
 	// currently selected msg for info, we'll hide the dialog once the selMsgId is nil
 	selMsgId *string
 	// current button selection once the msg info dialog in focus,
-	// 0 -> CopyBtn | 1 -> DeleteForMeBtn | 2 -> DeleteForEveryoneBtn
-	selMsgDialogBtn int  // -1 when the selMsgId is nil
-	gotoFirstMsg    bool // once at first msg, set to false
+	// 0 -> CopyBtn | 1 -> DeleteForMeBtn | 2 -> DeleteForEveryoneBtn | 3 -> PinBtn | 4 -> StarBtn
+	selMsgDialogBtn int // -1 when the selMsgId is nil
+	// code block of the selected msg, copied alone instead of the whole body, -1 if none
+	selCodeBlock int
+	gotoFirstMsg bool // once at first msg, set to false
+	// msg bodies rendered as markdown
+	bodies renderedBodies
+	// msg selected from the search, the current find match or the pin clicked, highlighted until the selected user
+	// changes, its line is set on render
+	foundMsgID   string
+	foundMsgLine int
+	gotoFoundMsg bool // once its page is received, set to false
+	// pending scheduled msgs to the selected user, due first, shown after the msgs
+	scheduled []*domain.ScheduledMessage
+	// pinned msgs of the conversation, the latest pinned first, pinIdx is the one on the pinned strip
+	pins   []*domain.Message
+	pinIdx int
+	// in-chat find, findMatches are the ids of the msgs containing findQuery, the latest first
+	findQuery       string
+	findMatches     []string
+	findIdx         int
+	finding         bool
 	focus           bool
 	fetching        bool
 	recvTypingTimer timer.Model
@@ -61,6 +113,8 @@ func InitialChatViewport(c *client.Client) ChatViewportModel {
 		chatVp:          viewport.New(0, 0),
 		msgDialogVp:     viewport.New(0, 0),
 		msgs:            make([]*domain.Message, 0),
+		bodies:          make(renderedBodies),
+		selCodeBlock:    -1,
 		client:          c,
 		recvTypingTimer: timer.New(2 * time.Second),
 		mb: msgBroadcast{
@@ -73,7 +127,7 @@ func InitialChatViewport(c *client.Client) ChatViewportModel {
 
 func (m ChatViewportModel) Init() tea.Cmd {
 	m.fetching = true
-	return tea.Batch(m.listenForMessages(), m.recvTypingTimer.Init())
+	return tea.Batch(m.listenForMessages(), m.recvTypingTimer.Init(), cyclePins())
 }
 
 func (m ChatViewportModel) Update(msg tea.Msg) (ChatViewportModel, tea.Cmd) {
@@ -93,11 +147,40 @@ func (m ChatViewportModel) Update(msg tea.Msg) (ChatViewportModel, tea.Cmd) {
 		m.msgDialogVp.MouseWheelEnabled = false
 	}
 
+	// handled before anything may return early, the cycle would stop otherwise
+	if _, ok := msg.(pinCycleMsg); ok {
+		if len(m.pins) > 1 {
+			m.pinIdx = (m.pinIdx + 1) % len(m.pins)
+		}
+		return m, cyclePins()
+	}
+
+	// the page of the found msg is fetched instead of the first one
+	if hit, ok := msg.(selSearchHitMsg); ok {
+		m.msgs = nil
+		m.scheduled = nil
+		m.pins = nil
+		m.selUsrID = hit.usrID
+		m.selMsgId = nil
+		m.foundMsgID = hit.msgID
+		m.gotoFoundMsg = true
+		m.gotoFirstMsg = false
+		m.fetching = true
+		return m, tea.Batch(
+			m.getMsgPageOf(hit.usrID, hit.msgID),
+			m.getScheduledMsgs(hit.usrID),
+			m.getPinnedMsgs(hit.usrID),
+		)
+	}
+
 	if m.selUsrID != selUserID {
 		m.msgs = slices.Delete(m.msgs, 0, len(m.msgs))
 		m.msgs = nil
 		m.selUsrID = selUserID
-		return m, m.getMsgAsPage(1)
+		m.scheduled = nil
+		m.pins = nil
+		m.clearFind()
+		return m, tea.Batch(m.getMsgAsPage(1), m.getScheduledMsgs(selUserID), m.getPinnedMsgs(selUserID))
 	}
 
 	if m.chatVp.AtTop() && !m.fetching {
@@ -122,65 +205,49 @@ func (m ChatViewportModel) Update(msg tea.Msg) (ChatViewportModel, tea.Cmd) {
 		m.chatVp.SetContent(m.renderChatViewport())
 		return m, tea.Batch(m.handleChatViewportUpdate(msg), m.handleMsgDialogViewportUpdate(msg))
 
+	case tea.FocusMsg:
+		// the read receipts deferred while out of focus
+		if latestUnread := m.markUnreadMsgsAsRead(); latestUnread != nil {
+			m.chatVp.SetContent(m.renderChatViewport())
+			return m, m.setMsgAsRead(latestUnread)
+		}
+
 	case tea.KeyMsg:
 		var selMsg *domain.Message
 		if m.selMsgId != nil {
 			selMsg = m.getSelMsgFromMsgSlice()
 		}
 
-		switch msg.String() {
-		case "esc", "ctrl+t", "ctrl+f": // once user types or filters convos, hide the dialog
+		switch {
+		// once user types or filters convos, hide the dialog
+		case msg.String() == "esc", key.Matches(msg, keys.Conversations.FocusTyping, keys.Global.Find):
 			m.selMsgId = nil
 			m.selMsgDialogBtn = -1
-		case "tab":
+			m.selCodeBlock = -1
+		case key.Matches(msg, keys.Chat.CopyCode):
+			if selMsg != nil && m.focus {
+				m.cycleCodeBlock(selMsg)
+				m.msgDialogVp.SetContent(m.renderMsgDialogViewport())
+			}
+		case msg.String() == "tab":
 			if selMsg != nil {
-				if m.selMsgDialogBtn >= 0 && m.selMsgDialogBtn <= 2 {
-					if selMsg.SenderID == m.client.CurrentUsr.ID {
-						m.selMsgDialogBtn = (m.selMsgDialogBtn + 1) % 3
-					} else {
-						m.selMsgDialogBtn = (m.selMsgDialogBtn + 1) % 2
-					}
-				}
-				if m.selMsgDialogBtn > 2 {
-					m.selMsgDialogBtn = 0
-				}
+				m.moveMsgDialogBtn(selMsg, 1, true)
 				m.msgDialogVp.SetContent(m.renderMsgDialogViewport())
 			}
-		case "left":
+		case msg.String() == "left":
 			if selMsg != nil {
-				if m.selMsgDialogBtn > 0 && m.selMsgDialogBtn <= 2 {
-					if selMsg.SenderID == m.client.CurrentUsr.ID {
-						m.selMsgDialogBtn--
-					} else {
-						m.selMsgDialogBtn--
-						if m.selMsgDialogBtn < 0 {
-							m.selMsgDialogBtn = 0
-						}
-					}
-				}
+				m.moveMsgDialogBtn(selMsg, -1, false)
 				m.msgDialogVp.SetContent(m.renderMsgDialogViewport())
 			}
-		case "right":
+		case msg.String() == "right":
 			if selMsg != nil {
-				if m.selMsgDialogBtn >= 0 && m.selMsgDialogBtn <= 1 {
-					if selMsg.SenderID == m.client.CurrentUsr.ID {
-						m.selMsgDialogBtn++
-						if m.selMsgDialogBtn > 2 {
-							m.selMsgDialogBtn = 2
-						}
-					} else {
-						m.selMsgDialogBtn++
-						if m.selMsgDialogBtn > 1 {
-							m.selMsgDialogBtn = 1
-						}
-					}
-				}
+				m.moveMsgDialogBtn(selMsg, 1, false)
 				m.msgDialogVp.SetContent(m.renderMsgDialogViewport())
 			}
-		case "enter":
+		case msg.String() == "enter":
 			if m.selMsgId != nil {
 				if m.selMsgDialogBtn == 0 {
-					_ = clipboard.WriteAll(m.getSelMsgFromMsgSlice().Body)
+					_ = clipboard.WriteAll(m.copyTxt(selMsg))
 				}
 				if m.selMsgDialogBtn == 1 {
 					if m.selMsgId != nil {
@@ -190,15 +257,27 @@ func (m ChatViewportModel) Update(msg tea.Msg) (ChatViewportModel, tea.Cmd) {
 				if m.selMsgDialogBtn == 2 {
 					return m, m.deleteForEveryone(*m.selMsgId)
 				}
+				if m.selMsgDialogBtn == 3 {
+					return m, m.togglePin(selMsg)
+				}
+				if m.selMsgDialogBtn == 4 {
+					return m, m.toggleStar(selMsg)
+				}
 			}
 		}
 
 	case tea.MouseMsg:
 		if msg.Button == tea.MouseButtonRight && msg.Action == tea.MouseActionRelease {
+			for _, sm := range m.scheduled {
+				if zone.Get(scheduledZoneID(sm.ID)).InBounds(msg) {
+					return m, func() tea.Msg { return editScheduledMsg{sm} }
+				}
+			}
 			for _, mesg := range m.msgs {
 				if zone.Get(mesg.ID).InBounds(msg) {
 					m.selMsgId = &mesg.ID
 					m.selMsgDialogBtn = 0
+					m.selCodeBlock = -1
 					m.msgDialogVp.SetContent(m.renderMsgDialogViewport())
 					m.msgDialogVp.GotoTop() // to remove previous render scroll position
 					break
@@ -217,6 +296,24 @@ func (m ChatViewportModel) Update(msg tea.Msg) (ChatViewportModel, tea.Cmd) {
 				if zone.Get(infoDialogDelForEveryoneBtn).InBounds(msg) {
 					m.selMsgDialogBtn = 2
 				}
+				if zone.Get(infoDialogPinBtn).InBounds(msg) {
+					m.selMsgDialogBtn = 3
+				}
+				if zone.Get(infoDialogStarBtn).InBounds(msg) {
+					m.selMsgDialogBtn = 4
+				}
+				if selMsg := m.getSelMsgFromMsgSlice(); selMsg != nil {
+					for i := range codeBlocks(selMsg.Body) {
+						if zone.Get(codeBlockZoneID(i)).InBounds(msg) {
+							if m.selCodeBlock == i { // clicked again, the whole body is to be copied
+								i = -1
+							}
+							m.selCodeBlock = i
+							m.selMsgDialogBtn = 0
+							break
+						}
+					}
+				}
 				m.msgDialogVp.SetContent(m.renderMsgDialogViewport())
 			}
 		}
@@ -225,6 +322,7 @@ func (m ChatViewportModel) Update(msg tea.Msg) (ChatViewportModel, tea.Cmd) {
 			if !zone.Get(infoDialogBox).InBounds(msg) {
 				m.selMsgId = nil
 				m.selMsgDialogBtn = -1
+				m.selCodeBlock = -1
 			}
 		}
 
@@ -234,7 +332,12 @@ func (m ChatViewportModel) Update(msg tea.Msg) (ChatViewportModel, tea.Cmd) {
 		m.currPage = msg.meta.CurrentPage
 		m.lastPage = msg.meta.LastPage
 		m.chatVp.SetContent(m.renderChatViewport())
-		if !m.gotoFirstMsg {
+		if m.gotoFoundMsg {
+			m.gotoFoundMsg = false
+			// centered, not at the very top, which would fetch the next page right away
+			m.chatVp.SetYOffset(max(1, m.foundMsgLine-m.chatVp.Height/2))
+			m.prevLineCount = m.chatVp.TotalLineCount()
+		} else if !m.gotoFirstMsg {
 			// Once content is set, it goes to the top, to go to the point where the user was before
 			c := m.chatVp.TotalLineCount() - m.prevLineCount
 			m.chatVp.LineDown(c)
@@ -258,7 +361,7 @@ func (m ChatViewportModel) Update(msg tea.Msg) (ChatViewportModel, tea.Cmd) {
 				return m, tea.Batch(m.setMsgAsRead(msg), m.listenForMessages())
 			}
 
-		case domain.DeliveredMsg, domain.ReadMsg:
+		case domain.DeliveredMsg, domain.ReadMsg, domain.DeliveredUpToMsg, domain.ReadUpToMsg:
 			m.updateMsgInMsgs(msg)
 			// the above op will update the msgs so we need to rerender
 			if m.selMsgId != nil {
@@ -267,7 +370,7 @@ func (m ChatViewportModel) Update(msg tea.Msg) (ChatViewportModel, tea.Cmd) {
 				m.chatVp.SetContent(m.renderChatViewport())
 			}
 
-		case domain.DeleteMsg:
+		case domain.DeleteMsg, domain.ExpiredMsg: // expired ones are purged by the client.Client already
 			m.deleteMsgInMsgs(msg.ID)
 			// the deleted msg is selected then:
 			if m.selMsgId != nil && *m.selMsgId == msg.ID {
@@ -285,14 +388,110 @@ func (m ChatViewportModel) Update(msg tea.Msg) (ChatViewportModel, tea.Cmd) {
 				m.chatVp.LineDown(max(0, prevLineCount-currLineCount))
 			}
 
+		case domain.ScheduledSentMsg:
+			m.scheduled = slices.DeleteFunc(m.scheduled, func(sm *domain.ScheduledMessage) bool {
+				return sm.ID == msg.ID
+			})
+			if !slices.ContainsFunc(m.msgs, func(imsg *domain.Message) bool { return imsg.ID == msg.ID }) {
+				sent := *msg
+				sent.Operation = domain.CreateMsg
+				m.msgs = append([]*domain.Message{&sent}, m.msgs...)
+			}
+			m.chatVp.SetContent(m.renderChatViewport())
+			m.chatVp.GotoBottom()
+
+		case domain.PinMsg, domain.UnpinMsg:
+			cmd := m.applyPin(msg)
+			m.chatVp.SetContent(m.renderChatViewport())
+			if m.selMsgId != nil && *m.selMsgId == msg.ID {
+				m.msgDialogVp.SetContent(m.renderMsgDialogViewport())
+			}
+			return m, tea.Batch(cmd, m.listenForMessages())
+
 		case domain.TypingMsg:
 			selUserTyping = true
 
+		// the client.Client has undone the rejected op locally already, e.g. a delete or a pin, reloaded to show it
+		case domain.ErrorMsg:
+			cmds := []tea.Cmd{rejectedErr(msg), m.listenForMessages()}
+			if msg.ReceiverID == selUserID {
+				cmds = append(cmds, m.getMsgAsPage(1), m.getPinnedMsgs(selUserID))
+			}
+			return m, tea.Batch(cmds...)
+
 		default:
 		}
 
 		return m, tea.Batch(m.handleChatViewportUpdate(msg), m.handleMsgDialogViewportUpdate(msg), m.listenForMessages())
 
+	case findResult:
+		if msg.query != m.findQuery || msg.usrID != selUserID { // stale
+			return m, nil
+		}
+		m.finding = false
+		m.findMatches = msg.ids
+		m.findIdx = 0
+		return m, m.gotoFindMatch()
+
+	case scheduledMsgs:
+		if msg.usrID != selUserID { // stale
+			return m, nil
+		}
+		atBottom := m.chatVp.AtBottom()
+		m.scheduled = msg.sms
+		m.chatVp.SetContent(m.renderChatViewport())
+		if atBottom || msg.done {
+			m.chatVp.GotoBottom()
+		}
+		return m, nil
+
+	case pinnedMsgs:
+		if msg.usrID != selUserID { // stale
+			return m, nil
+		}
+		m.pins = msg.msgs
+		m.pinIdx = 0
+		pinnedAt := make(map[string]*time.Time, len(m.pins))
+		for _, pin := range m.pins {
+			pinnedAt[pin.ID] = pin.PinnedAt
+		}
+		for _, imsg := range m.msgs {
+			imsg.PinnedAt = pinnedAt[imsg.ID]
+		}
+		m.chatVp.SetContent(m.renderChatViewport())
+		if m.selMsgId != nil {
+			m.msgDialogVp.SetContent(m.renderMsgDialogViewport())
+		}
+		return m, nil
+
+	case msgStarred:
+		for _, imsg := range m.msgs {
+			if imsg.ID == msg.id {
+				imsg.StarredAt = nil
+				if msg.starred {
+					t := time.Now()
+					imsg.StarredAt = &t
+				}
+				break
+			}
+		}
+		m.chatVp.SetContent(m.renderChatViewport())
+		if m.selMsgId != nil {
+			m.msgDialogVp.SetContent(m.renderMsgDialogViewport())
+		}
+		return m, nil
+
+	case rawMsgsToggled, themeChangedMsg:
+		if _, ok := msg.(themeChangedMsg); ok {
+			m.bodies = make(renderedBodies)
+		}
+		atBottom := m.chatVp.AtBottom()
+		m.chatVp.SetContent(m.renderChatViewport())
+		if atBottom {
+			m.chatVp.GotoBottom()
+		}
+		return m, nil
+
 	case msgSetAsReadSuccessMsg:
 
 	case SentMsg: // the message we'll send gets here
@@ -384,8 +583,15 @@ func (m *ChatViewportModel) renderChatViewport() string {
 			Align(align).
 			Render(m.renderBubbleWithStatusInfo(msg))
 		sb.WriteString("\n")
+		if msg.ID == m.foundMsgID {
+			m.foundMsgLine = strings.Count(sb.String(), "\n")
+		}
 		sb.WriteString(cb)
 	}
+	for _, sm := range m.scheduled {
+		sb.WriteString("\n")
+		sb.WriteString(cb.Align(lipgloss.Right).Render(m.renderScheduledBubble(sm)))
+	}
 	return sb.String()
 }
 
@@ -405,7 +611,12 @@ func (m ChatViewportModel) renderMsgDialogViewport() string {
 		Width(chatWidth() - msgInfoBodyStyle.GetHorizontalFrameSize()).
 		Render(infoMsg.Body)
 
-	copyBtn := zone.Mark(infoDialogCopyBtn, renderCopyBtn(m.selMsgDialogBtn))
+	copyTxt := "COPY"
+	blocks := codeBlocks(infoMsg.Body)
+	if m.selCodeBlock >= 0 && m.selCodeBlock < len(blocks) {
+		copyTxt = fmt.Sprintf("COPY CODE %d", m.selCodeBlock+1)
+	}
+	copyBtn := zone.Mark(infoDialogCopyBtn, renderCopyBtn(m.selMsgDialogBtn, copyTxt))
 	delBtn := zone.Mark(infoDialogDelForMeBtn, renderDeleteBtn(false, "DELETE"))
 	delForMeFocus := false
 	delForEveryoneFocus := false
@@ -417,20 +628,29 @@ func (m ChatViewportModel) renderMsgDialogViewport() string {
 	}
 	delForMeBtn := zone.Mark(infoDialogDelForMeBtn, renderDeleteBtn(delForMeFocus, "DELETE FOR ME"))
 	delForEveryoneBtn := zone.Mark(infoDialogDelForEveryoneBtn, renderDeleteBtn(delForEveryoneFocus, "DELETE FOR EVERYONE"))
+	pinTxt, starTxt := "PIN", "STAR"
+	if infoMsg.PinnedAt != nil {
+		pinTxt = "UNPIN"
+	}
+	if infoMsg.StarredAt != nil {
+		starTxt = "UNSTAR"
+	}
+	pinBtn := zone.Mark(infoDialogPinBtn, renderMarkBtn(m.selMsgDialogBtn == 3, "📌 "+pinTxt))
+	starBtn := zone.Mark(infoDialogStarBtn, renderMarkBtn(m.selMsgDialogBtn == 4, "★ "+starTxt))
 
-	if m.selMsgDialogBtn == 0 {
-		btnContainer = msgInfoContainerBtn.Render(copyBtn, delBtn)
+	if !delForMeFocus && !delForEveryoneFocus {
+		btnContainer = msgInfoContainerBtn.Render(copyBtn, pinBtn, starBtn, delBtn)
 	} else {
-		btnContainer = msgInfoContainerBtn.Render(copyBtn, delForMeBtn, delForEveryoneBtn)
+		btnContainer = msgInfoContainerBtn.Render(copyBtn, pinBtn, starBtn, delForMeBtn, delForEveryoneBtn)
 		if infoMsg.SenderID != m.client.CurrentUsr.ID {
-			btnContainer = msgInfoContainerBtn.Render(copyBtn, delForMeBtn)
+			btnContainer = msgInfoContainerBtn.Render(copyBtn, pinBtn, starBtn, delForMeBtn)
 		}
 	}
 
 	status := renderInfoMsgStatus(infoMsg)
 	foot = msgInfoFooterStyle.Render(status)
 
-	return head + body + btnContainer + foot
+	return head + body + m.renderCodeBlocks(blocks) + btnContainer + foot
 }
 
 func renderInfoMsgStatus(msg *domain.Message) string {
@@ -454,7 +674,7 @@ func renderInfoMsgStatus(msg *domain.Message) string {
 	return sb.String()
 }
 
-func renderCopyBtn(selBtnIdx int) string {
+func renderCopyBtn(selBtnIdx int, btnTxt string) string {
 	bg := primaryColor
 	fg := primaryContrastColor
 	if selBtnIdx != 0 {
@@ -465,7 +685,21 @@ func renderCopyBtn(selBtnIdx int) string {
 		Background(bg).
 		Foreground(fg).
 		Padding(0, 3).
-		Render("COPY")
+		Render(btnTxt)
+}
+
+// renderMarkBtn renders the pin & the star buttons
+func renderMarkBtn(focus bool, btnTxt string) string {
+	bg := primaryColor
+	fg := primaryContrastColor
+	if !focus {
+		bg = darkGreyColor
+		fg = lightGreyColor
+	}
+	return msgInfoBtnStyle.
+		Background(bg).
+		Foreground(fg).
+		Render(btnTxt)
 }
 
 func renderDeleteBtn(focus bool, btnTxt string) string {
@@ -491,9 +725,29 @@ func (m *ChatViewportModel) getSelMsgFromMsgSlice() *domain.Message {
 }
 
 func (m *ChatViewportModel) renderBubbleWithStatusInfo(msg *domain.Message) string {
-	txtWidth := min(chatWidth()-20, lipgloss.Width(msg.Body)+2)
-	bubble := chatBubbleLStyle.Width(txtWidth).Render(msg.Body)
-	sentAt := lipgloss.NewStyle().Faint(true).Foreground(whiteColor).SetString(msg.SentAt.Format(time.Kitchen))
+	own := msg.SenderID == m.client.CurrentUsr.ID
+	style, fg := chatBubbleLStyle, whiteColor
+	if own {
+		style, fg = chatBubbleRStyle, primaryColor
+	}
+	if msg.ID == m.foundMsgID {
+		style = style.BorderForeground(orangeColor)
+	}
+	maxWidth := chatWidth() - 20
+	body := m.renderBody(msg, maxWidth-style.GetHorizontalPadding(), fg)
+	txtWidth := min(maxWidth, lipgloss.Width(body)+style.GetHorizontalPadding())
+	bubble := style.Width(txtWidth).Render(body)
+	sentAtStr := msg.SentAt.Format(time.Kitchen)
+	if msg.ExpiresAt != nil { // disappears
+		sentAtStr = "⏱ " + sentAtStr
+	}
+	if msg.StarredAt != nil {
+		sentAtStr = "★ " + sentAtStr
+	}
+	if msg.PinnedAt != nil {
+		sentAtStr = "📌 " + sentAtStr
+	}
+	sentAt := lipgloss.NewStyle().Faint(true).Foreground(whiteColor).SetString(sentAtStr)
 	var status string
 	if msg.SentAt != nil {
 		status = "⁎"
@@ -506,8 +760,7 @@ func (m *ChatViewportModel) renderBubbleWithStatusInfo(msg *domain.Message) stri
 	}
 	status = lipgloss.NewStyle().Faint(true).Foreground(primaryColor).Render(status)
 
-	if msg.SenderID == m.client.CurrentUsr.ID {
-		bubble = chatBubbleRStyle.Width(txtWidth).Render(msg.Body)
+	if own {
 		// mark the msg with zone on the right side so we can pick these up using mouse clicks
 		bubble = zone.Mark(msg.ID, bubble)
 		sentAt = sentAt.Foreground(primaryColor)
@@ -518,6 +771,59 @@ func (m *ChatViewportModel) renderBubbleWithStatusInfo(msg *domain.Message) stri
 	return lipgloss.JoinHorizontal(lipgloss.Center, bubble, " ", sentAt.Render())
 }
 
+// renderBody renders the body of msg as markdown in fg wrapped at width, or as typed if the user prefers the raw
+// msgs, the find matches are highlighted on the body as typed
+func (m *ChatViewportModel) renderBody(msg *domain.Message, width int, fg lipgloss.AdaptiveColor) string {
+	if m.findQuery != "" && strings.Contains(strings.ToLower(msg.Body), strings.ToLower(m.findQuery)) {
+		return highlightMatches(msg.Body, m.findQuery, lipgloss.NewStyle().Foreground(fg))
+	}
+	if m.client.RawMsgs() {
+		return msg.Body
+	}
+	return m.bodies.get(msg.ID, msg.Body, width, fg)
+}
+
+// renderCodeBlocks renders the code blocks of the selected msg to pick the one copied, the selected one is outlined
+func (m ChatViewportModel) renderCodeBlocks(blocks []codeBlock) string {
+	var sb strings.Builder
+	width := chatWidth() - msgInfoCodeBlockStyle.GetHorizontalFrameSize()
+	for i, b := range blocks {
+		style := msgInfoCodeBlockStyle
+		if i == m.selCodeBlock {
+			style = msgInfoSelCodeBlockStyle
+		}
+		title := fmt.Sprintf("CODE %d", i+1)
+		if b.lang != "" {
+			title += " · " + b.lang
+		}
+		title = msgInfoCodeBlockTitleStyle.Render(title)
+		sb.WriteString(zone.Mark(codeBlockZoneID(i), style.Width(width).Render(title+"\n"+b.code)))
+	}
+	return sb.String()
+}
+
+// renderScheduledBubble renders the pending msg faded on the right side, marked with the clock & the time it is due
+func (m *ChatViewportModel) renderScheduledBubble(sm *domain.ScheduledMessage) string {
+	txtWidth := min(chatWidth()-20, lipgloss.Width(sm.Body)+2)
+	bubble := chatScheduledBubbleStyle.Inherit(chatBubbleRStyle).Width(txtWidth).Render(sm.Body)
+	bubble = zone.Mark(scheduledZoneID(sm.ID), bubble)
+	sendAt := sm.SendAt.In(time.Local)
+	f := time.Kitchen
+	if y, d := sendAt.YearDay(), time.Now().YearDay(); y != d || sendAt.Year() != time.Now().Year() {
+		f = "Jan 02 " + time.Kitchen
+	}
+	due := lipgloss.NewStyle().Faint(true).Foreground(primaryColor).Render("⏰ " + sendAt.Format(f))
+	return lipgloss.JoinHorizontal(lipgloss.Center, due, " ", bubble)
+}
+
+func scheduledZoneID(id string) string {
+	return "sched_" + id
+}
+
+func codeBlockZoneID(i int) string {
+	return fmt.Sprintf("%v%d", infoDialogCodeBlock, i)
+}
+
 func (m *ChatViewportModel) updateDimensions() {
 	w := chatWidth()
 	h := chatHeight() - (chatHeaderHeight + chatTextareaHeight)
@@ -556,7 +862,9 @@ func (m ChatViewportModel) listenForMessages() tea.Cmd {
 
 func (m ChatViewportModel) getMsgAsPage(p int) tea.Cmd {
 	return func() tea.Msg {
-		msgs, meta, err := m.client.GetMessagesAsPageAndMarkAsRead(selUserID, p)
+		// nil checks, if the terminal focus is not supported, just set the msgs as read
+		markAsRead := terminalFocus == nil || *terminalFocus
+		msgs, meta, err := m.client.GetMessagesAsPage(selUserID, p, markAsRead)
 		if err != nil {
 			return &errMsg{
 				err:  "Unable to fetch initial chat for this user...",
@@ -567,7 +875,43 @@ func (m ChatViewportModel) getMsgAsPage(p int) tea.Cmd {
 	}
 }
 
+func (m ChatViewportModel) getScheduledMsgs(usrID string) tea.Cmd {
+	return func() tea.Msg {
+		return fetchScheduledMsgs(m.client, usrID, false)
+	}
+}
+
+// fetchScheduledMsgs fetches the pending scheduled msgs to usrID, offline there are none to show
+func fetchScheduledMsgs(c *client.Client, usrID string, done bool) tea.Msg {
+	sms, code, err := c.GetScheduledMessages(usrID)
+	if code == http.StatusUnauthorized {
+		return requireAuthMsg{}
+	}
+	if err != nil || code != http.StatusOK {
+		sms = nil
+	}
+	return scheduledMsgs{usrID: usrID, sms: sms, done: done}
+}
+
+func (m ChatViewportModel) getMsgPageOf(usrID, msgID string) tea.Cmd {
+	return func() tea.Msg {
+		markAsRead := terminalFocus == nil || *terminalFocus
+		msgs, meta, err := m.client.GetMessagesPageOf(usrID, msgID, markAsRead)
+		if err != nil {
+			return &errMsg{
+				err:  "Unable to fetch the chat of this message...",
+				code: 0,
+			}
+		}
+		return msgPage{msgs, meta}
+	}
+}
+
 func (m *ChatViewportModel) updateMsgInMsgs(msg *domain.Message) {
+	if msg.Operation.IsWatermark() {
+		m.updateMsgsUpTo(msg)
+		return
+	}
 	for i, imsg := range m.msgs {
 		if imsg.ID == msg.ID {
 			switch msg.Operation {
@@ -582,6 +926,28 @@ func (m *ChatViewportModel) updateMsgInMsgs(msg *domain.Message) {
 	}
 }
 
+// updateMsgsUpTo applies the watermark to every msg of the conversation sent up to it, keeping the fields already set,
+// compared to the millisecond same as repository.LocalMessageRepository.UpdateMsgsUpTo
+func (m *ChatViewportModel) updateMsgsUpTo(msg *domain.Message) {
+	if msg.UpTo == nil {
+		return
+	}
+	upTo := msg.UpTo.Truncate(time.Millisecond)
+	author, recipient := msg.Parties()
+	for _, imsg := range m.msgs {
+		if imsg.SenderID != author || imsg.ReceiverID != recipient ||
+			imsg.SentAt == nil || imsg.SentAt.Truncate(time.Millisecond).After(upTo) {
+			continue
+		}
+		if imsg.DeliveredAt == nil {
+			imsg.DeliveredAt = msg.DeliveredAt
+		}
+		if imsg.ReadAt == nil {
+			imsg.ReadAt = msg.ReadAt
+		}
+	}
+}
+
 func (m ChatViewportModel) setMsgAsRead(msg *domain.Message) tea.Cmd {
 	return func() tea.Msg {
 		// ignore the error
@@ -590,7 +956,26 @@ func (m ChatViewportModel) setMsgAsRead(msg *domain.Message) tea.Cmd {
 	}
 }
 
-// once the message is deleted, this deletes it from the msg slice, if not exists -> NOOP
+// markUnreadMsgsAsRead sets the unread msgs of the selected user as read, returning the latest of them, if any
+func (m *ChatViewportModel) markUnreadMsgsAsRead() *domain.Message {
+	var latestUnread *domain.Message
+	t := time.Now()
+	for _, msg := range m.msgs {
+		if msg.SenderID != selUserID || msg.ReadAt != nil {
+			continue
+		}
+		if msg.DeliveredAt == nil {
+			msg.DeliveredAt = &t
+		}
+		msg.ReadAt = &t
+		if latestUnread == nil { // msgs are ordered by sent_at DESC
+			latestUnread = msg
+		}
+	}
+	return latestUnread
+}
+
+// once the message is deleted, this deletes it from the msg slice & the pins, if not exists -> NOOP
 func (m *ChatViewportModel) deleteMsgInMsgs(msgId string) {
 	for i, mesg := range m.msgs {
 		if mesg.ID == msgId {
@@ -599,6 +984,7 @@ func (m *ChatViewportModel) deleteMsgInMsgs(msgId string) {
 			break
 		}
 	}
+	m.pins = slices.DeleteFunc(m.pins, func(pin *domain.Message) bool { return pin.ID == msgId })
 }
 
 func (m ChatViewportModel) deleteForMe(msgId string) tea.Cmd {
@@ -638,3 +1024,285 @@ func (m ChatViewportModel) deleteForEveryone(msgId string) tea.Cmd {
 		return deleteMsgSuccess(msgId)
 	}
 }
+
+// cycleCodeBlock selects the next code block of msg to be copied alone, after the last one the whole body is again
+func (m *ChatViewportModel) cycleCodeBlock(msg *domain.Message) {
+	n := len(codeBlocks(msg.Body))
+	if n == 0 {
+		return
+	}
+	m.selCodeBlock++
+	if m.selCodeBlock >= n {
+		m.selCodeBlock = -1
+	}
+	m.selMsgDialogBtn = 0
+}
+
+// copyTxt is the selected code block of msg if any, its whole body otherwise
+func (m *ChatViewportModel) copyTxt(msg *domain.Message) string {
+	if msg == nil {
+		return ""
+	}
+	if blocks := codeBlocks(msg.Body); m.selCodeBlock >= 0 && m.selCodeBlock < len(blocks) {
+		return blocks[m.selCodeBlock].code
+	}
+	return msg.Body
+}
+
+func (m ChatViewportModel) toggleRawMsgs() tea.Cmd {
+	raw := !m.client.RawMsgs()
+	return func() tea.Msg {
+		if err := m.client.SetRawMsgs(raw); err != nil {
+			return &errMsg{
+				err:  "Unable to save the preference",
+				code: 0,
+			}
+		}
+		return rawMsgsToggled(raw)
+	}
+}
+
+// msgDialogBtns are the buttons of the msg info dialog in their order, deleting for everyone is for own msgs only
+func (m *ChatViewportModel) msgDialogBtns(msg *domain.Message) []int {
+	if msg.SenderID == m.client.CurrentUsr.ID {
+		return []int{0, 3, 4, 1, 2}
+	}
+	return []int{0, 3, 4, 1}
+}
+
+// moveMsgDialogBtn moves the button selection of the msg info dialog by step, wrapping around if wrap
+func (m *ChatViewportModel) moveMsgDialogBtn(msg *domain.Message, step int, wrap bool) {
+	btns := m.msgDialogBtns(msg)
+	i := slices.Index(btns, m.selMsgDialogBtn)
+	if i == -1 {
+		return
+	}
+	i += step
+	if wrap {
+		i = (i + len(btns)) % len(btns)
+	} else {
+		i = max(0, min(i, len(btns)-1))
+	}
+	m.selMsgDialogBtn = btns[i]
+}
+
+func (m ChatViewportModel) togglePin(msg *domain.Message) tea.Cmd {
+	pin := msg.PinnedAt == nil
+	pinned := *msg
+	usrID := selUserID
+	return func() tea.Msg {
+		if m.client.WsConnState.Get() != client.Connected {
+			return &errMsg{
+				err:  "No Connection, unable to pin message.",
+				code: http.StatusRequestTimeout,
+			}
+		}
+		if err := m.client.PinMsg(&pinned, pin); err != nil {
+			return &errMsg{
+				err:  "Unable to pin this message",
+				code: 0,
+			}
+		}
+		return fetchPinnedMsgs(m.client, usrID)
+	}
+}
+
+func (m ChatViewportModel) toggleStar(msg *domain.Message) tea.Cmd {
+	star := msg.StarredAt == nil
+	id := msg.ID
+	return func() tea.Msg {
+		if err := m.client.StarMsg(id, star); err != nil {
+			return &errMsg{
+				err:  "Unable to star this message",
+				code: 0,
+			}
+		}
+		return msgStarred{id: id, starred: star}
+	}
+}
+
+// applyPin applies the PinMsg or UnpinMsg of the other user to the loaded msgs & the pins, the pins are fetched again
+// if the pinned msg is not loaded
+func (m *ChatViewportModel) applyPin(msg *domain.Message) tea.Cmd {
+	m.pins = slices.DeleteFunc(m.pins, func(pin *domain.Message) bool { return pin.ID == msg.ID })
+	m.pinIdx = 0
+	i := slices.IndexFunc(m.msgs, func(imsg *domain.Message) bool { return imsg.ID == msg.ID })
+	if msg.Operation == domain.UnpinMsg {
+		if i != -1 {
+			m.msgs[i].PinnedAt = nil
+		}
+		return nil
+	}
+	if i == -1 {
+		return m.getPinnedMsgs(selUserID)
+	}
+	m.msgs[i].PinnedAt = msg.PinnedAt
+	pin := *m.msgs[i]
+	m.pins = append([]*domain.Message{&pin}, m.pins...)
+	return nil
+}
+
+// currentPin is the pin on the pinned strip, its index & the count of the pins, nil if there is none
+func (m ChatViewportModel) currentPin() (*domain.Message, int, int) {
+	if len(m.pins) == 0 || m.selUsrID != selUserID {
+		return nil, 0, 0
+	}
+	i := min(m.pinIdx, len(m.pins)-1)
+	return m.pins[i], i, len(m.pins)
+}
+
+func (m ChatViewportModel) getPinnedMsgs(usrID string) tea.Cmd {
+	return func() tea.Msg {
+		return fetchPinnedMsgs(m.client, usrID)
+	}
+}
+
+func fetchPinnedMsgs(c *client.Client, usrID string) tea.Msg {
+	msgs, err := c.GetPinnedMessages(usrID)
+	if err != nil {
+		return &errMsg{
+			err:  "Unable to fetch the pinned messages...",
+			code: 0,
+		}
+	}
+	return pinnedMsgs{usrID: usrID, msgs: msgs}
+}
+
+func cyclePins() tea.Cmd {
+	return tea.Tick(pinCycleInterval, func(time.Time) tea.Msg { return pinCycleMsg{} })
+}
+
+type findResult struct {
+	query, usrID string
+	ids          []string
+}
+
+// find looks for the query in every page of the conversation, the ones not loaded yet too
+func (m *ChatViewportModel) find(query string) tea.Cmd {
+	m.clearFind()
+	m.findQuery = query
+	m.finding = query != ""
+	m.chatVp.SetContent(m.renderChatViewport())
+	if query == "" {
+		return nil
+	}
+	c, usrID := m.client, selUserID
+	return func() tea.Msg {
+		q := strings.ToLower(query)
+		ids := make([]string, 0)
+		for p, last := 1, 1; p <= last; p++ {
+			msgs, meta, err := c.GetMessagesAsPage(usrID, p, false)
+			if err != nil {
+				return &errMsg{err: "Unable to find in this chat...", code: 0}
+			}
+			for _, msg := range msgs {
+				if strings.Contains(strings.ToLower(msg.Body), q) {
+					ids = append(ids, msg.ID)
+				}
+			}
+			last = meta.LastPage
+		}
+		return findResult{query: query, usrID: usrID, ids: ids}
+	}
+}
+
+// nextFindMatch moves by step through the matches wrapping around, a positive step moves to the older ones
+func (m *ChatViewportModel) nextFindMatch(step int) tea.Cmd {
+	if len(m.findMatches) == 0 {
+		return nil
+	}
+	m.findIdx = (m.findIdx + step + len(m.findMatches)) % len(m.findMatches)
+	return m.gotoFindMatch()
+}
+
+// gotoFindMatch scrolls to the current match, fetching its page if not loaded
+func (m *ChatViewportModel) gotoFindMatch() tea.Cmd {
+	if len(m.findMatches) == 0 {
+		m.foundMsgID = ""
+		m.chatVp.SetContent(m.renderChatViewport())
+		return nil
+	}
+	m.foundMsgID = m.findMatches[m.findIdx]
+	return m.scrollToFoundMsg()
+}
+
+// gotoPinnedMsg scrolls to the msg on the pinned strip, fetching its page if not loaded
+func (m *ChatViewportModel) gotoPinnedMsg() tea.Cmd {
+	pin, _, _ := m.currentPin()
+	if pin == nil {
+		return nil
+	}
+	m.foundMsgID = pin.ID
+	return m.scrollToFoundMsg()
+}
+
+// scrollToFoundMsg scrolls to foundMsgID, fetching its page if not loaded
+func (m *ChatViewportModel) scrollToFoundMsg() tea.Cmd {
+	if slices.ContainsFunc(m.msgs, func(msg *domain.Message) bool { return msg.ID == m.foundMsgID }) {
+		m.chatVp.SetContent(m.renderChatViewport())
+		m.chatVp.SetYOffset(max(1, m.foundMsgLine-m.chatVp.Height/2))
+		return nil
+	}
+	m.gotoFoundMsg = true
+	m.fetching = true
+	return m.getMsgPageOf(selUserID, m.foundMsgID)
+}
+
+func (m *ChatViewportModel) clearFind() {
+	m.findQuery = ""
+	m.findMatches = nil
+	m.findIdx = 0
+	m.finding = false
+	m.foundMsgID = ""
+}
+
+// findStatus is the "n of m" of the current match
+func (m ChatViewportModel) findStatus() string {
+	switch {
+	case m.finding:
+		return "finding..."
+	case m.findQuery == "":
+		return ""
+	case len(m.findMatches) == 0:
+		return "no matches"
+	}
+	return fmt.Sprintf("%v of %v", m.findIdx+1, len(m.findMatches))
+}
+
+// highlightMatches highlights each occurrence of the query in s ignoring case, the rest is rendered with base as the
+// highlights would otherwise reset the style of the bubble after them
+func highlightMatches(s, query string, base lipgloss.Style) string {
+	ls, lq := strings.ToLower(s), strings.ToLower(query)
+	if len(ls) != len(s) { // lowering changed the byte offsets
+		return s
+	}
+	// line by line, rendering multiple lines at once pads them to the same width
+	render := func(t string) string {
+		lines := strings.Split(t, "\n")
+		for i, l := range lines {
+			if l != "" {
+				lines[i] = base.Render(l)
+			}
+		}
+		return strings.Join(lines, "\n")
+	}
+	var sb strings.Builder
+	for {
+		i := strings.Index(ls, lq)
+		if i < 0 {
+			sb.WriteString(render(s))
+			return sb.String()
+		}
+		sb.WriteString(render(s[:i]))
+		sb.WriteString(chatFindMatchStyle.Render(s[i : i+len(lq)]))
+		s, ls = s[i+len(lq):], ls[i+len(lq):]
+	}
+}
+
+// rejectedErr shows the reasons the server has rejected the msg operation for
+func rejectedErr(msg *domain.Message) tea.Cmd {
+	reasons := slices.Sorted(maps.Values(msg.Errors))
+	return func() tea.Msg {
+		return &errMsg{err: "Rejected: " + strings.Join(reasons, ", "), code: 0}
+	}
+}
@@ -33,11 +33,22 @@ type resetSpinnerMsg struct{}
 func spinnerResetCmd() tea.Msg { return resetSpinnerMsg{} }
 
 type selDiscUserMsg struct { // selected Discovered User Msg
-	id, name, email string
+	id, name, handle, email string
+	isBot                   bool
+}
+
+type selSearchHitMsg struct { // selected Search Hit Msg, usrID being the conversation partner
+	usrID, username, msgID string
 }
 
 type SentMsg *domain.Message
 
+type editScheduledMsg struct { // the pending scheduled msg right-clicked, to be edited in the compose box
+	sm *domain.ScheduledMessage
+}
+
+type scheduleFailed string // the reason shown on the schedule bar
+
 type echoTypingMsg struct{}
 
 type msgSetAsReadSuccessMsg struct{}
@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"cmp"
 	"fmt"
 	"github.com/MuhamedUsman/letschat/internal/client"
 	"github.com/MuhamedUsman/letschat/internal/domain"
@@ -48,10 +49,13 @@ type ConversationModel struct {
 	cb                  convosBroadcast
 }
 
-type conversationItem struct{ id, selConvoUsrId, title, unreadMsgsCount, status, latestMsg string }
+// convoMuted is the status shown once the conversation is muted or unmuted
+type convoMuted string
+
+type conversationItem struct{ id, selConvoUsrId, title, badge, unreadMsgsCount, status, latestMsg string }
 
 func (i conversationItem) Title() string {
-	return zone.Mark(i.id, fmt.Sprint(i.title, i.unreadMsgsCount, i.status))
+	return zone.Mark(i.id, fmt.Sprint(i.title, i.badge, i.unreadMsgsCount, i.status))
 }
 func (i conversationItem) FilterValue() string {
 	return zone.Mark(i.id, fmt.Sprintf("%v|%v", i.title, i.selConvoUsrId))
@@ -120,9 +124,9 @@ func (m ConversationModel) Update(msg tea.Msg) (ConversationModel, tea.Cmd) {
 		m.rerenderTimer.Timeout = 10 * time.Second
 		m.rerenderTimer.Start()
 		var cmds []tea.Cmd
-		cmds = append(cmds, m.conversationList.SetItems(m.populateConvos()))
+		cmds = append(cmds, m.conversationList.SetItems(m.populateConvos()), m.setUnreadTitle())
 		if m.selDiscUserConvo != nil {
-			cmds = append(cmds, m.conversationList.InsertItem(0, populateConvoItem(0, m.selDiscUserConvo, false)))
+			cmds = append(cmds, m.conversationList.InsertItem(0, populateConvoItem(0, m.selDiscUserConvo, false, false)))
 		}
 		return m, tea.Batch(cmds...)
 	}
@@ -146,30 +150,38 @@ func (m ConversationModel) Update(msg tea.Msg) (ConversationModel, tea.Cmd) {
 		var cmd []tea.Cmd
 		cmd = append(cmd, m.conversationList.SetItems(m.populateConvos()))
 		if m.selDiscUserConvo != nil {
-			cmd = append(cmd, m.conversationList.InsertItem(0, populateConvoItem(0, m.selDiscUserConvo, false)))
+			cmd = append(cmd, m.conversationList.InsertItem(0, populateConvoItem(0, m.selDiscUserConvo, false, false)))
 		}
 		return m, tea.Batch(cmd...)
 
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "enter":
+		switch {
+		case msg.String() == "enter":
 			if m.focus {
 				selUserID = m.getSelConvoUsrID()
 				selUsername = m.getSelConvoUsername()
 				m.selConvoItemIdx = m.conversationList.Index()
 				m.handleConvoItemSelection()
 			}
-		case "ctrl+f":
+		case key.Matches(msg, keys.Global.Find):
 			if m.focus {
 				return m, tea.Batch(m.conversationList.FilterInput.Focus(), m.handleConversationListUpdate(msg))
 			}
-		case "ctrl+t":
+		case key.Matches(msg, keys.Conversations.FocusTyping):
 			m.conversationList.FilterInput.Blur()
-		case "ctrl+s":
+		case key.Matches(msg, keys.Conversations.Busy):
+			if m.focus {
+				return m, m.toggleDoNotDisturb()
+			}
+		case key.Matches(msg, keys.Conversations.Mute):
+			if m.focus && m.getSelConvoUsrID() != "" {
+				return m, m.toggleMute(m.getSelConvoUsrID())
+			}
+		case msg.String() == "ctrl+s":
 			if validMsgForSend {
 				m.selDiscUserConvo = nil
 			}
-		case "esc":
+		case msg.String() == "esc":
 			m.conversationList.FilterInput.Blur()
 		}
 
@@ -207,7 +219,8 @@ func (m ConversationModel) Update(msg tea.Msg) (ConversationModel, tea.Cmd) {
 				}
 			}
 			if zone.Get(conversationSearchBar).InBounds(msg) {
-				return m, m.handleConversationListUpdate(tea.KeyMsg{Type: tea.KeyCtrlF})
+				find, _ := keyMsg(keys.Global.Find) // filtering is not started if unbound
+				return m, m.handleConversationListUpdate(find)
 			} else {
 				m.conversationList.FilterInput.Blur()
 				return m, m.handleConversationListUpdate(tea.KeyMsg{Type: tea.KeyEsc})
@@ -252,15 +265,26 @@ func (m ConversationModel) Update(msg tea.Msg) (ConversationModel, tea.Cmd) {
 			m.selDiscUserConvo = nil
 		}
 		if m.selDiscUserConvo != nil { // if the conversation list refreshes when there is temporary discover user selected
-			cmds[1] = m.conversationList.InsertItem(0, populateConvoItem(0, m.selDiscUserConvo, false))
+			cmds[1] = m.conversationList.InsertItem(0, populateConvoItem(0, m.selDiscUserConvo, false, false))
 		}
 		return m, tea.Batch(
 			tea.Sequence(cmds...),
 			spinnerResetCmd,
 			m.getConversations(), // to continue the loop
 			m.conversationList.NewStatusMessage("Updated Conversations"),
+			m.setUnreadTitle(),
 		)
 
+	case convoMuted:
+		m.rerenderTimer.Timeout = 0 // this will rerender the convos
+		return m, m.conversationList.NewStatusMessage(string(msg))
+
+	case themeChangedMsg:
+		m.conversationList.SetDelegate(getDelegateWithCustomStyling())
+		m.conversationList = applyCustomConversationListStyling(m.conversationList)
+		restyleTxtInput(&m.conversationList.FilterInput)
+		m.rerenderTimer.Timeout = 0 // this will rerender the convos
+
 	case selDiscUserMsg:
 		// there is previously discovered user set in the conversation list, we'll remove that before entering a new
 		if len(m.conversationList.Items()) > len(m.convos) {
@@ -286,15 +310,34 @@ func (m ConversationModel) Update(msg tea.Msg) (ConversationModel, tea.Cmd) {
 			Username:   msg.name,
 			UserEmail:  msg.email,
 			LastOnline: &t,
+			UserIsBot:  msg.isBot,
+		}
+		if msg.handle != "" {
+			convo.UserHandle = &msg.handle
 		}
+		selDiscUserHandle = msg.handle
 		m.selDiscUserConvo = convo
 		selUserID = msg.id
 		selUsername = msg.name
-		cmd := m.conversationList.InsertItem(0, populateConvoItem(0, convo, false))
+		cmd := m.conversationList.InsertItem(0, populateConvoItem(0, convo, false, false))
 		m.conversationList.Select(0)
 		m.selConvoItemIdx = m.conversationList.Index()
 		return m, cmd
 
+	case selSearchHitMsg:
+		for i, item := range m.conversationList.Items() {
+			if extractUsrId(item.FilterValue()) == msg.usrID {
+				m.conversationList.Select(i)
+				m.selConvoItemIdx = i
+				m.handleConvoItemSelection()
+				return m, nil
+			}
+		}
+		// the conversation is gone, its msgs are still shown
+		selUserID = msg.usrID
+		selUsername = cmp.Or(msg.username, "Unknown")
+		return m, nil
+
 	}
 
 	return m, tea.Batch(m.handleConversationListUpdate(msg))
@@ -316,8 +359,7 @@ func (m ConversationModel) View() string {
 
 func newConversationTxtInput(placeholder string) textinput.Model {
 	ti := textinput.New()
-	ti.TextStyle = lipgloss.NewStyle().Foreground(primaryColor)
-	ti.Cursor.Style = lipgloss.NewStyle().Foreground(primaryColor)
+	restyleTxtInput(&ti)
 	ti.CharLimit = 64
 	ti.Prompt = ""
 	ti.Placeholder = placeholder
@@ -326,6 +368,8 @@ func newConversationTxtInput(placeholder string) textinput.Model {
 
 func getDelegateWithCustomStyling() list.ItemDelegate {
 	d := list.NewDefaultDelegate()
+	// name, profile status & the latest msg
+	d.SetHeight(3)
 
 	d.Styles.SelectedTitle = d.Styles.SelectedTitle.
 		Foreground(primaryColor).
@@ -360,7 +404,7 @@ func applyCustomConversationListStyling(m list.Model) list.Model {
 
 func getConversationListKeyMap(enabled bool) list.KeyMap {
 	km := list.DefaultKeyMap()
-	km.Filter = key.NewBinding(key.WithKeys("ctrl+f"), key.WithHelp("ctrl+f", "filter by name"))
+	km.Filter = keys.Global.Find
 	kb := key.NewBinding() // disable keybindings when out of focus
 	km.Quit = kb           // default
 	km.ForceQuit = kb      // default
@@ -384,13 +428,13 @@ func (m ConversationModel) populateConvos() []list.Item {
 		if m.client.WsConnState.Get() == client.Connected {
 			renderState = true
 		}
-		item := populateConvoItem(i, convo, renderState)
+		item := populateConvoItem(i, convo, renderState, m.client.Muted(convo.UserID))
 		c = append(c, item)
 	}
 	return c
 }
 
-func populateConvoItem(i int, convo *domain.Conversation, renderState bool) conversationItem {
+func populateConvoItem(i int, convo *domain.Conversation, renderState, muted bool) conversationItem {
 	id := "item_" + strconv.Itoa(i)
 	var latestMsg string
 	if convo.LatestMsg != nil {
@@ -398,6 +442,12 @@ func populateConvoItem(i int, convo *domain.Conversation, renderState bool) conv
 	} else {
 		latestMsg = "..."
 	}
+	// rendered under the name
+	if status := renderProfileStatus(convo.StatusEmoji, convo.StatusText, convo.StatusExpiresAt); status != "" {
+		latestMsg = status + "\n" + latestMsg
+	} else if convo.Bio != "" {
+		latestMsg = convo.Bio + "\n" + latestMsg
+	}
 	var s string
 	if renderState {
 		s = renderStateInfo(convo)
@@ -408,21 +458,91 @@ func populateConvoItem(i int, convo *domain.Conversation, renderState bool) conv
 		count = lipgloss.NewStyle().Foreground(greenColor).Render(count)
 		latestMsg = lipgloss.NewStyle().Foreground(primarySubtleDarkColor).Italic(true).Render(latestMsg)
 	}
-	widthBetweenUsernameAndStatus := conversationWidth() - (lipgloss.Width(convo.Username) + lipgloss.Width(count) + 5)
+	var badge string
+	if convo.UserIsBot {
+		badge = conversationBotBadge
+	}
+	if muted {
+		badge += conversationMutedBadge
+	}
+	widthBetweenUsernameAndStatus := conversationWidth() -
+		(lipgloss.Width(convo.Username) + lipgloss.Width(badge) + lipgloss.Width(count) + 5)
 	s = lipgloss.NewStyle().Width(widthBetweenUsernameAndStatus).Align(lipgloss.Right).Render(s)
-	item := conversationItem{id, convo.UserID, convo.Username, count, s, latestMsg}
+	item := conversationItem{id, convo.UserID, convo.Username, badge, count, s, latestMsg}
 	return item
 }
 
+// renderHandle renders the handle with its leading @, empty if not chosen
+func renderHandle(handle *string) string {
+	if handle == nil || *handle == "" {
+		return ""
+	}
+	return "@" + *handle
+}
+
+// renderProfileStatus renders the status of the user, empty if there is none or it expired
+func renderProfileStatus(emoji, text string, expiresAt *time.Time) string {
+	if domain.StatusExpired(expiresAt) {
+		return ""
+	}
+	return strings.TrimSpace(emoji + " " + text)
+}
+
 func renderStateInfo(convo *domain.Conversation) string {
-	t := convo.LastOnline
-	if t == nil {
+	switch convo.Presence {
+	case domain.Online:
 		return conversationOnlineIndicator
+	case domain.Away:
+		return conversationAwayIndicator
+	case domain.DoNotDisturb:
+		return conversationDNDIndicator
+	}
+	if convo.LastOnline == nil {
+		return ""
 	}
 	onlineAgoTimestamp := calculateOnlineAgoTimestamp(convo.LastOnline)
 	return conversationAgoTimestampStyle.Render(onlineAgoTimestamp)
 }
 
+func (m *ConversationModel) toggleDoNotDisturb() tea.Cmd {
+	doNotDisturb = !doNotDisturb
+	status := domain.Online
+	if doNotDisturb {
+		status = domain.DoNotDisturb
+	}
+	return func() tea.Msg {
+		if err := m.client.SetPresence(status); err != nil {
+			return &errMsg{err: err.Error()}
+		}
+		return nil
+	}
+}
+
+func (m *ConversationModel) toggleMute(usrID string) tea.Cmd {
+	mute := !m.client.Muted(usrID)
+	return func() tea.Msg {
+		if err := m.client.MuteConvo(usrID, mute); err != nil {
+			return &errMsg{err: "Unable to mute this conversation"}
+		}
+		if mute {
+			return convoMuted("Muted")
+		}
+		return convoMuted("Unmuted")
+	}
+}
+
+// setUnreadTitle sets the count of the unread msgs in the title of the terminal, seen from the other windows
+func (m ConversationModel) setUnreadTitle() tea.Cmd {
+	var unread int64
+	for _, convo := range m.convos {
+		unread += convo.UnreadMsgsCount
+	}
+	if unread == 0 {
+		return tea.SetWindowTitle("Letschat")
+	}
+	return tea.SetWindowTitle(fmt.Sprintf("(%d) Letschat", unread))
+}
+
 func (m *ConversationModel) handleConversationListUpdate(msg tea.Msg) tea.Cmd {
 	var cmd tea.Cmd
 	m.conversationList, cmd = m.conversationList.Update(msg)
@@ -4,6 +4,7 @@ import (
 	"github.com/MuhamedUsman/letschat/internal/client"
 	"github.com/MuhamedUsman/letschat/internal/domain"
 	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/table"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -11,20 +12,25 @@ import (
 	zone "github.com/lrstanley/bubblezone"
 	"net/http"
 	"strconv"
+	"strings"
 	"unicode/utf8"
 )
 
 const (
 	discoverSearchBar = "discoverSearchBar"
 	discoverTable     = "discoverTable"
+	discoverLoadMore  = "discoverLoadMore"
+	// in place of the email of a bot, the table cells are plain text
+	discoverBotCell = "🤖 bot"
 )
 
 type DiscoverModel struct {
 	searchTxtInput textinput.Model
 	table          table.Model
 	tableUsrIDs    []string // user ids related to each row
-	metadata       domain.Metadata
-	focusIdx       int // 0 -> Search, 1 -> Table
+	metadata       domain.CursorMetadata
+	query          string // the one the table rows are the results of, loading more keeps to it
+	focusIdx       int    // 0 -> Search, 1 -> Table
 	focus          bool
 	placeholder    string
 	client         *client.Client
@@ -32,7 +38,7 @@ type DiscoverModel struct {
 
 func InitialDiscoverModel(c *client.Client) DiscoverModel {
 	m := DiscoverModel{
-		placeholder: "Bashbunni OR bashbunni@bunnibrain.letschat",
+		placeholder: "Bashbunni, @bashbunni OR bashbunni@bunnibrain.letschat",
 		table:       newDiscoverTable(),
 		focus:       true,
 		client:      c,
@@ -49,37 +55,53 @@ func (m DiscoverModel) Init() tea.Cmd {
 func (m DiscoverModel) Update(msg tea.Msg) (DiscoverModel, tea.Cmd) {
 	m.focusAccordingly()
 	m.handleDiscoverTableHeight()
-	// Fetching more records if the user is in the end of the table
-	curPage := m.metadata.CurrentPage
-	if m.table.Cursor() == len(m.table.Rows())-5 && curPage < m.metadata.LastPage && ioStatus == "" {
-		ioStatus = "Fetching more"
-		m.table.MoveDown(1)
-		return m, tea.Batch(m.searchUser(m.searchTxtInput.Value(), curPage+1), spinnerSpinCmd)
-	}
 
 	switch msg := msg.(type) {
 
+	case themeChangedMsg:
+		restyleDiscoverTxtInput(&m.searchTxtInput)
+		m.table.SetStyles(discoverTableStyles())
+
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+f":
+		if key.Matches(msg, keys.Global.Find) {
 			m.focusIdx = 0
 			return m, m.focusAccordingly()
-		case "up", "down":
+		}
+		switch msg.String() {
+		case "up":
 			m.focusIdx = 1
+		case "down":
+			// moving past the last row loads more
+			if m.focusIdx == 1 && m.focus && m.table.Cursor() == len(m.table.Rows())-1 {
+				return m, m.loadMore()
+			}
+			m.focusIdx = 1
+		case "j":
+			if m.focusIdx == 1 && m.focus && m.table.Cursor() == len(m.table.Rows())-1 {
+				return m, m.loadMore()
+			}
 		case "enter":
 			if m.focusIdx == 0 && m.focus {
 				if utf8.RuneCountInString(m.searchTxtInput.Value()) > 0 {
 					m.table.SetRows(nil) // clearing any previous records
+					m.tableUsrIDs = nil
+					m.metadata = domain.CursorMetadata{}
+					m.query = m.searchTxtInput.Value()
 					ioStatus = "Searching"
-					return m, tea.Batch(spinnerSpinCmd, m.searchUser(m.searchTxtInput.Value(), 1))
+					return m, tea.Batch(spinnerSpinCmd, m.searchUser(m.query, ""))
 				}
 			}
 			if m.focusIdx == 1 && m.focus {
 				selRow := m.table.SelectedRow()
 				selMsg := selDiscUserMsg{
-					id:    m.tableUsrIDs[m.table.Cursor()],
-					name:  selRow[1],
-					email: selRow[2],
+					id:     m.tableUsrIDs[m.table.Cursor()],
+					name:   selRow[1],
+					handle: strings.TrimPrefix(selRow[2], "@"),
+					email:  selRow[3],
+					isBot:  selRow[3] == discoverBotCell,
+				}
+				if selMsg.isBot {
+					selMsg.email = ""
 				}
 				return m, func() tea.Msg { return selMsg } // cmd
 			}
@@ -96,6 +118,10 @@ func (m DiscoverModel) Update(msg tea.Msg) (DiscoverModel, tea.Cmd) {
 				m.focusIdx = 1
 				return m, m.focusAccordingly()
 			}
+			if zone.Get(discoverLoadMore).InBounds(msg) {
+				m.focusIdx = 1
+				return m, tea.Batch(m.focusAccordingly(), m.loadMore())
+			}
 		case tea.MouseButtonWheelDown:
 			if zone.Get(discoverTable).InBounds(msg) {
 				m.focusIdx = 1
@@ -115,8 +141,10 @@ func (m DiscoverModel) Update(msg tea.Msg) (DiscoverModel, tea.Cmd) {
 		m.table.SetRows(msg.rows)
 		m.tableUsrIDs = msg.rowsIds
 		m.metadata = msg.metadata
-		if m.metadata.CurrentPage == 1 {
+		if msg.firstPage {
 			m.table.SetCursor(0)
+		} else if m.table.Cursor() < len(m.table.Rows())-1 {
+			m.table.MoveDown(1) // onto the first loaded row
 		}
 		if len(m.table.Rows()) > 0 {
 			m.focusIdx = 1
@@ -136,6 +164,9 @@ func (m *DiscoverModel) View() string {
 	if len(m.table.Rows()) > 0 {
 		s = discoverTableStyle.Render(m.table.View())
 		s = zone.Mark(discoverTable, s)
+		if m.metadata.NextCursor != "" {
+			s = lipgloss.JoinVertical(lipgloss.Center, s, zone.Mark(discoverLoadMore, discoverLoadMoreStyle.Render("↓ load more")))
+		}
 	} else {
 		s = bunny
 		s = lipgloss.PlaceVertical(terminalHeight-10, lipgloss.Center, s)
@@ -164,7 +195,26 @@ func newDiscoverCursor() cursor.Model {
 	return c
 }
 
+// restyleDiscoverTxtInput colors ti created with newDiscoverTxtInput & newDiscoverCursor after the active theme
+func restyleDiscoverTxtInput(ti *textinput.Model) {
+	restyleTxtInput(ti)
+	ti.Cursor.TextStyle = ti.Cursor.Style
+}
+
 func newDiscoverTable() table.Model {
+	cols := []table.Column{
+		{Title: "#", Width: 6},
+		{Title: "Name", Width: 30},
+		{Title: "Handle", Width: 22},
+		{Title: "Email", Width: 45},
+		{Title: "Joined Since", Width: 20},
+	}
+	t := table.New(table.WithColumns(cols))
+	t.SetStyles(discoverTableStyles())
+	return t
+}
+
+func discoverTableStyles() table.Styles {
 	s := table.DefaultStyles()
 	s.Header = s.Header.
 		BorderStyle(lipgloss.NormalBorder()).
@@ -176,16 +226,7 @@ func newDiscoverTable() table.Model {
 		Foreground(primaryContrastColor).
 		Background(primaryColor).
 		Bold(false)
-
-	cols := []table.Column{
-		{Title: "#", Width: 6},
-		{Title: "Name", Width: 30},
-		{Title: "Email", Width: 45},
-		{Title: "Joined Since", Width: 20},
-	}
-	t := table.New(table.WithColumns(cols))
-	t.SetStyles(s)
-	return t
+	return s
 }
 
 func (m *DiscoverModel) handleDiscoverSearchTxtInput(msg tea.Msg) tea.Cmd {
@@ -202,6 +243,9 @@ func (m *DiscoverModel) handleDiscoverTableUpdate(msg tea.Msg) tea.Cmd {
 
 func (m *DiscoverModel) handleDiscoverTableHeight() {
 	h := terminalHeight - 12
+	if m.metadata.NextCursor != "" {
+		h-- // for the load more line
+	}
 	m.table.SetHeight(h)
 }
 
@@ -223,15 +267,25 @@ func (m *DiscoverModel) focusAccordingly() tea.Cmd {
 	return cmd
 }
 
+// loadMore fetches the page after the rows already in the table, if there is one
+func (m *DiscoverModel) loadMore() tea.Cmd {
+	if m.metadata.NextCursor == "" || ioStatus != "" {
+		return nil
+	}
+	ioStatus = "Fetching more"
+	return tea.Batch(m.searchUser(m.query, m.metadata.NextCursor), spinnerSpinCmd)
+}
+
 type tableResp struct {
-	rows     []table.Row
-	rowsIds  []string
-	metadata domain.Metadata
+	rows      []table.Row
+	rowsIds   []string
+	metadata  domain.CursorMetadata
+	firstPage bool
 }
 
-func (m DiscoverModel) searchUser(query string, page int) tea.Cmd {
+func (m DiscoverModel) searchUser(query, after string) tea.Cmd {
 	return func() tea.Msg {
-		resp, code, err := m.client.SearchUser(query, page)
+		resp, code, err := m.client.SearchUser(query, after)
 		if code == http.StatusUnauthorized {
 			return requireAuthMsg{}
 		}
@@ -242,20 +296,25 @@ func (m DiscoverModel) searchUser(query string, page int) tea.Cmd {
 		ids := m.tableUsrIDs
 		l := len(rows)
 		for _, u := range resp.Users {
-			// do not show the current user in the results
-			if u.ID == m.client.CurrentUsr.ID {
-				continue
+			email := u.Email
+			if u.IsBot { // its email is a placeholder
+				email = discoverBotCell
 			}
-			cell := table.Row{strconv.Itoa(l + 1), u.Name, u.Email, u.CreatedAt.Format("January 2006")}
+			cell := table.Row{strconv.Itoa(l + 1), u.Name, renderHandle(u.Handle), email, u.CreatedAt.Format("January 2006")}
 			l++
 			rows = append(rows, cell)
 			ids = append(ids, u.ID)
 		}
 		m.table.SetRows(rows)
+		// so the presences of the discovered users are received too
+		if err = m.client.WatchPresences(ids); err != nil {
+			return &errMsg{err: err.Error()}
+		}
 		return tableResp{
-			rows:     rows,
-			rowsIds:  ids,
-			metadata: resp.Metadata,
+			rows:      rows,
+			rowsIds:   ids,
+			metadata:  resp.Metadata,
+			firstPage: after == "",
 		}
 	}
 }
@@ -0,0 +1,81 @@
+package tui
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"strings"
+)
+
+// HelpModel is the overlay listing the active keys, generated from keys so it never drifts from them
+type HelpModel struct {
+	vp   viewport.Model
+	show bool
+}
+
+func NewHelpModel() HelpModel {
+	return HelpModel{vp: viewport.New(0, 0)}
+}
+
+func (m HelpModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m HelpModel) Update(msg tea.Msg) (HelpModel, tea.Cmd) {
+	switch msg.(type) {
+	case tea.WindowSizeMsg, themeChangedMsg:
+		m.vp.Width = terminalWidth - 2
+		m.vp.Height = conversationHeight()
+		m.vp.SetContent(renderHelp(m.vp.Width))
+	}
+	if !m.show {
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.vp, cmd = m.vp.Update(msg)
+	return m, cmd
+}
+
+func (m HelpModel) View() string {
+	return m.vp.View()
+}
+
+func (m *HelpModel) toggle() {
+	m.show = !m.show
+	m.vp.GotoTop()
+}
+
+// Helpers & Stuff -----------------------------------------------------------------------------------------------------
+
+// renderHelp renders the groups of keys one after the other, centered in width, each with the name it is rebound by
+// in the keys file
+func renderHelp(width int) string {
+	groups := keys.groups()
+	var keysWidth int
+	for _, g := range groups {
+		for _, nb := range g.bindings {
+			keysWidth = max(keysWidth, lipgloss.Width(keysHelp(*nb.Binding)))
+		}
+	}
+	var sb strings.Builder
+	for _, g := range groups {
+		sb.WriteString(helpTitleStyle.Render(g.title))
+		sb.WriteString("\n")
+		for _, nb := range g.bindings {
+			sb.WriteString(renderHelpLine(*nb.Binding, g.name+"."+nb.name, keysWidth))
+			sb.WriteString("\n")
+		}
+	}
+	sb.WriteString(helpFooterStyle.Render("↑/↓ to scroll, ESC to close"))
+	return lipgloss.PlaceHorizontal(width, lipgloss.Center, sb.String())
+}
+
+func renderHelpLine(b key.Binding, name string, keysWidth int) string {
+	s := helpKeyStyle
+	if len(b.Keys()) == 0 {
+		s = helpUnboundKeyStyle
+	}
+	ks := s.Width(keysWidth + s.GetHorizontalFrameSize()).Render(keysHelp(b))
+	return lipgloss.JoinHorizontal(lipgloss.Top, ks, helpDescStyle.Render(b.Help().Desc), helpNameStyle.Render(name))
+}
@@ -0,0 +1,286 @@
+package tui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// keyMapFile is the name of the file, .json or .toml, in the FilesDir of the client overriding the default keys, e.g.
+//
+//	[chat]
+//	options = "alt+o"
+//	rawText = ["alt+r", "ctrl+y"]
+//	[conversations]
+//	busy = [] # unbound
+const keyMapFile = "keys"
+
+// reservedKeys navigate the forms, the lists & the dialogs, they cannot be bound
+var reservedKeys = []string{"enter", "esc", "tab", "shift+tab", "up", "down", "left", "right"}
+
+// keyMap is every shortcut of the tui, grouped by the area it works in, see keyMap groups
+type keyMap struct {
+	Global        globalKeyMap
+	Conversations conversationsKeyMap
+	Chat          chatKeyMap
+	History       historyKeyMap
+	Preferences   preferencesKeyMap
+	Accounts      accountsKeyMap
+}
+
+type globalKeyMap struct {
+	Quit, NextTab, PrevTab, Help, Accounts, NextAccount key.Binding
+	// focuses the search bar of the tab, filters the conversations or finds in the chat, whichever is focused
+	Find key.Binding
+}
+
+type conversationsKeyMap struct {
+	FocusTyping, CloseChat, Busy, Mute key.Binding
+}
+
+type chatKeyMap struct {
+	NewLine, Options, SendLater, Unschedule, RawText, NextMatch, PrevMatch, CopyCode key.Binding
+}
+
+type historyKeyMap struct {
+	Starred key.Binding
+}
+
+type preferencesKeyMap struct {
+	Theme key.Binding
+}
+
+type accountsKeyMap struct {
+	Remove key.Binding
+}
+
+// keyGroup is the bindings of an area by their name in the keys file, in the order of the help
+type keyGroup struct {
+	name, title string
+	bindings    []namedBinding
+}
+
+type namedBinding struct {
+	name string
+	*key.Binding
+}
+
+// keys is the active keyMap, set once on startup, see loadKeyMap
+var keys = defaultKeyMap()
+
+func defaultKeyMap() keyMap {
+	return keyMap{
+		Global: globalKeyMap{
+			Quit:    key.NewBinding(key.WithKeys("ctrl+c"), key.WithHelp("", "quit")),
+			NextTab: key.NewBinding(key.WithKeys("ctrl+right", "ctrl+r"), key.WithHelp("", "next tab")),
+			PrevTab: key.NewBinding(key.WithKeys("ctrl+left", "ctrl+l"), key.WithHelp("", "previous tab")),
+			Help:    key.NewBinding(key.WithKeys("f1", "?"), key.WithHelp("", "this help")),
+			Accounts: key.NewBinding(key.WithKeys("alt+a"),
+				key.WithHelp("", "the accounts, with their unread msgs, to switch to or add one")),
+			NextAccount: key.NewBinding(key.WithKeys("alt+n"), key.WithHelp("", "switch to the next account")),
+			Find: key.NewBinding(key.WithKeys("ctrl+f"),
+				key.WithHelp("", "focus the search bar, filter the conversations or find in the chat")),
+		},
+		Conversations: conversationsKeyMap{
+			FocusTyping: key.NewBinding(key.WithKeys("ctrl+t"), key.WithHelp("", "focus typing")),
+			CloseChat:   key.NewBinding(key.WithKeys("ctrl+x"), key.WithHelp("", "close the chat")),
+			Busy:        key.NewBinding(key.WithKeys("ctrl+b"), key.WithHelp("", "busy on/off")),
+			Mute:        key.NewBinding(key.WithKeys("alt+m"), key.WithHelp("", "mute the highlighted conversation on/off")),
+		},
+		Chat: chatKeyMap{
+			NewLine:    key.NewBinding(key.WithKeys("ctrl+n"), key.WithHelp("", "newline")),
+			Options:    key.NewBinding(key.WithKeys("ctrl+o"), key.WithHelp("", "chat options")),
+			SendLater:  key.NewBinding(key.WithKeys("ctrl+g"), key.WithHelp("", "send later")),
+			Unschedule: key.NewBinding(key.WithKeys("alt+c"), key.WithHelp("", "unschedule, while editing later")),
+			RawText:    key.NewBinding(key.WithKeys("alt+r"), key.WithHelp("", "raw text on/off")),
+			NextMatch:  key.NewBinding(key.WithKeys("n"), key.WithHelp("", "older match of find")),
+			PrevMatch:  key.NewBinding(key.WithKeys("N"), key.WithHelp("", "newer match of find")),
+			CopyCode:   key.NewBinding(key.WithKeys("c"), key.WithHelp("", "next code block, in message info")),
+		},
+		History: historyKeyMap{
+			Starred: key.NewBinding(key.WithKeys("ctrl+s"), key.WithHelp("", "starred on/off")),
+		},
+		Preferences: preferencesKeyMap{
+			Theme: key.NewBinding(key.WithKeys("alt+t"), key.WithHelp("", "next theme")),
+		},
+		Accounts: accountsKeyMap{
+			Remove: key.NewBinding(key.WithKeys("x"), key.WithHelp("", "remove the highlighted account")),
+		},
+	}
+}
+
+// groups returns the bindings of km by the area they work in
+func (km *keyMap) groups() []keyGroup {
+	return []keyGroup{
+		{"global", "⌨️ EVERYWHERE", []namedBinding{
+			{"quit", &km.Global.Quit},
+			{"nextTab", &km.Global.NextTab},
+			{"prevTab", &km.Global.PrevTab},
+			{"help", &km.Global.Help},
+			{"accounts", &km.Global.Accounts},
+			{"nextAccount", &km.Global.NextAccount},
+			{"find", &km.Global.Find},
+		}},
+		{"conversations", "💭 CONVERSATIONS", []namedBinding{
+			{"focusTyping", &km.Conversations.FocusTyping},
+			{"closeChat", &km.Conversations.CloseChat},
+			{"busy", &km.Conversations.Busy},
+			{"mute", &km.Conversations.Mute},
+		}},
+		{"chat", "💬 CHATTING WINDOW", []namedBinding{
+			{"newline", &km.Chat.NewLine},
+			{"options", &km.Chat.Options},
+			{"sendLater", &km.Chat.SendLater},
+			{"unschedule", &km.Chat.Unschedule},
+			{"rawText", &km.Chat.RawText},
+			{"nextMatch", &km.Chat.NextMatch},
+			{"prevMatch", &km.Chat.PrevMatch},
+			{"copyCode", &km.Chat.CopyCode},
+		}},
+		{"history", "📜 HISTORY", []namedBinding{
+			{"starred", &km.History.Starred},
+		}},
+		{"preferences", "⚙️ PREFERENCES", []namedBinding{
+			{"theme", &km.Preferences.Theme},
+		}},
+		{"accounts", "👥 ACCOUNTS", []namedBinding{
+			{"remove", &km.Accounts.Remove},
+		}},
+	}
+}
+
+// loadKeyMap returns the default keyMap overridden by the keys file in dir, if any, or the default one & the error if
+// the file is invalid or binds a key to two actions working at the same time
+func loadKeyMap(dir string) (keyMap, error) {
+	km := defaultKeyMap()
+	var b []byte
+	var isTOML bool
+	for _, ext := range []string{".json", ".toml"} {
+		var err error
+		b, err = os.ReadFile(filepath.Join(dir, keyMapFile+ext))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return km, err
+		}
+		isTOML = ext == ".toml"
+		break
+	}
+	if b == nil {
+		return km, nil
+	}
+	if err := km.override(b, isTOML); err != nil {
+		return defaultKeyMap(), fmt.Errorf("%v file, the default keys are used: %w", keyMapFile, err)
+	}
+	if err := km.conflicts(); err != nil {
+		return defaultKeyMap(), fmt.Errorf("%v file, the default keys are used: %w", keyMapFile, err)
+	}
+	return km, nil
+}
+
+// keyMsg returns the msg of the first key of b, e.g. to forward it to the components matching it themselves, false
+// if b is unbound
+func keyMsg(b key.Binding) (tea.KeyMsg, bool) {
+	if len(b.Keys()) == 0 {
+		return tea.KeyMsg{}, false
+	}
+	// key.Matches compares the String of the msg, which is the runes as they are
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(b.Keys()[0])}, true
+}
+
+// keysHelp renders the keys of b as shown in the usage & the help, e.g. CTRL+F OR ?
+func keysHelp(b key.Binding) string {
+	if len(b.Keys()) == 0 {
+		return "UNBOUND"
+	}
+	ks := make([]string, len(b.Keys()))
+	for i, k := range b.Keys() {
+		ks[i] = strings.ToUpper(k)
+		if len(k) == 1 { // the case of the letters matters
+			ks[i] = k
+		}
+	}
+	return strings.Join(ks, " OR ")
+}
+
+// Helpers & Stuff -----------------------------------------------------------------------------------------------------
+
+// override sets the keys of the bindings in the keys file, a key or a list of them by the action by the group
+func (km *keyMap) override(b []byte, isTOML bool) error {
+	if isTOML {
+		var err error
+		if b, err = tomlToJSON(b); err != nil {
+			return err
+		}
+	}
+	var groups map[string]map[string]json.RawMessage
+	if err := json.Unmarshal(b, &groups); err != nil {
+		return err
+	}
+	for _, g := range km.groups() {
+		actions, ok := groups[g.name]
+		if !ok {
+			continue
+		}
+		delete(groups, g.name)
+		for _, nb := range g.bindings {
+			raw, ok := actions[nb.name]
+			if !ok {
+				continue
+			}
+			delete(actions, nb.name)
+			var ks []string
+			var k string
+			if err := json.Unmarshal(raw, &k); err == nil {
+				ks = []string{k}
+			} else if err = json.Unmarshal(raw, &ks); err != nil {
+				return fmt.Errorf("%v.%v must be a key or a list of them", g.name, nb.name)
+			}
+			ks = slices.DeleteFunc(ks, func(k string) bool { return k == "" })
+			if i := slices.IndexFunc(ks, func(k string) bool { return slices.Contains(reservedKeys, k) }); i >= 0 {
+				return fmt.Errorf("%v.%v cannot be bound to %v, it is reserved", g.name, nb.name, ks[i])
+			}
+			nb.SetKeys(ks...)
+		}
+		for a := range actions {
+			return fmt.Errorf("unknown action %v.%v", g.name, a)
+		}
+	}
+	for g := range groups {
+		return fmt.Errorf("unknown group %q", g)
+	}
+	return nil
+}
+
+// conflicts reports the keys bound to two actions working at the same time, those of the global group work along
+// each of the other groups, the conversations & the chat ones are on the same tab
+func (km *keyMap) conflicts() error {
+	groups := km.groups()
+	global, convos, chat, history, prefs, accounts := groups[0], groups[1], groups[2], groups[3], groups[4], groups[5]
+	scopes := [][]keyGroup{{global, convos, chat}, {global, history}, {global, prefs}, {global, accounts}}
+	var errs []error
+	reported := make(map[string]bool) // the global ones would be reported for each scope
+	for _, scope := range scopes {
+		bound := make(map[string]string)
+		for _, g := range scope {
+			for _, nb := range g.bindings {
+				name := g.name + "." + nb.name
+				for _, k := range nb.Keys() {
+					if prev, ok := bound[k]; ok && prev != name && !reported[k] {
+						reported[k] = true
+						errs = append(errs, fmt.Errorf("%q is bound to both %v & %v", k, prev, name))
+					}
+					bound[k] = name
+				}
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -2,6 +2,7 @@ package tui
 
 import (
 	"github.com/MuhamedUsman/letschat/internal/client"
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	zone "github.com/lrstanley/bubblezone"
@@ -17,8 +18,12 @@ var (
 	// selected user from conversations
 	selUserID, selUsername string
 	selUserTyping          bool
+	// handle of the user selected from discover, the ones in the conversations are looked up from there
+	selDiscUserHandle string
 	// if false msg will not be sent, and ConversationModel will not call for createConvoIfNotExist()
 	validMsgForSend bool
+	// toggled with ctrl+b on the conversations, others see the current user as busy
+	doNotDisturb bool
 )
 
 type LetschatModel struct {
@@ -56,11 +61,11 @@ func (m LetschatModel) Update(msg tea.Msg) (LetschatModel, tea.Cmd) {
 		}
 
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+t":
+		switch {
+		case key.Matches(msg, keys.Conversations.FocusTyping):
 			m.chat.focus = true
 			m.conversation.focus = false
-		case "ctrl+x":
+		case key.Matches(msg, keys.Conversations.CloseChat):
 			selUserID, selUsername, selUserTyping = "", "", false
 		}
 	}
@@ -5,6 +5,7 @@ import (
 	"github.com/MuhamedUsman/letschat/internal/client"
 	"github.com/MuhamedUsman/letschat/internal/domain"
 	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -25,13 +26,15 @@ type LoginModel struct {
 	errMsg       errMsg
 	ev           *domain.ErrValidation
 	redirected   bool
+	accounts     *client.Accounts
 	client       *client.Client
 	tcm          TabContainerModel
 }
 
 type InActiveUser struct{}
 
-func InitialLoginModel() LoginModel {
+// InitialLoginModel logs into the active one of the accounts
+func InitialLoginModel(accounts *client.Accounts) LoginModel {
 	s := spinner.New()
 	s.Style = lipgloss.NewStyle().Foreground(primaryContrastColor)
 	s.Spinner = spinner.Meter
@@ -45,7 +48,8 @@ func InitialLoginModel() LoginModel {
 		spinner:   s,
 		activeBtn: -1,
 		ev:        domain.NewErrValidation(),
-		client:    client.Get(),
+		accounts:  accounts,
+		client:    accounts.Active(),
 	}
 	for i := range m.txtInputs {
 		ti := textinput.New()
@@ -83,9 +87,18 @@ func (m LoginModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// must be after handling the activeTab tab indices method
 		m.dangerState = false // once there is a keypress remove the danger state
 		m.errMsg.err = ""
-		switch msg.String() {
-		case "ctrl+c":
+		if key.Matches(msg, keys.Global.Quit) {
 			return m, tea.Quit
+		}
+		// a way out to the other accounts, this one stays logged out
+		if key.Matches(msg, keys.Global.NextAccount) && len(m.accounts.List()) > 1 {
+			c, err := m.accounts.Next()
+			if err != nil {
+				return m, func() tea.Msg { return errMsg{err: err.Error()} }
+			}
+			return showAccount(m.accounts, c)
+		}
+		switch msg.String() {
 		case "enter":
 			s := msg.String()
 			if s == "enter" {
@@ -96,7 +109,7 @@ func (m LoginModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.spin = true
 					return m, tea.Batch(m.spinner.Tick, m.login())
 				} else if m.tabIdx == 3 {
-					registerModel := InitialUserRegisterModel()
+					registerModel := InitialUserRegisterModel(m.accounts)
 					return registerModel, registerModel.Init()
 				} else {
 					if m.tabIdx != 2 {
@@ -147,7 +160,7 @@ func (m LoginModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.spin = false
 		m.dangerState = true
 		m.errMsg.err = "initiating account activation"
-		otpModel := InitialOTPModel(m.txtInputs[0].Value())
+		otpModel := InitialOTPModel(m.accounts, m.txtInputs[0].Value())
 		return otpModel, tea.Sequence(m.resendOtp(), otpModel.Init())
 
 	case errMsg:
@@ -158,7 +171,7 @@ func (m LoginModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case doneMsg:
 		m.spin = false
-		mainModel := InitialTabContainerModel()
+		mainModel := InitialTabContainerModel(m.accounts)
 		return mainModel, tea.Batch(mainModel.Init(), func() tea.Msg {
 			return tea.WindowSizeMsg{Width: terminalWidth, Height: terminalHeight}
 		})
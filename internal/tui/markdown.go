@@ -0,0 +1,124 @@
+package tui
+
+import (
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/glamour/styles"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// rgxEmptySGR matches a style reset right after it is set, glamour leaves plenty of them around the padding trimmed
+var rgxEmptySGR = regexp.MustCompile(`\x1b\[[0-9;]*[1-9][0-9;]*m\x1b\[0m`)
+
+// renderedBodyKey keys a msg body rendered as markdown, it is wrapped at the width, which follows the terminal size
+type renderedBodyKey struct {
+	id    string
+	width int
+}
+
+// renderedBodies caches the msg bodies rendered as markdown, a sent msg never changes, so only a resize or a theme
+// switch renders them again, which keeps scrolling through the chat fast
+type renderedBodies map[renderedBodyKey]string
+
+// codeBlock is a fenced or an indented code block of a msg body, lang is empty if the fence is not tagged
+type codeBlock struct {
+	lang, code string
+}
+
+// get returns the body of msg rendered as markdown wrapped at width, in fg, rendering it on a miss
+func (c renderedBodies) get(msgID, body string, width int, fg lipgloss.AdaptiveColor) string {
+	k := renderedBodyKey{msgID, width}
+	if s, ok := c[k]; ok {
+		return s
+	}
+	s, err := renderMarkdown(body, width, fg)
+	if err != nil {
+		slog.Error(err.Error())
+		return body
+	}
+	c[k] = s
+	return s
+}
+
+// renderMarkdown renders the body as markdown wrapped at width, the code blocks are highlighted by chroma & the rest
+// of the text is in fg, as picked by the active theme, the newlines typed are kept as is
+func renderMarkdown(body string, width int, fg lipgloss.AdaptiveColor) (string, error) {
+	sc, color := styles.LightStyleConfig, activeTheme.pick(fg)
+	if activeTheme.dark() {
+		sc = styles.DarkStyleConfig
+	}
+	// the bubble pads & frames the body already
+	var noMargin uint
+	sc.Document.Margin = &noMargin
+	sc.Document.BlockPrefix, sc.Document.BlockSuffix = "", ""
+	sc.Document.Color = &color
+	r, err := glamour.NewTermRenderer(
+		glamour.WithStyles(sc),
+		glamour.WithWordWrap(width),
+		glamour.WithPreservedNewLines(),
+		glamour.WithEmoji(),
+	)
+	if err != nil {
+		return "", err
+	}
+	s, err := r.Render(body)
+	if err != nil {
+		return "", err
+	}
+	return trimRendered(s), nil
+}
+
+// codeBlocks returns the code blocks of the markdown body in order, as they are to be copied, without the fences
+func codeBlocks(body string) []codeBlock {
+	src := []byte(body)
+	doc := goldmark.DefaultParser().Parse(text.NewReader(src))
+	var blocks []codeBlock
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		var lang string
+		switch n := n.(type) {
+		case *ast.FencedCodeBlock:
+			lang = string(n.Language(src))
+		case *ast.CodeBlock:
+		default:
+			return ast.WalkContinue, nil
+		}
+		var sb strings.Builder
+		lines := n.Lines()
+		for i := range lines.Len() {
+			seg := lines.At(i)
+			sb.Write(seg.Value(src))
+		}
+		blocks = append(blocks, codeBlock{lang: lang, code: strings.TrimRight(sb.String(), "\n")})
+		return ast.WalkSkipChildren, nil
+	})
+	return blocks
+}
+
+// Helpers & Stuff -----------------------------------------------------------------------------------------------------
+
+// trimRendered drops the spaces glamour pads each line with up to the wrap width, & the blank lines around, so the
+// bubble is only as wide & as tall as the text
+func trimRendered(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		w := ansi.StringWidth(strings.TrimRight(ansi.Strip(l), " "))
+		lines[i] = rgxEmptySGR.ReplaceAllString(ansi.Truncate(l, w, ""), "")
+	}
+	start, end := 0, len(lines)
+	for start < end && ansi.Strip(lines[start]) == "" {
+		start++
+	}
+	for end > start && ansi.Strip(lines[end-1]) == "" {
+		end--
+	}
+	return strings.Join(lines[start:end], "\n")
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/MuhamedUsman/letschat/internal/client"
 	"github.com/MuhamedUsman/letschat/internal/domain"
 	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/timer"
 	tea "github.com/charmbracelet/bubbletea"
@@ -28,10 +29,11 @@ type OtpModel struct {
 	userEmail   string
 	errMsg      errMsg
 	ev          *domain.ErrValidation
+	accounts    *client.Accounts
 	client      *client.Client
 }
 
-func InitialOTPModel(email string) OtpModel {
+func InitialOTPModel(accounts *client.Accounts, email string) OtpModel {
 	i := textinput.New()
 	i.CharLimit = 6
 	i.Prompt = ""
@@ -48,7 +50,8 @@ func InitialOTPModel(email string) OtpModel {
 		placeholder: "$$$$$$",
 		userEmail:   email,
 		ev:          domain.NewErrValidation(),
-		client:      client.Get(),
+		accounts:    accounts,
+		client:      accounts.Active(),
 	}
 }
 
@@ -67,9 +70,10 @@ func (m OtpModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.otp.Placeholder = m.placeholder
 		m.errMsg.err = ""
 		m.otp.PlaceholderStyle = lipgloss.NewStyle().Foreground(darkGreyColor)
-		switch msg.String() {
-		case "ctrl+c":
+		if key.Matches(msg, keys.Global.Quit) {
 			return m, tea.Quit
+		}
+		switch msg.String() {
 		case "enter":
 			if m.tabIdx == 0 {
 				if err := m.validateOtp(); err != nil {
@@ -119,7 +123,7 @@ func (m OtpModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case doneMsg:
-		loginModel := InitialLoginModel()
+		loginModel := InitialLoginModel(m.accounts)
 		return loginModel, loginModel.Init()
 	}
 
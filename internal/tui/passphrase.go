@@ -0,0 +1,219 @@
+package tui
+
+import (
+	"github.com/MuhamedUsman/letschat/internal/client"
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"strings"
+)
+
+// PassphraseModel unlocks the credentials file of the accounts, or sets its passphrase if it is new, before they load
+type PassphraseModel struct {
+	txtInputs    []textinput.Model
+	placeholders []string
+	spinner      spinner.Model
+	spin         bool
+	tabIdx       int  // 0 - len(txtInputs)-1 -> txtInputs | len(txtInputs) -> Unlock btn
+	dangerState  bool // we turn the form to dangerColor
+	errMsg       errMsg
+	// the passphrase is typed twice
+	setting  bool
+	accounts *client.Accounts
+}
+
+// InitialModel asks for the passphrase of the credentials file if the accounts are locked, else shows the active one
+func InitialModel(accounts *client.Accounts) tea.Model {
+	if accounts.Locked() {
+		return InitialPassphraseModel(accounts)
+	}
+	return InitialTabContainerModel(accounts)
+}
+
+func InitialPassphraseModel(accounts *client.Accounts) PassphraseModel {
+	s := spinner.New()
+	s.Style = lipgloss.NewStyle().Foreground(primaryContrastColor)
+	s.Spinner = spinner.Meter
+
+	m := PassphraseModel{
+		placeholders: []string{"the passphrase goes here..."},
+		spinner:      s,
+		setting:      accounts.SettingPassphrase(),
+		accounts:     accounts,
+	}
+	if m.setting {
+		m.placeholders = append(m.placeholders, "and here it goes again...")
+	}
+	m.txtInputs = make([]textinput.Model, len(m.placeholders))
+	for i := range m.txtInputs {
+		ti := textinput.New()
+		ti.Prompt = ""
+		ti.CharLimit = 128
+		ti.Placeholder = m.placeholders[i]
+		ti.TextStyle = lipgloss.NewStyle().Foreground(primaryColor)
+		ti.EchoCharacter = '*'
+		ti.EchoMode = textinput.EchoPassword
+		ti.Cursor = cursor.New()
+		ti.Cursor.SetMode(cursor.CursorHide)
+		m.txtInputs[i] = ti
+	}
+	m.txtInputs[0].Focus()
+	return m
+}
+
+func (m PassphraseModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m PassphraseModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+
+	case tea.WindowSizeMsg:
+		terminalWidth = msg.Width
+		terminalHeight = msg.Height
+
+	case tea.KeyMsg:
+		m.dangerState = false // once there is a keypress remove the danger state
+		m.errMsg.err = ""
+		if key.Matches(msg, keys.Global.Quit) {
+			return m, tea.Quit
+		}
+		switch msg.String() {
+		case "enter":
+			if m.spin {
+				return m, nil
+			}
+			if m.tabIdx < len(m.txtInputs)-1 {
+				m.tabIdx++
+				break
+			}
+			if err := m.validatePassphrase(); err != nil {
+				return m, nil
+			}
+			m.spin = true
+			return m, tea.Batch(m.spinner.Tick, m.unlock())
+		case "tab", "down":
+			m.tabIdx = (m.tabIdx + 1) % (len(m.txtInputs) + 1)
+		case "shift+tab", "up":
+			m.tabIdx = (m.tabIdx + len(m.txtInputs)) % (len(m.txtInputs) + 1)
+		}
+		m.handleActiveTabIdxElement()
+
+	case spinner.TickMsg:
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case errMsg:
+		m.spin = false
+		m.dangerState = true
+		m.errMsg = msg
+		for i := range m.txtInputs {
+			m.txtInputs[i].Reset()
+		}
+		m.tabIdx = 0
+		m.handleActiveTabIdxElement()
+		return m, nil
+
+	case doneMsg:
+		m.spin = false
+		mainModel := InitialTabContainerModel(m.accounts)
+		return mainModel, tea.Batch(mainModel.Init(), func() tea.Msg {
+			return tea.WindowSizeMsg{Width: terminalWidth, Height: terminalHeight}
+		})
+	}
+
+	return m, m.handleTxtInputs(msg)
+}
+
+func (m PassphraseModel) View() string {
+	var sb strings.Builder
+	sb.WriteString(letschatLogo)
+	if m.errMsg.err != "" && m.dangerState {
+		e := ansi.Wordwrap(m.errMsg.String(), 60, " ")
+		sb.WriteString(infoTxtStyle.Foreground(dangerColor).Render(e))
+	} else if m.setting {
+		sb.WriteString(infoTxtStyle.Render("Set a passphrase encrypting the logins of your accounts"))
+	} else {
+		sb.WriteString(infoTxtStyle.Render("Unlock the logins of your accounts"))
+	}
+	for i := range m.txtInputs {
+		if i == m.tabIdx {
+			sb.WriteString(activeInputStyle.Render(m.txtInputs[i].View()))
+		} else {
+			sb.WriteString(inputStyle.Render(m.txtInputs[i].View()))
+		}
+	}
+	btnTxt := "Unlock"
+	if m.setting {
+		btnTxt = "Set"
+	}
+	if m.tabIdx == len(m.txtInputs) {
+		if m.spin {
+			btnTxt = m.spinner.View()
+		}
+		btn := activeButtonStyleWithColor(primaryContrastColor, primaryColor).Render(btnTxt)
+		sb.WriteString(activeBtnInputStyle.Render(btn))
+	} else {
+		sb.WriteString(btnInputStyle.Render(buttonStyle.Render(btnTxt)))
+	}
+	c := formContainer
+	if m.dangerState {
+		c = c.BorderForeground(dangerColor)
+	}
+	return formContainerCentered(c.Render(sb.String()))
+}
+
+// Helpers & Stuff -----------------------------------------------------------------------------------------------------
+
+// validatePassphrase checks the passphrase is typed, twice alike if it is being set
+func (m *PassphraseModel) validatePassphrase() error {
+	switch {
+	case m.txtInputs[0].Value() == "":
+		m.errMsg.err = "the passphrase must be provided"
+	case m.setting && m.txtInputs[0].Value() != m.txtInputs[1].Value():
+		m.errMsg.err = "the passphrases do not match"
+	default:
+		return nil
+	}
+	m.dangerState = true
+	for i := range m.txtInputs {
+		m.txtInputs[i].Reset()
+	}
+	m.tabIdx = 0
+	m.handleActiveTabIdxElement()
+	return ErrValidation
+}
+
+func (m *PassphraseModel) handleActiveTabIdxElement() {
+	for i := range m.txtInputs {
+		if i == m.tabIdx {
+			m.txtInputs[i].Focus()
+		} else {
+			m.txtInputs[i].Blur()
+		}
+	}
+}
+
+func (m PassphraseModel) handleTxtInputs(msg tea.Msg) tea.Cmd {
+	cmds := make([]tea.Cmd, len(m.txtInputs))
+	for i := range m.txtInputs {
+		if m.tabIdx == i {
+			m.txtInputs[i], cmds[i] = m.txtInputs[i].Update(msg)
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+func (m PassphraseModel) unlock() tea.Cmd {
+	return func() tea.Msg {
+		if err := m.accounts.Unlock(m.txtInputs[0].Value()); err != nil {
+			return errMsg{err: err.Error()}
+		}
+		return doneMsg{}
+	}
+}
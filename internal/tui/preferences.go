@@ -1,29 +1,41 @@
 package tui
 
 import (
+	"fmt"
 	"github.com/MuhamedUsman/letschat/internal/client"
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	zone "github.com/lrstanley/bubblezone"
+	"log/slog"
+	"slices"
 )
 
 const (
-	updateProfile = "updateProfile"
-	usageVp       = "usageVp"
+	updateProfile   = "updateProfile"
+	usageVp         = "usageVp"
+	themePickerPrev = "themePickerPrev"
+	themePickerNext = "themePickerNext"
 )
 
 type PreferencesModel struct {
 	up      UpdateProfileModel
 	usageVp UsageViewportModel
-	focus   bool
-	client  *client.Client
+	// the built-in & the custom themes, switched through with the theme picker
+	themes   []Theme
+	themeIdx int
+	focus    bool
+	client   *client.Client
 }
 
-func NewPreferencesModel(c *client.Client) PreferencesModel {
+// NewPreferencesModel takes the themes loaded, of which the active one is picked
+func NewPreferencesModel(c *client.Client, themes []Theme) PreferencesModel {
 	return PreferencesModel{
-		up:      NewUpdateProfileModel(c),
-		usageVp: NewUsageViewportModel(),
-		client:  c,
+		up:       NewUpdateProfileModel(c),
+		usageVp:  NewUsageViewportModel(),
+		themes:   themes,
+		themeIdx: max(0, slices.IndexFunc(themes, func(t Theme) bool { return t.Name == activeTheme.Name })),
+		client:   c,
 	}
 }
 
@@ -35,6 +47,9 @@ func (m PreferencesModel) Update(msg tea.Msg) (PreferencesModel, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		m.up.focus = m.focus
+		if key.Matches(msg, keys.Preferences.Theme) && m.focus {
+			return m, m.switchTheme(1)
+		}
 	case tea.MouseMsg:
 		m.usageVp.focus = false
 		m.up.focus = false
@@ -44,6 +59,14 @@ func (m PreferencesModel) Update(msg tea.Msg) (PreferencesModel, tea.Cmd) {
 		if zone.Get(usageVp).InBounds(msg) {
 			m.usageVp.focus = true
 		}
+		if msg.Button == tea.MouseButtonLeft && msg.Action == tea.MouseActionRelease && m.focus {
+			if zone.Get(themePickerPrev).InBounds(msg) {
+				return m, m.switchTheme(-1)
+			}
+			if zone.Get(themePickerNext).InBounds(msg) {
+				return m, m.switchTheme(1)
+			}
+		}
 	}
 	return m, tea.Batch(m.handleUsageViewportUpdate(msg), m.handleUpdateProfileModelUpdate(msg))
 }
@@ -52,7 +75,9 @@ func (m PreferencesModel) View() string {
 	d := verticalDivider.Height(conversationHeight()).Render()
 	upView := zone.Mark(updateProfile, m.up.View())
 	usageVpView := zone.Mark(usageVp, m.usageVp.View())
-	return lipgloss.JoinHorizontal(lipgloss.Left, upView, d, usageVpView)
+	picker := renderThemePicker(fmt.Sprintf("%v (%v/%v)", activeTheme.Name, m.themeIdx+1, len(m.themes)))
+	right := lipgloss.JoinVertical(lipgloss.Left, picker, usageVpView)
+	return lipgloss.JoinHorizontal(lipgloss.Left, upView, d, right)
 }
 
 // Helpers & Stuff -----------------------------------------------------------------------------------------------------
@@ -68,3 +93,27 @@ func (m *PreferencesModel) handleUsageViewportUpdate(msg tea.Msg) tea.Cmd {
 	m.usageVp, cmd = m.usageVp.Update(msg)
 	return cmd
 }
+
+// switchTheme switches to the theme by off from the active one, wrapping around, applying it before the next render, &
+// persists it, the models are restyled on the themeChangedMsg returned
+func (m *PreferencesModel) switchTheme(off int) tea.Cmd {
+	m.themeIdx = (m.themeIdx + off + len(m.themes)) % len(m.themes)
+	t, c := m.themes[m.themeIdx], m.client
+	applyTheme(t)
+	return func() tea.Msg {
+		if err := c.SetTheme(t.Name); err != nil {
+			slog.Error(err.Error())
+		}
+		return themeChangedMsg{}
+	}
+}
+
+// renderThemePicker renders the name of the active theme between the arrows switching it
+func renderThemePicker(name string) string {
+	prev := zone.Mark(themePickerPrev, themePickerArrowStyle.Render("◀"))
+	next := zone.Mark(themePickerNext, themePickerArrowStyle.Render("▶"))
+	label := themePickerLabelStyle.Render("Theme  ")
+	hint := themePickerLabelStyle.Render("  ALT+T")
+	p := lipgloss.JoinHorizontal(lipgloss.Center, label, prev, themePickerNameStyle.Render(name), next, hint)
+	return themePickerStyle.Width(usageWidth()).Align(lipgloss.Center).Render(p)
+}
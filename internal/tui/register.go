@@ -5,6 +5,7 @@ import (
 	"github.com/MuhamedUsman/letschat/internal/client"
 	"github.com/MuhamedUsman/letschat/internal/domain"
 	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -20,28 +21,31 @@ type UserRegisterModel struct {
 	spin         bool
 	placeholders []string
 	activeBtn    int // -1 -> none, 0 -> Continue 1 -> Login
-	tabIdx       int // 0 - 2 -> txtInputs | 3 - 4 -> Continue & Login btns
+	tabIdx       int // 0 - 3 -> txtInputs | 4 - 5 -> Continue & Login btns
 	dangerState  bool
 	errMsg       errMsg
 	ev           *domain.ErrValidation
+	accounts     *client.Accounts
 	client       *client.Client
 }
 
-func InitialUserRegisterModel() UserRegisterModel {
+func InitialUserRegisterModel(accounts *client.Accounts) UserRegisterModel {
 	s := spinner.New()
 	s.Style = lipgloss.NewStyle().Foreground(primaryContrastColor)
 	s.Spinner = spinner.Meter
 
 	m := UserRegisterModel{
-		txtInputs: make([]textinput.Model, 3),
+		txtInputs: make([]textinput.Model, 4),
 		spinner:   s,
 		ev:        domain.NewErrValidation(),
 		placeholders: []string{
 			"What should we call you, probably your name",
+			"How should others find you, an @handle (optional)",
 			"How should we contact you, probably your email",
 			"How should we authenticate you, most probably your ex's name",
 		},
-		client: client.Get(),
+		accounts: accounts,
+		client:   accounts.Active(),
 	}
 
 	for i := range m.txtInputs {
@@ -59,9 +63,9 @@ func InitialUserRegisterModel() UserRegisterModel {
 		case 0:
 			input.Placeholder = m.placeholders[i]
 			input.Focus()
-		case 1:
+		case 1, 2:
 			input.Placeholder = m.placeholders[i]
-		case 2:
+		case 3:
 			input.Placeholder = m.placeholders[i]
 			input.EchoMode = textinput.EchoPassword
 			input.EchoCharacter = '*'
@@ -87,14 +91,14 @@ func (m UserRegisterModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// must be after handling the activeTab tab indices method
 		m.dangerState = false // once there is a keypress remove the danger state
 		m.errMsg.err = ""
-		switch msg.String() {
-
-		case "ctrl+c":
+		if key.Matches(msg, keys.Global.Quit) {
 			return m, tea.Quit
+		}
+		switch msg.String() {
 
 		case "enter":
 			// user hit continue btn
-			if m.tabIdx == 3 {
+			if m.tabIdx == 4 {
 				if !m.spin {
 					m.spin = true
 					if err := m.validateUserRegisterModel(); err == nil {
@@ -103,43 +107,43 @@ func (m UserRegisterModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 				return m, nil
 			}
-			if m.tabIdx == 4 {
-				loginModel := InitialLoginModel()
+			if m.tabIdx == 5 {
+				loginModel := InitialLoginModel(m.accounts)
 				return loginModel, loginModel.Init()
 			}
 			m.tabIdx++
-			if m.tabIdx == 3 {
+			if m.tabIdx == 4 {
 				m.activeBtn = 0
 			}
 
 		case "tab":
-			if m.tabIdx == 4 {
+			if m.tabIdx == 5 {
 				m.tabIdx = 0
 			} else {
 				m.tabIdx++
 			}
 		case "shift+tab":
 			if m.tabIdx == 0 {
-				m.tabIdx = 4
+				m.tabIdx = 5
 			} else {
 				m.tabIdx--
 			}
 		case "right":
-			if m.tabIdx == 3 {
+			if m.tabIdx == 4 {
 				m.activeBtn = 1
 				m.tabIdx++
 			}
 		case "left":
-			if m.tabIdx == 4 {
+			if m.tabIdx == 5 {
 				m.activeBtn = 0
 				m.tabIdx--
 			}
 		}
 
 		{ // Updating btns
-			if m.tabIdx == 3 {
+			if m.tabIdx == 4 {
 				m.activeBtn = 0
-			} else if m.tabIdx == 4 {
+			} else if m.tabIdx == 5 {
 				m.activeBtn = 1
 			} else {
 				m.activeBtn = -1
@@ -155,17 +159,20 @@ func (m UserRegisterModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if msg.Name != m.txtInputs[0].Value() {
 			m.populateErr(0, msg.Name)
 		}
-		if msg.Email != m.txtInputs[1].Value() {
-			m.populateErr(1, msg.Email)
+		if msg.Handle != m.txtInputs[1].Value() {
+			m.populateErr(1, msg.Handle)
+		}
+		if msg.Email != m.txtInputs[2].Value() {
+			m.populateErr(2, msg.Email)
 		}
-		if msg.Password != m.txtInputs[2].Value() {
-			m.populateErr(2, msg.Password)
+		if msg.Password != m.txtInputs[3].Value() {
+			m.populateErr(3, msg.Password)
 		}
-		m.txtInputs[2].Reset()
+		m.txtInputs[3].Reset()
 		return m, nil
 
 	case doneMsg:
-		otpModel := InitialOTPModel(m.txtInputs[1].Value())
+		otpModel := InitialOTPModel(m.accounts, m.txtInputs[2].Value())
 		return otpModel, otpModel.Init()
 
 	case spinner.TickMsg:
@@ -237,21 +244,27 @@ func (m *UserRegisterModel) validateUserRegisterModel() error {
 	// clear the previous errors
 	maps.Clear(m.ev.Errors)
 	domain.ValidateName(m.txtInputs[0].Value(), m.ev)
-	domain.ValidateEmail(m.txtInputs[1].Value(), m.ev)
-	domain.ValidPlainPassword(m.txtInputs[2].Value(), m.ev)
+	if h := m.txtInputs[1].Value(); h != "" {
+		domain.ValidateHandle(domain.NormalizeHandle(h), m.ev)
+	}
+	domain.ValidateEmail(m.txtInputs[2].Value(), m.ev)
+	domain.ValidPlainPassword(m.txtInputs[3].Value(), m.ev)
 
 	if m.ev.HasErrors() {
-		m.txtInputs[2].Reset() // Reset password field for any error
+		m.txtInputs[3].Reset() // Reset password field for any error
 		m.dangerState = true
 		if err, ok := m.ev.Errors["name"]; ok {
 			m.populateErr(0, err)
 		}
-		if err, ok := m.ev.Errors["email"]; ok {
+		if err, ok := m.ev.Errors["handle"]; ok {
 			m.populateErr(1, err)
 		}
-		if err, ok := m.ev.Errors["password"]; ok {
+		if err, ok := m.ev.Errors["email"]; ok {
 			m.populateErr(2, err)
 		}
+		if err, ok := m.ev.Errors["password"]; ok {
+			m.populateErr(3, err)
+		}
 		return ErrValidation
 	}
 	return nil
@@ -272,7 +285,7 @@ func (m *UserRegisterModel) handleActiveTabIdxElement() {
 			m.txtInputs[i].Blur()
 		}
 	}
-	// Changes at tabIdx 3 - 4 only affects the view (btns) so the logic will reside in the View method
+	// Changes at tabIdx 4 - 5 only affects the view (btns) so the logic will reside in the View method
 }
 
 func (m UserRegisterModel) handleTxtInputs(msg tea.Msg) tea.Cmd {
@@ -289,8 +302,9 @@ func (m *UserRegisterModel) registerUser() tea.Cmd {
 	return func() tea.Msg {
 		u := &domain.UserRegister{
 			Name:     m.txtInputs[0].Value(),
-			Email:    m.txtInputs[1].Value(),
-			Password: m.txtInputs[2].Value(),
+			Handle:   m.txtInputs[1].Value(), // normalized by the server
+			Email:    m.txtInputs[2].Value(),
+			Password: m.txtInputs[3].Value(),
 		}
 		if err := m.client.Register(u); err != nil {
 			if errors.Is(err, client.ErrServerValidation) {
@@ -0,0 +1,368 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MuhamedUsman/letschat/internal/client"
+	"github.com/MuhamedUsman/letschat/internal/client/repository"
+	"github.com/MuhamedUsman/letschat/internal/domain"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	zone "github.com/lrstanley/bubblezone"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	searchBar  = "searchBar"
+	searchList = "searchList"
+)
+
+// SearchModel searches the locally persisted msgs of every conversation, or lists the starred ones, selecting a hit
+// opens its conversation scrolled to it
+type SearchModel struct {
+	searchTxtInput textinput.Model
+	hitList        list.Model
+	hits           []*domain.MessageHit
+	metadata       domain.Metadata
+	query          string // the one the hits are of
+	focusIdx       int    // 0 -> Search, 1 -> Hits
+	starred        bool   // listing the starred msgs as the hits, instead of the ones matching the query
+	focus          bool
+	fetching       bool
+	unavailable    bool
+	client         *client.Client
+}
+
+type searchHitItem struct {
+	hit *domain.MessageHit
+	id  string // zone id
+}
+
+func (i searchHitItem) Title() string {
+	name := i.hit.Username
+	if name == "" {
+		name = "Unknown"
+	}
+	var sentAt string
+	if i.hit.SentAt != nil {
+		sentAt = i.hit.SentAt.In(time.Local).Format("02-Jan-2006 | 3:04 PM")
+	}
+	return zone.Mark(i.id, fmt.Sprint(name, searchHitTimestampStyle.Render(" · ", sentAt)))
+}
+func (i searchHitItem) Description() string {
+	if i.hit.Snippet == "" { // starred, not a match
+		return strings.ReplaceAll(i.hit.Body, "\n", " ")
+	}
+	return renderSnippet(i.hit.Snippet)
+}
+func (i searchHitItem) FilterValue() string { return i.hit.Body }
+
+type searchResp struct {
+	query   string
+	starred bool
+	hits    []*domain.MessageHit
+	meta    *domain.Metadata
+}
+
+func InitialSearchModel(c *client.Client) SearchModel {
+	ti := newDiscoverTxtInput("Search the messages of every conversation...")
+	ti.Cursor = newDiscoverCursor()
+	return SearchModel{
+		searchTxtInput: ti,
+		hitList:        newSearchHitList(),
+		client:         c,
+	}
+}
+
+func (m SearchModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m SearchModel) Update(msg tea.Msg) (SearchModel, tea.Cmd) {
+	m.focusAccordingly()
+	m.handleSearchListSize()
+
+	switch msg := msg.(type) {
+
+	case themeChangedMsg:
+		restyleDiscoverTxtInput(&m.searchTxtInput)
+		m.hitList.SetDelegate(newSearchHitDelegate())
+
+	case tea.KeyMsg:
+		if !m.focus {
+			return m, nil
+		}
+		switch {
+		case key.Matches(msg, keys.Global.Find):
+			m.focusIdx = 0
+			if m.starred {
+				m.starred = false
+				return m, tea.Batch(m.focusAccordingly(), m.search(m.query, 1))
+			}
+			return m, m.focusAccordingly()
+		case key.Matches(msg, keys.History.Starred):
+			m.starred = !m.starred
+			if m.starred {
+				m.focusIdx = 1
+				m.focusAccordingly()
+				m.hits, m.metadata = nil, domain.Metadata{}
+				return m, tea.Batch(m.hitList.SetItems(nil), m.fetchStarred(1))
+			}
+			m.focusIdx = 0
+			return m, tea.Batch(m.focusAccordingly(), m.search(m.query, 1))
+		case msg.String() == "up", msg.String() == "down":
+			if len(m.hits) > 0 {
+				m.focusIdx = 1
+				m.focusAccordingly()
+			}
+		case msg.String() == "enter":
+			if m.focusIdx == 1 {
+				return m, m.selectHit(m.hitList.Index())
+			}
+			return m, nil
+		}
+
+	case tea.MouseMsg:
+		if !m.focus {
+			break
+		}
+		switch msg.Button {
+		case tea.MouseButtonLeft:
+			if msg.Action != tea.MouseActionRelease {
+				break
+			}
+			if zone.Get(searchBar).InBounds(msg) && !m.starred {
+				m.focusIdx = 0
+				return m, m.focusAccordingly()
+			}
+			for i, item := range m.hitList.VisibleItems() {
+				if zone.Get(item.(searchHitItem).id).InBounds(msg) {
+					m.focusIdx = 1
+					m.hitList.Select(i)
+					return m, tea.Batch(m.focusAccordingly(), m.selectHit(i))
+				}
+			}
+		case tea.MouseButtonWheelDown:
+			if zone.Get(searchList).InBounds(msg) {
+				m.hitList.CursorDown()
+			}
+		case tea.MouseButtonWheelUp:
+			if zone.Get(searchList).InBounds(msg) {
+				m.hitList.CursorUp()
+			}
+		default:
+		}
+		return m, m.fetchMoreIfAtEnd()
+
+	case searchResp:
+		if msg.starred != m.starred || (!m.starred && msg.query != m.query) { // stale, the user typed on or toggled
+			return m, nil
+		}
+		m.fetching = false
+		if msg.meta.CurrentPage <= 1 {
+			m.hits = msg.hits
+			m.hitList.ResetSelected()
+		} else {
+			m.hits = append(m.hits, msg.hits...)
+		}
+		m.metadata = *msg.meta
+		return m, m.hitList.SetItems(m.populateHitItems())
+
+	case searchUnavailableMsg:
+		m.unavailable = true
+		return m, nil
+	}
+
+	prevValue := m.searchTxtInput.Value()
+	cmds := []tea.Cmd{m.handleSearchTxtInputUpdate(msg)}
+	if m.focusIdx == 1 {
+		cmds = append(cmds, m.handleSearchListUpdate(msg), m.fetchMoreIfAtEnd())
+	}
+	// searching as the user types, the index is local
+	if v := m.searchTxtInput.Value(); v != prevValue && !m.starred {
+		m.query = strings.TrimSpace(v)
+		cmds = append(cmds, m.search(m.query, 1))
+	}
+	return m, tea.Batch(cmds...)
+}
+
+func (m SearchModel) View() string {
+	bar := activeDiscoverBar.Render(m.searchTxtInput.View())
+	bar = zone.Mark(searchBar, bar)
+	if m.starred {
+		bar = activeDiscoverBar.Render(searchStarredTitleStyle.Render("★ STARRED MESSAGES · ctrl+s to search"))
+	}
+	var s string
+	switch {
+	case m.starred && len(m.hits) == 0:
+		s = searchInfoStyle.Render("No starred messages, star one from its info dialog")
+	case m.starred:
+		s = m.renderHitList()
+	case m.unavailable:
+		s = searchInfoStyle.Render("Searching is unavailable, this build of Letschat is without FTS5 support")
+	case m.query == "":
+		s = searchInfoStyle.Render("Type to search the messages of every conversation")
+	case len(m.hits) == 0:
+		s = searchInfoStyle.Render("No messages found")
+	default:
+		s = m.renderHitList()
+	}
+	s = lipgloss.JoinVertical(lipgloss.Center, bar, s)
+	return lipgloss.PlaceHorizontal(terminalWidth-2, lipgloss.Center, s)
+}
+
+// Helpers & Stuff -----------------------------------------------------------------------------------------------------
+
+type searchUnavailableMsg struct{}
+
+func (m SearchModel) renderHitList() string {
+	l := searchListStyle.Render(m.hitList.View())
+	l = zone.Mark(searchList, l)
+	count := fmt.Sprintf("%v of %v", m.hitList.Index()+1, m.metadata.TotalRecords)
+	return lipgloss.JoinVertical(lipgloss.Right, l, searchInfoStyle.Render(count))
+}
+
+func newSearchHitList() list.Model {
+	l := list.New(nil, newSearchHitDelegate(), 0, 0)
+	l.KeyMap = getSearchListKeyMap()
+	l.SetFilteringEnabled(false)
+	l.SetShowFilter(false)
+	l.SetShowHelp(false)
+	l.SetShowTitle(false)
+	l.SetShowStatusBar(false)
+	l.SetShowPagination(false)
+	return l
+}
+
+func newSearchHitDelegate() list.DefaultDelegate {
+	d := list.NewDefaultDelegate()
+	d.Styles.SelectedTitle = d.Styles.SelectedTitle.
+		Foreground(primaryColor).
+		BorderForeground(primaryColor)
+	d.Styles.SelectedDesc = d.Styles.SelectedDesc.
+		Foreground(whiteColor).
+		BorderForeground(primaryColor)
+	d.Styles.NormalTitle = d.Styles.NormalTitle.Foreground(whiteColor)
+	return d
+}
+
+func getSearchListKeyMap() list.KeyMap {
+	km := list.DefaultKeyMap()
+	kb := key.NewBinding()
+	km.Quit = kb
+	km.ForceQuit = kb
+	km.Filter = kb
+	km.ShowFullHelp = kb
+	return km
+}
+
+// renderSnippet highlights the matched terms of the snippet
+func renderSnippet(snippet string) string {
+	snippet = strings.ReplaceAll(snippet, "\n", " ")
+	var sb strings.Builder
+	for {
+		before, rest, found := strings.Cut(snippet, domain.SnippetMatchStart)
+		sb.WriteString(before)
+		if !found {
+			return sb.String()
+		}
+		match, after, _ := strings.Cut(rest, domain.SnippetMatchEnd)
+		sb.WriteString(searchHitMatchStyle.Render(match))
+		snippet = after
+	}
+}
+
+func (m *SearchModel) populateHitItems() []list.Item {
+	items := make([]list.Item, len(m.hits))
+	for i, hit := range m.hits {
+		items[i] = searchHitItem{hit: hit, id: "hit_" + strconv.Itoa(i)}
+	}
+	return items
+}
+
+func (m *SearchModel) selectHit(i int) tea.Cmd {
+	if i < 0 || i >= len(m.hits) {
+		return nil
+	}
+	hit := m.hits[i]
+	usrID := hit.SenderID
+	if usrID == m.client.CurrentUsr.ID {
+		usrID = hit.ReceiverID
+	}
+	selMsg := selSearchHitMsg{usrID: usrID, username: hit.Username, msgID: hit.ID}
+	return func() tea.Msg { return selMsg }
+}
+
+// fetchMoreIfAtEnd fetches the next page of hits once the selection is near the end of the list
+func (m *SearchModel) fetchMoreIfAtEnd() tea.Cmd {
+	if m.fetching || m.metadata.CurrentPage >= m.metadata.LastPage || m.hitList.Index() < len(m.hits)-5 {
+		return nil
+	}
+	if m.starred {
+		return m.fetchStarred(m.metadata.CurrentPage + 1)
+	}
+	return m.search(m.query, m.metadata.CurrentPage+1)
+}
+
+func (m *SearchModel) search(query string, page int) tea.Cmd {
+	if query == "" {
+		m.hits = nil
+		m.metadata = domain.Metadata{}
+		return m.hitList.SetItems(nil)
+	}
+	m.fetching = true
+	return func() tea.Msg {
+		hits, meta, err := m.client.SearchMessages(query, "", page)
+		if err != nil {
+			if errors.Is(err, repository.ErrSearchUnavailable) {
+				return searchUnavailableMsg{}
+			}
+			return &errMsg{err: "Unable to search the messages", code: 0}
+		}
+		return searchResp{query: query, hits: hits, meta: meta}
+	}
+}
+
+func (m *SearchModel) fetchStarred(page int) tea.Cmd {
+	m.fetching = true
+	return func() tea.Msg {
+		hits, meta, err := m.client.GetStarredMessages(page)
+		if err != nil {
+			return &errMsg{err: "Unable to fetch the starred messages", code: 0}
+		}
+		return searchResp{starred: true, hits: hits, meta: meta}
+	}
+}
+
+func (m *SearchModel) handleSearchTxtInputUpdate(msg tea.Msg) tea.Cmd {
+	var cmd tea.Cmd
+	m.searchTxtInput, cmd = m.searchTxtInput.Update(msg)
+	return cmd
+}
+
+func (m *SearchModel) handleSearchListUpdate(msg tea.Msg) tea.Cmd {
+	var cmd tea.Cmd
+	m.hitList, cmd = m.hitList.Update(msg)
+	return cmd
+}
+
+func (m *SearchModel) handleSearchListSize() {
+	m.hitList.SetSize(min(terminalWidth-8, 120), max(0, terminalHeight-13))
+}
+
+func (m *SearchModel) focusAccordingly() tea.Cmd {
+	if !m.focus {
+		m.searchTxtInput.Blur()
+		return nil
+	}
+	if m.focusIdx == 0 {
+		return m.searchTxtInput.Focus()
+	}
+	m.searchTxtInput.Blur()
+	return nil
+}
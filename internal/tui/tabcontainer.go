@@ -1,10 +1,13 @@
 package tui
 
 import (
+	"errors"
 	"fmt"
 	"github.com/MuhamedUsman/letschat/internal/client"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/stopwatch"
+	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/timer"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -12,7 +15,9 @@ import (
 	zone "github.com/lrstanley/bubblezone"
 	"log/slog"
 	"net/http"
+	"slices"
 	"strconv"
+	"sync"
 	"time"
 )
 
@@ -25,40 +30,75 @@ type LoginStateBroadcast struct {
 	token int
 }
 
+// themes & keys are loaded once, not on each switch of the accounts
+var (
+	settingsOnce sync.Once
+	themes       []Theme
+)
+
 // TabContainerModel -> main TUI model for this application
 type TabContainerModel struct {
 	discover    DiscoverModel
 	letschat    LetschatModel
+	search      SearchModel
 	preferences PreferencesModel
+	help        HelpModel
+	switcher    AccountsModel
 	tabs        []string
 	activeTab   int
 	errMsg      *errMsg
-	timer       timer.Model
-	stopwatch   stopwatch.Model
-	spinner     *spinner.Model
-	client      *client.Client
-	lsb         LoginStateBroadcast
+	// of loading the custom themes & the keys, shown once
+	startupErr error
+	timer      timer.Model
+	stopwatch  stopwatch.Model
+	spinner    *spinner.Model
+	client     *client.Client
+	accounts   *client.Accounts
+	lsb        LoginStateBroadcast
+	// the user is reported away after client.Client AwayAfter without focus or input, see checkIdle
+	lastInputAt time.Time
+	blurredAt   time.Time
+	away        bool
 }
 
-func InitialTabContainerModel() TabContainerModel {
+type idleCheckMsg struct{}
+
+// InitialTabContainerModel shows the active one of the accounts
+func InitialTabContainerModel(accounts *client.Accounts) TabContainerModel {
 	t := []string{
 		"🔎 DISCOVER",
 		"💭 CONVERSATIONS",
+		"📜 HISTORY",
 		"⚙️ PREFERENCES",
 	}
-	c := client.Get()
+	c := accounts.Active()
+	// before anything is styled or matches keys
+	var startupErr error
+	settingsOnce.Do(func() {
+		var themesErr, keysErr error
+		themes, themesErr = loadThemes(c.ThemesDir())
+		keys, keysErr = loadKeyMap(c.FilesDir)
+		startupErr = errors.Join(themesErr, keysErr)
+	})
+	applyTheme(themeByName(themes, c.Theme()))
 	s := spinner.New(spinner.WithStyle(spinnerStyle), spinner.WithSpinner(spinner.Points))
 	token, ch := c.LoginState.Subscribe()
 	return TabContainerModel{
 		discover:    InitialDiscoverModel(c),
 		letschat:    InitialLetschatModel(c),
-		preferences: NewPreferencesModel(c),
+		search:      InitialSearchModel(c),
+		preferences: NewPreferencesModel(c, themes),
+		help:        NewHelpModel(),
+		switcher:    NewAccountsModel(accounts),
+		startupErr:  startupErr,
 		tabs:        t,
 		activeTab:   1,
 		timer:       timer.New(0),
 		stopwatch:   stopwatch.New(),
 		spinner:     &s,
 		client:      c,
+		accounts:    accounts,
+		lastInputAt: time.Now(),
 		lsb: LoginStateBroadcast{
 			ch:    ch,
 			token: token,
@@ -68,18 +108,32 @@ func InitialTabContainerModel() TabContainerModel {
 
 func (m TabContainerModel) Init() tea.Cmd {
 	//initializeBroadcasts()
+	var errCmd tea.Cmd
+	if m.startupErr != nil {
+		err := m.startupErr
+		errCmd = func() tea.Msg { return &errMsg{err: err.Error()} }
+	}
 	return tea.Batch(
 		m.discover.Init(),
 		m.letschat.Init(),
+		m.search.Init(),
 		m.preferences.Init(),
+		m.help.Init(),
+		m.switcher.Init(),
+		errCmd,
 		m.stopwatch.Init(),
 		m.readOnUsrLoggedInChan(),
 		m.runStartUpProcesses(),
+		checkIdle(),
 	)
 }
 
 func (m TabContainerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	m.setChildModelFocus()
+	// the focus, the tab & the selected user may change anywhere below
+	defer func() { m.client.SetFocusedConvo(m.focusedConvo()) }()
+	// set on activity, if the user was away
+	var awayCmd tea.Cmd
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		terminalHeight = msg.Height
@@ -88,34 +142,72 @@ func (m TabContainerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.FocusMsg:
 		flag := true
 		terminalFocus = &flag
+		m.lastInputAt = time.Now()
+		awayCmd = m.setAway(false)
 
 	case tea.BlurMsg:
 		flag := false
 		terminalFocus = &flag
+		m.blurredAt = time.Now()
+
+	case idleCheckMsg:
+		// set before m is returned, the order the operands of the return are evaluated in is unspecified
+		cmd := m.setAway(m.isIdle())
+		return m, tea.Batch(cmd, checkIdle())
 
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c":
+		m.lastInputAt = time.Now()
+		awayCmd = m.setAway(false)
+		switch {
+		case key.Matches(msg, keys.Global.Quit):
 			m.unsubBroadcasts()
-			if err := m.client.BT.Shutdown(5 * time.Second); err != nil {
+			if err := m.accounts.Shutdown(5 * time.Second); err != nil {
 				slog.Error(err.Error())
 			}
 			return m, tea.Quit
-		case "enter":
+		case msg.String() == "enter":
 			if !m.timer.Timedout() {
-				return m, nil
+				return m, awayCmd
 			}
-		case "esc":
+		case msg.String() == "esc":
 			m.errMsg = nil
 			m.timer.Timeout = 0 * time.Second
-		case "ctrl+right", "ctrl+r":
+			if m.help.show || m.switcher.show {
+				m.help.show, m.switcher.show = false, false
+				return m, awayCmd
+			}
+		case key.Matches(msg, keys.Global.NextTab):
 			if m.activeTab+1 < len(m.tabs) {
 				m.activeTab++
 			}
-		case "ctrl+left", "ctrl+l":
+			m.help.show, m.switcher.show = false, false
+		case key.Matches(msg, keys.Global.PrevTab):
 			if m.activeTab-1 >= 0 {
 				m.activeTab--
 			}
+			m.help.show, m.switcher.show = false, false
+		// the printable ones are text while typing
+		case key.Matches(msg, keys.Global.Help) && (msg.Type != tea.KeyRunes || !m.typing()):
+			m.help.toggle()
+			m.switcher.show = false
+			return m, awayCmd
+		case key.Matches(msg, keys.Global.Accounts) && (msg.Type != tea.KeyRunes || !m.typing()):
+			m.switcher.toggle()
+			m.help.show = false
+			return m, awayCmd
+		case key.Matches(msg, keys.Global.NextAccount) && (msg.Type != tea.KeyRunes || !m.typing()):
+			c, err := m.accounts.Next()
+			if err != nil {
+				return m, func() tea.Msg { return &errMsg{err: err.Error()} }
+			}
+			return m.switchAccount(c)
+		}
+		// the overlays cover the active tab, they take the keys
+		if m.help.show {
+			return m, tea.Batch(m.handleHelpUpdate(msg), awayCmd)
+		}
+		if m.switcher.show {
+			return m, tea.Batch(m.handleSwitcherUpdate(msg), awayCmd)
 		}
 
 	case tea.MouseMsg:
@@ -124,10 +216,20 @@ func (m TabContainerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			for i, t := range m.tabs {
 				if zone.Get(t).InBounds(msg) {
 					m.activeTab = i
+					m.help.show, m.switcher.show = false, false
 				}
 			}
 		default:
 		}
+		if m.help.show {
+			return m, m.handleHelpUpdate(msg)
+		}
+		if m.switcher.show {
+			return m, m.handleSwitcherUpdate(msg)
+		}
+
+	case switchAccountMsg:
+		return m.switchAccount(msg.c)
 
 	case requireAuthMsg:
 		// telling the WsConnStateListener to Idle when user is logging in
@@ -138,7 +240,7 @@ func (m TabContainerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		selUserID = ""
 		selUserTyping = false
 		selUsername = ""
-		loginModel := InitialLoginModel()
+		loginModel := InitialLoginModel(m.accounts)
 		return loginModel, loginModel.Init()
 
 	case *errMsg:
@@ -170,11 +272,20 @@ func (m TabContainerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case resetSpinnerMsg:
 		m.resetSpinner()
 
-	case selDiscUserMsg:
+	case themeChangedMsg:
+		m.spinner.Style = spinnerStyle
+
+	case selDiscUserMsg, selSearchHitMsg:
 		m.activeTab = 1
 	}
 
-	return m, tea.Batch(m.handleChildModelUpdates(msg), m.handleStopwatchUpdate(msg))
+	return m, tea.Batch(
+		m.handleChildModelUpdates(msg),
+		m.handleHelpUpdate(msg),
+		m.handleSwitcherUpdate(msg),
+		m.handleStopwatchUpdate(msg),
+		awayCmd,
+	)
 }
 
 func (m TabContainerModel) View() string {
@@ -199,13 +310,23 @@ func (m TabContainerModel) View() string {
 	if ioStatus != "" {
 		s = ioStatus + " " + m.spinner.View()
 	}
+	if n := m.switcher.othersUnread(); n > 0 {
+		// of the accounts in the background
+		s = fmt.Sprintf("%v · %d⁕ %v", s, n, keysHelp(keys.Global.Accounts))
+	}
 	if m.client.CurrentUsr != nil {
-		t = renderTabsWithGapsAndText(t, m.client.CurrentUsr.Name, s, m.client.WsConnState.Get())
+		t = renderTabsWithGapsAndText(t, m.client.CurrentUsr.Name, s, m.client.WsConnState.Get(), m.client.RTT())
 	} else {
 		// conn Confirmation will be ignored of currentUsr is nil
-		t = renderTabsWithGapsAndText(t, "", s, m.client.WsConnState.Get())
+		t = renderTabsWithGapsAndText(t, "", s, m.client.WsConnState.Get(), 0)
 	}
 	content := m.populateActiveTabContent()
+	if m.help.show {
+		content = m.help.View()
+	}
+	if m.switcher.show {
+		content = m.switcher.View()
+	}
 	c := renderContainerWithTabs(t, content)
 	return zone.Scan(c)
 }
@@ -227,7 +348,23 @@ func renderLeftText(txt string, s client.WsConnState) string {
 	return fmt.Sprint(is.Render("●"), statusTextStyle.UnsetPadding().Render(txt), is.Render("●"))
 }
 
-func renderTabsWithGapsAndText(tabs, textL, textR string, state client.WsConnState) string {
+// renderConnQuality renders the rtt of the ws heartbeat as signal bars, empty if there is no rtt yet
+func renderConnQuality(rtt time.Duration) string {
+	if rtt == 0 {
+		return ""
+	}
+	bars, c := "▂▄▆", greenColor
+	switch {
+	case rtt > 400*time.Millisecond:
+		bars, c = "▂", redColor
+	case rtt > 150*time.Millisecond:
+		bars, c = "▂▄", orangeColor
+	}
+	s := statusTextStyle.UnsetPadding().Foreground(c)
+	return " " + s.Render(fmt.Sprintf("%-3s %vms", bars, rtt.Milliseconds()))
+}
+
+func renderTabsWithGapsAndText(tabs, textL, textR string, state client.WsConnState, rtt time.Duration) string {
 	w := (terminalWidth - lipgloss.Width(tabs) - 4) / 2
 	gapL := tabGapLeft.Width(w).Render(statusTextStyle.Render("Letschat"))
 	// used for verticalDivider in conversations tab
@@ -236,7 +373,7 @@ func renderTabsWithGapsAndText(tabs, textL, textR string, state client.WsConnSta
 	// used for chat field in conversations tab
 	tabGapRightWithTabsWidth = lipgloss.Width(gapR) + lipgloss.Width(tabs)
 	if textL != "" {
-		gapL = tabGapLeft.Width(w).Render(renderLeftText(textL, state))
+		gapL = tabGapLeft.Width(w).Render(renderLeftText(textL, state) + renderConnQuality(rtt))
 	}
 	return lipgloss.JoinHorizontal(lipgloss.Bottom, gapL, tabs, gapR)
 }
@@ -270,6 +407,7 @@ func renderErrContainer(err string, code int, timer string) string {
 func (m *TabContainerModel) setChildModelFocus() {
 	m.discover.focus = false
 	m.letschat.focus = false
+	m.search.focus = false
 	m.preferences.focus = false
 	switch m.activeTab {
 	case 0:
@@ -277,18 +415,100 @@ func (m *TabContainerModel) setChildModelFocus() {
 	case 1:
 		m.letschat.focus = true
 	case 2:
+		m.search.focus = true
+	case 3:
 		m.preferences.focus = true
 	}
 }
 
 func (m *TabContainerModel) handleChildModelUpdates(msg tea.Msg) tea.Cmd {
-	cmds := make([]tea.Cmd, 3)
+	cmds := make([]tea.Cmd, 4)
 	m.discover, cmds[0] = m.discover.Update(msg)
 	m.letschat, cmds[1] = m.letschat.Update(msg)
-	m.preferences, cmds[2] = m.preferences.Update(msg)
+	m.search, cmds[2] = m.search.Update(msg)
+	m.preferences, cmds[3] = m.preferences.Update(msg)
 	return tea.Batch(cmds...)
 }
 
+func checkIdle() tea.Cmd {
+	return tea.Tick(10*time.Second, func(time.Time) tea.Msg { return idleCheckMsg{} })
+}
+
+// isIdle reports whether the terminal has been out of focus or without keyboard input for client.Client AwayAfter
+func (m *TabContainerModel) isIdle() bool {
+	d := m.client.AwayAfter
+	if d <= 0 {
+		return false
+	}
+	unfocused := terminalFocus != nil && !*terminalFocus && time.Since(m.blurredAt) >= d
+	return unfocused || time.Since(m.lastInputAt) >= d
+}
+
+// focusedConvo is the user id of the conversation in front of the user, empty if the conversations tab is not active
+// or the terminal is out of focus
+func (m *TabContainerModel) focusedConvo() string {
+	if m.activeTab != 1 || (terminalFocus != nil && !*terminalFocus) {
+		return ""
+	}
+	return selUserID
+}
+
+// setAway reports the transition to the server, nil if away is unchanged
+func (m *TabContainerModel) setAway(away bool) tea.Cmd {
+	if m.away == away {
+		return nil
+	}
+	m.away = away
+	return func() tea.Msg {
+		if err := m.client.SetIdle(away); err != nil {
+			slog.Error(err.Error())
+		}
+		return nil
+	}
+}
+
+func (m *TabContainerModel) handleHelpUpdate(msg tea.Msg) tea.Cmd {
+	var cmd tea.Cmd
+	m.help, cmd = m.help.Update(msg)
+	return cmd
+}
+
+func (m *TabContainerModel) handleSwitcherUpdate(msg tea.Msg) tea.Cmd {
+	var cmd tea.Cmd
+	m.switcher, cmd = m.switcher.Update(msg)
+	return cmd
+}
+
+// switchAccount shows the account of c, the one shown so far keeps running in the background
+func (m TabContainerModel) switchAccount(c *client.Client) (tea.Model, tea.Cmd) {
+	if c == m.client {
+		return m, nil
+	}
+	// must unsubscribe before redirecting to some other model
+	m.unsubBroadcasts()
+	m.client.SetFocusedConvo("")
+	return showAccount(m.accounts, c)
+}
+
+// typing reports whether an input of the active tab is focused, the printable keys are text then
+func (m *TabContainerModel) typing() bool {
+	focused := func(ti textinput.Model) bool { return ti.Focused() }
+	switch m.activeTab {
+	case 0:
+		return m.discover.searchTxtInput.Focused()
+	case 1:
+		c := m.letschat.chat
+		return c.chatTxtarea.Focused() || c.findTxtInput.Focused() || c.schedTxtInput.Focused() ||
+			m.letschat.conversation.conversationList.FilterInput.Focused()
+	case 2:
+		return m.search.searchTxtInput.Focused()
+	case 3:
+		return slices.ContainsFunc(m.preferences.up.txtInputs, focused)
+	default:
+		return false
+	}
+}
+
 func (m *TabContainerModel) handleTimerUpdate(msg tea.Msg) tea.Cmd {
 	var cmd tea.Cmd
 	m.timer, cmd = m.timer.Update(msg)
@@ -323,6 +543,8 @@ func (m *TabContainerModel) populateActiveTabContent() string {
 	case 1:
 		return m.letschat.View()
 	case 2:
+		return m.search.View()
+	case 3:
 		return m.preferences.View()
 
 	default:
@@ -334,7 +556,11 @@ func (m *TabContainerModel) populateActiveTabContent() string {
 func (m TabContainerModel) readOnUsrLoggedInChan() tea.Cmd {
 	return func() tea.Msg {
 		for {
-			if flag, ok := <-m.lsb.ch; ok && bool(!flag) {
+			flag, ok := <-m.lsb.ch
+			if !ok { // unsubscribed, e.g. on switching accounts
+				return nil
+			}
+			if !flag {
 				return requireAuthMsg{}
 			}
 		}
@@ -345,6 +571,10 @@ func (m TabContainerModel) readOnUsrLoggedInChan() tea.Cmd {
 func (m TabContainerModel) runStartUpProcesses() tea.Cmd {
 	return func() tea.Msg {
 		m.client.RunStartupProcesses()
+		// the login state was broadcast before subscribing, if it ran on loading the accounts
+		if m.client.CurrentUsr == nil {
+			return requireAuthMsg{}
+		}
 		return nil
 	}
 }
@@ -354,3 +584,24 @@ func (m TabContainerModel) unsubBroadcasts() {
 	m.client.Conversations.Unsubscribe(m.letschat.conversation.cb.token)
 	m.client.RecvMsgs.Unsubscribe(m.letschat.chat.chatViewport.mb.token)
 }
+
+// showAccount makes the account of c the active one & shows it, requiring a login if it is logged out
+func showAccount(accounts *client.Accounts, c *client.Client) (tea.Model, tea.Cmd) {
+	if err := accounts.SetActive(c); err != nil {
+		slog.Error(err.Error())
+	}
+	// clear any selected chat for view
+	selUserID = ""
+	selUserTyping = false
+	selUsername = ""
+	tcm := InitialTabContainerModel(accounts)
+	size := func() tea.Msg { return tea.WindowSizeMsg{Width: terminalWidth, Height: terminalHeight} }
+	refresh := func() tea.Msg {
+		// the conversations were broadcast to the model shown before
+		if c.CurrentUsr != nil {
+			c.RefreshConversations()
+		}
+		return nil
+	}
+	return tcm, tea.Batch(tcm.Init(), size, refresh)
+}
@@ -0,0 +1,332 @@
+package tui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/lipgloss"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// backgrounds a Theme is made for
+const (
+	darkBackground  = "dark"
+	lightBackground = "light"
+)
+
+// Theme is the set of colors all the styles are built from, see applyTheme, a color adapts to the background of the
+// terminal unless its light & dark are the same
+type Theme struct {
+	Name string
+	// darkBackground or lightBackground, it picks the colors & the markdown styles, empty to detect the one of the
+	// terminal, which is not always possible, e.g. inside tmux
+	Background string
+	// the brand color, its subtle variant for the secondary text & the one readable on top of it
+	Primary, PrimarySubtleDark, PrimaryContrast lipgloss.AdaptiveColor
+	Danger, DangerDark                          lipgloss.AdaptiveColor
+	// the text & the background
+	White, Black lipgloss.AdaptiveColor
+	// the borders & the dimmed text
+	DarkGrey, LightGrey       lipgloss.AdaptiveColor
+	Red, Orange, Green, Bunny lipgloss.AdaptiveColor
+}
+
+// themeChangedMsg is sent once the theme is switched, the models restyle the components they styled on creation
+type themeChangedMsg struct{}
+
+// activeTheme is the one the styles are currently built from
+var activeTheme Theme
+
+var builtinThemes = []Theme{
+	{
+		Name:              "auto",
+		Primary:           lipgloss.AdaptiveColor{Light: "#4b3b00", Dark: "#FFC700"},
+		PrimarySubtleDark: lipgloss.AdaptiveColor{Light: "#6c5300", Dark: "#8b7000"},
+		PrimaryContrast:   lipgloss.AdaptiveColor{Light: "#FFC700", Dark: "#4b3b00"},
+		Danger:            lipgloss.AdaptiveColor{Light: "#ff7b4e", Dark: "#FF5C00"},
+		DangerDark:        lipgloss.AdaptiveColor{Light: "#b65d3e", Dark: "#a34a00"},
+		White:             lipgloss.AdaptiveColor{Light: "#202020", Dark: "#E5D6A8"},
+		Black:             lipgloss.AdaptiveColor{Light: "#E5D6A8", Dark: "#202020"},
+		DarkGrey:          lipgloss.AdaptiveColor{Light: "#808080", Dark: "#404040"},
+		LightGrey:         lipgloss.AdaptiveColor{Light: "#404040", Dark: "#afafaf"},
+		Red:               lipgloss.AdaptiveColor{Light: "#FF0000", Dark: "#FF0000"},
+		Orange:            lipgloss.AdaptiveColor{Light: "#ffa000", Dark: "#ffa000"},
+		Green:             lipgloss.AdaptiveColor{Light: "#00a300", Dark: "#00ff00"},
+		Bunny:             lipgloss.AdaptiveColor{Light: "#602c1a", Dark: "#6d4534"},
+	},
+	{
+		Name:              "dark",
+		Background:        darkBackground,
+		Primary:           lipgloss.AdaptiveColor{Light: "#FFC700", Dark: "#FFC700"},
+		PrimarySubtleDark: lipgloss.AdaptiveColor{Light: "#8b7000", Dark: "#8b7000"},
+		PrimaryContrast:   lipgloss.AdaptiveColor{Light: "#4b3b00", Dark: "#4b3b00"},
+		Danger:            lipgloss.AdaptiveColor{Light: "#FF5C00", Dark: "#FF5C00"},
+		DangerDark:        lipgloss.AdaptiveColor{Light: "#a34a00", Dark: "#a34a00"},
+		White:             lipgloss.AdaptiveColor{Light: "#E5D6A8", Dark: "#E5D6A8"},
+		Black:             lipgloss.AdaptiveColor{Light: "#202020", Dark: "#202020"},
+		DarkGrey:          lipgloss.AdaptiveColor{Light: "#404040", Dark: "#404040"},
+		LightGrey:         lipgloss.AdaptiveColor{Light: "#afafaf", Dark: "#afafaf"},
+		Red:               lipgloss.AdaptiveColor{Light: "#FF0000", Dark: "#FF0000"},
+		Orange:            lipgloss.AdaptiveColor{Light: "#ffa000", Dark: "#ffa000"},
+		Green:             lipgloss.AdaptiveColor{Light: "#00ff00", Dark: "#00ff00"},
+		Bunny:             lipgloss.AdaptiveColor{Light: "#6d4534", Dark: "#6d4534"},
+	},
+	{
+		Name:              "light",
+		Background:        lightBackground,
+		Primary:           lipgloss.AdaptiveColor{Light: "#4b3b00", Dark: "#4b3b00"},
+		PrimarySubtleDark: lipgloss.AdaptiveColor{Light: "#6c5300", Dark: "#6c5300"},
+		PrimaryContrast:   lipgloss.AdaptiveColor{Light: "#FFC700", Dark: "#FFC700"},
+		Danger:            lipgloss.AdaptiveColor{Light: "#ff7b4e", Dark: "#ff7b4e"},
+		DangerDark:        lipgloss.AdaptiveColor{Light: "#b65d3e", Dark: "#b65d3e"},
+		White:             lipgloss.AdaptiveColor{Light: "#202020", Dark: "#202020"},
+		Black:             lipgloss.AdaptiveColor{Light: "#E5D6A8", Dark: "#E5D6A8"},
+		DarkGrey:          lipgloss.AdaptiveColor{Light: "#808080", Dark: "#808080"},
+		LightGrey:         lipgloss.AdaptiveColor{Light: "#404040", Dark: "#404040"},
+		Red:               lipgloss.AdaptiveColor{Light: "#FF0000", Dark: "#FF0000"},
+		Orange:            lipgloss.AdaptiveColor{Light: "#ffa000", Dark: "#ffa000"},
+		Green:             lipgloss.AdaptiveColor{Light: "#00a300", Dark: "#00a300"},
+		Bunny:             lipgloss.AdaptiveColor{Light: "#602c1a", Dark: "#602c1a"},
+	},
+	{
+		// black & white with the blue & orange pair, which the common kinds of color blindness tell apart, in place
+		// of the green & red one
+		Name:              "high-contrast",
+		Primary:           lipgloss.AdaptiveColor{Light: "#0000AF", Dark: "#FFFF00"},
+		PrimarySubtleDark: lipgloss.AdaptiveColor{Light: "#303030", Dark: "#D0D0D0"},
+		PrimaryContrast:   lipgloss.AdaptiveColor{Light: "#FFFFFF", Dark: "#000000"},
+		Danger:            lipgloss.AdaptiveColor{Light: "#AF5F00", Dark: "#FFAF00"},
+		DangerDark:        lipgloss.AdaptiveColor{Light: "#875F00", Dark: "#FFD787"},
+		White:             lipgloss.AdaptiveColor{Light: "#000000", Dark: "#FFFFFF"},
+		Black:             lipgloss.AdaptiveColor{Light: "#FFFFFF", Dark: "#000000"},
+		DarkGrey:          lipgloss.AdaptiveColor{Light: "#4E4E4E", Dark: "#B2B2B2"},
+		LightGrey:         lipgloss.AdaptiveColor{Light: "#000000", Dark: "#FFFFFF"},
+		Red:               lipgloss.AdaptiveColor{Light: "#AF5F00", Dark: "#FFAF00"},
+		Orange:            lipgloss.AdaptiveColor{Light: "#AF5F00", Dark: "#FFAF00"},
+		Green:             lipgloss.AdaptiveColor{Light: "#005FD7", Dark: "#5FD7FF"},
+		Bunny:             lipgloss.AdaptiveColor{Light: "#000000", Dark: "#FFFFFF"},
+	},
+}
+
+func init() {
+	applyTheme(builtinThemes[0])
+}
+
+// applyTheme sets the colors of t & builds the styles from them again, the components styled on creation are
+// restyled on themeChangedMsg
+func applyTheme(t Theme) {
+	activeTheme = t
+	primaryColor = t.Primary
+	primarySubtleDarkColor = t.PrimarySubtleDark
+	primaryContrastColor = t.PrimaryContrast
+	dangerColor = t.Danger
+	dangerDarkColor = t.DangerDark
+	whiteColor = t.White
+	blackColor = t.Black
+	darkGreyColor = t.DarkGrey
+	lightGreyColor = t.LightGrey
+	redColor = t.Red
+	orangeColor = t.Orange
+	greenColor = t.Green
+	bunnyColor = t.Bunny
+	styleGlobal()
+	styleForms()
+	styleTabContainer()
+	styleDiscover() // after the forms, it builds on activeInputStyle
+	styleSearch()
+	styleConversations()
+	styleChat()
+	styleMsgInfo()
+	stylePreferences()
+	styleHelp()
+	styleAccounts()
+	styleUpdateProfile()
+	styleBunny()
+	styleBanner()
+}
+
+// dark reports whether the theme is made for a dark background, detecting the one of the terminal if not set
+func (t Theme) dark() bool {
+	switch t.Background {
+	case darkBackground:
+		return true
+	case lightBackground:
+		return false
+	default:
+		return lipgloss.HasDarkBackground()
+	}
+}
+
+// pick returns the one of the light & dark of c the theme is made for, e.g. for the markdown styles which are not
+// adaptive
+func (t Theme) pick(c lipgloss.AdaptiveColor) string {
+	if t.dark() {
+		return c.Dark
+	}
+	return c.Light
+}
+
+// loadThemes returns the built-in themes followed by the ones in the .json & .toml files of dir, named after the file,
+// a file named like a built-in theme replaces it, the files failing to load are reported in err but skipped
+func loadThemes(dir string) ([]Theme, error) {
+	themes := slices.Clone(builtinThemes)
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return themes, nil
+	}
+	if err != nil {
+		return themes, err
+	}
+	var errs []error
+	for _, e := range entries {
+		ext := filepath.Ext(e.Name())
+		if e.IsDir() || (ext != ".json" && ext != ".toml") {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err == nil {
+			var t Theme
+			if t, err = parseTheme(strings.TrimSuffix(e.Name(), ext), b, ext == ".toml"); err == nil {
+				i := slices.IndexFunc(themes, func(bt Theme) bool { return bt.Name == t.Name })
+				if i < 0 {
+					themes = append(themes, t)
+				} else {
+					themes[i] = t
+				}
+				continue
+			}
+		}
+		errs = append(errs, fmt.Errorf("theme %v: %w", e.Name(), err))
+	}
+	return themes, errors.Join(errs...)
+}
+
+// themeByName returns the theme named name, the first one if there is none
+func themeByName(themes []Theme, name string) Theme {
+	if i := slices.IndexFunc(themes, func(t Theme) bool { return t.Name == name }); i >= 0 {
+		return themes[i]
+	}
+	return themes[0]
+}
+
+// parseTheme parses the theme file, its colors are either a single color for both the backgrounds or a table of the
+// light & dark ones, the ones not set are taken from the theme named base, auto by default, e.g. in JSON
+//
+//	{"base": "dark", "primary": "#00AFFF", "danger": {"light": "#AF0000", "dark": "#FF5F5F"}}
+//
+// & in TOML
+//
+//	base = "dark"
+//	primary = "#00AFFF"
+//	danger = { light = "#AF0000", dark = "#FF5F5F" }
+func parseTheme(name string, b []byte, isTOML bool) (Theme, error) {
+	if isTOML {
+		var err error
+		if b, err = tomlToJSON(b); err != nil {
+			return Theme{}, err
+		}
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return Theme{}, err
+	}
+	var base string
+	if err := unmarshalThemeField(fields, "base", &base); err != nil {
+		return Theme{}, err
+	}
+	t := themeByName(builtinThemes, base)
+	t.Name = name
+	if err := unmarshalThemeField(fields, "background", &t.Background); err != nil {
+		return Theme{}, err
+	}
+	if t.Background != "" && t.Background != darkBackground && t.Background != lightBackground {
+		return Theme{}, fmt.Errorf("background must be %v or %v", darkBackground, lightBackground)
+	}
+	for k, c := range t.colors() {
+		var tc themeColor
+		if err := unmarshalThemeField(fields, k, &tc); err != nil {
+			return Theme{}, err
+		}
+		if tc != (themeColor{}) {
+			*c = lipgloss.AdaptiveColor(tc)
+		}
+	}
+	for k := range fields {
+		return Theme{}, fmt.Errorf("unknown key %q", k)
+	}
+	return t, nil
+}
+
+// restyleTxtInput styles the text & the cursor of ti in the primary color, on creation & on themeChangedMsg
+func restyleTxtInput(ti *textinput.Model) {
+	ti.TextStyle = lipgloss.NewStyle().Foreground(primaryColor)
+	ti.Cursor.Style = lipgloss.NewStyle().Foreground(primaryColor)
+}
+
+// Helpers & Stuff -----------------------------------------------------------------------------------------------------
+
+// colors returns the colors of the theme by their key in the theme files
+func (t *Theme) colors() map[string]*lipgloss.AdaptiveColor {
+	return map[string]*lipgloss.AdaptiveColor{
+		"primary":           &t.Primary,
+		"primarySubtleDark": &t.PrimarySubtleDark,
+		"primaryContrast":   &t.PrimaryContrast,
+		"danger":            &t.Danger,
+		"dangerDark":        &t.DangerDark,
+		"white":             &t.White,
+		"black":             &t.Black,
+		"darkGrey":          &t.DarkGrey,
+		"lightGrey":         &t.LightGrey,
+		"red":               &t.Red,
+		"orange":            &t.Orange,
+		"green":             &t.Green,
+		"bunny":             &t.Bunny,
+	}
+}
+
+// themeColor is a color of a theme file, a single color for both the backgrounds or the light & dark ones
+type themeColor lipgloss.AdaptiveColor
+
+func (c *themeColor) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		c.Light, c.Dark = s, s
+		return nil
+	}
+	var ac struct{ Light, Dark string }
+	if err := json.Unmarshal(b, &ac); err != nil {
+		return errors.New(`must be a color or a table of the "light" & "dark" ones`)
+	}
+	if ac.Light == "" || ac.Dark == "" {
+		return errors.New(`both the "light" & "dark" colors must be set`)
+	}
+	c.Light, c.Dark = ac.Light, ac.Dark
+	return nil
+}
+
+// unmarshalThemeField unmarshals the field k into v if set & deletes it from fields, so the unknown ones remain
+func unmarshalThemeField(fields map[string]json.RawMessage, k string, v any) error {
+	raw, ok := fields[k]
+	if !ok {
+		return nil
+	}
+	delete(fields, k)
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("%v: %w", k, err)
+	}
+	return nil
+}
+
+// tomlToJSON converts the TOML file b to JSON, so the theme & the keys files are parsed the same in either format
+func tomlToJSON(b []byte) ([]byte, error) {
+	var m map[string]any
+	if err := toml.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	return json.Marshal(m)
+}
@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"cmp"
 	"errors"
 	"fmt"
 	"github.com/MuhamedUsman/letschat/internal/client"
@@ -14,8 +15,30 @@ import (
 	"golang.org/x/exp/maps"
 	"net/http"
 	"strings"
+	"time"
 )
 
+// form items of UpdateProfileModel, in tab order, the txtInputs first
+const (
+	upNameIdx = iota
+	upHandleIdx
+	upEmailIdx
+	upStatusEmojiIdx
+	upStatusTextIdx
+	upStatusExpiryIdx
+	upBioIdx
+	upPrevPassIdx
+	upNewPassIdx
+	upConfirmPassIdx
+	upPassBtnIdx
+	upUpdateBtnIdx
+	upLogoutIdx
+	upFormItems
+)
+
+// typed in the status text or bio to clear it
+const upClearValue = "-"
+
 type inputStyles struct {
 	header lipgloss.Style
 	field  lipgloss.Style
@@ -38,10 +61,16 @@ type UpdateProfileModel struct {
 
 func NewUpdateProfileModel(c *client.Client) UpdateProfileModel {
 	up := UpdateProfileModel{
-		inputTitles:          []string{"Name", "Email", "Previous Password", "New Password", "Confirm Password"},
-		errFieldTitles:       []string{"name", "email", "prevPass", "newPass", "confirmPass"},
-		inputFieldStyles:     make([]inputStyles, 5),
-		txtInputs:            make([]textinput.Model, 5),
+		inputTitles: []string{
+			"Name", "Handle", "Email", "Status Emoji", "Status", "Status Expires In", "Bio",
+			"Previous Password", "New Password", "Confirm Password",
+		},
+		errFieldTitles: []string{
+			"name", "handle", "email", "statusEmoji", "statusText", "statusExpiresAt", "bio",
+			"prevPass", "newPass", "confirmPass",
+		},
+		inputFieldStyles:     make([]inputStyles, upPassBtnIdx),
+		txtInputs:            make([]textinput.Model, upPassBtnIdx),
 		tabIdx:               -1,
 		populatePlaceholders: true,
 		spinner:              newSpinner(),
@@ -50,19 +79,22 @@ func NewUpdateProfileModel(c *client.Client) UpdateProfileModel {
 	}
 
 	for i := range up.txtInputs {
-		crsr := cursor.New()
-		crsr.Style = lipgloss.NewStyle().Foreground(primaryColor)
-		crsr.TextStyle = crsr.Style
-
 		t := textinput.New()
 		t.Prompt = ""
-		t.PlaceholderStyle = lipgloss.NewStyle().Foreground(primarySubtleDarkColor)
-		t.TextStyle = lipgloss.NewStyle().Foreground(primaryColor)
-		t.Cursor = crsr
+		t.Cursor = cursor.New()
+		restyleUpdateProfileTxtInput(&t)
 		t.CharLimit = 64
 
 		switch i {
-		case 2, 3, 4:
+		case upHandleIdx:
+			t.CharLimit = 21 // with the leading @
+		case upStatusEmojiIdx:
+			t.CharLimit = 8
+		case upStatusTextIdx:
+			t.CharLimit = 80
+		case upBioIdx:
+			t.CharLimit = 160
+		case upPrevPassIdx, upNewPassIdx, upConfirmPassIdx:
 			t.EchoCharacter = '*'
 			t.EchoMode = textinput.EchoPassword
 		}
@@ -98,20 +130,19 @@ func (m UpdateProfileModel) Update(msg tea.Msg) (UpdateProfileModel, tea.Cmd) {
 
 		case "tab":
 			if m.focus {
-				// if pass is not included, then after email field, goto first button
-				if !m.includePass && m.tabIdx == 1 {
-					m.tabIdx = 4
+				// if pass is not included, then after bio field, goto first button
+				if !m.includePass && m.tabIdx == upBioIdx {
+					m.tabIdx = upConfirmPassIdx
 				}
-				m.tabIdx = (m.tabIdx + 1) % (len(m.inputTitles) + 3)
+				m.tabIdx = (m.tabIdx + 1) % upFormItems
 				m.focusTxtInputsAccordingly()
 			}
 
 		case "shift+tab":
 			if m.focus {
-				l := len(m.inputTitles) + 3
-				m.tabIdx = (m.tabIdx - 1 + l) % l
-				if !m.includePass && m.tabIdx == 4 {
-					m.tabIdx = 1
+				m.tabIdx = (m.tabIdx - 1 + upFormItems) % upFormItems
+				if !m.includePass && m.tabIdx == upConfirmPassIdx {
+					m.tabIdx = upBioIdx
 				}
 				m.focusTxtInputsAccordingly()
 			}
@@ -122,48 +153,49 @@ func (m UpdateProfileModel) Update(msg tea.Msg) (UpdateProfileModel, tea.Cmd) {
 
 		case "enter":
 			switch m.tabIdx {
-			case 0, 1, 2, 3, 4:
-				if !m.includePass && m.tabIdx == 1 {
-					m.tabIdx = 5
+			case upNameIdx, upHandleIdx, upEmailIdx, upStatusEmojiIdx, upStatusTextIdx, upStatusExpiryIdx, upBioIdx,
+				upPrevPassIdx, upNewPassIdx, upConfirmPassIdx:
+				if !m.includePass && m.tabIdx == upBioIdx {
+					m.tabIdx = upPassBtnIdx
 				} else {
 					m.tabIdx++
 				}
 				m.focusTxtInputsAccordingly()
-			case 5:
+			case upPassBtnIdx:
 				m.includePass = !m.includePass
 				// clear the associated fields
-				for i := 2; i <= 4; i++ {
+				for i := upPrevPassIdx; i <= upConfirmPassIdx; i++ {
 					m.txtInputs[i].Reset()
 				}
 				if m.includePass {
-					m.tabIdx = 2
+					m.tabIdx = upPrevPassIdx
 					m.focusTxtInputsAccordingly()
 				}
-			case 6:
+			case upUpdateBtnIdx:
 				if !m.spin {
 					m.spin = true
 					if err := m.validateTxtInputs(); err == nil {
 						return m, tea.Batch(m.spinner.Tick, m.updateUser())
 					}
 				}
-			case 7:
+			case upLogoutIdx:
 				return m, m.logout()
 			}
 
 		case "up", "left":
-			if m.tabIdx == 6 {
-				m.tabIdx = 5
+			if m.tabIdx == upUpdateBtnIdx {
+				m.tabIdx = upPassBtnIdx
 			}
 
 		case "down", "right":
-			if m.tabIdx == 5 {
-				m.tabIdx = 6
+			if m.tabIdx == upPassBtnIdx {
+				m.tabIdx = upUpdateBtnIdx
 			}
 		}
 
 	case tea.MouseMsg:
 		if msg.Button == tea.MouseButtonLeft {
-			for i := range 8 {
+			for i := range upFormItems {
 				if zone.Get(fmt.Sprint("formItem", i)).InBounds(msg) {
 					m.tabIdx = i
 					m.focusTxtInputsAccordingly()
@@ -191,6 +223,12 @@ func (m UpdateProfileModel) Update(msg tea.Msg) (UpdateProfileModel, tea.Cmd) {
 	case hideSuccessMsg:
 		m.showSuccess = false
 
+	case themeChangedMsg:
+		for i := range m.txtInputs {
+			restyleUpdateProfileTxtInput(&m.txtInputs[i])
+		}
+		m.spinner.Style = lipgloss.NewStyle().Foreground(primaryColor)
+
 	case *domain.ErrValidation:
 		m.spin = false
 		m.spinner = newSpinner()
@@ -226,23 +264,69 @@ func newSpinner() spinner.Model {
 	return s
 }
 
+// restyleUpdateProfileTxtInput colors ti after the active theme, on creation & on themeChangedMsg
+func restyleUpdateProfileTxtInput(ti *textinput.Model) {
+	restyleTxtInput(ti)
+	ti.Cursor.TextStyle = ti.Cursor.Style
+	ti.PlaceholderStyle = lipgloss.NewStyle().Foreground(primarySubtleDarkColor)
+}
+
 func (m *UpdateProfileModel) populateDefaultPlaceholders() {
 	for m.client.CurrentUsr != nil { // the loop max runs for 2 iterations, tested it
-		m.txtInputs[0].Placeholder = m.client.CurrentUsr.Name
 		m.prevName = m.client.CurrentUsr.Name
-		m.txtInputs[1].Placeholder = m.client.CurrentUsr.Email
 		m.prevEmail = m.client.CurrentUsr.Email
+		for i := upNameIdx; i <= upBioIdx; i++ {
+			m.txtInputs[i].Placeholder = m.defaultPlaceholder(i)
+		}
 		if m.client.CurrentUsr != nil {
 			break
 		}
 	}
 }
 
+// defaultPlaceholder shows the current value of the field, or how to fill it in if not set
+func (m *UpdateProfileModel) defaultPlaceholder(idx int) string {
+	u := m.client.CurrentUsr
+	if u == nil {
+		return ""
+	}
+	u.ClearExpiredStatus()
+	switch idx {
+	case upNameIdx:
+		return u.Name
+	case upHandleIdx:
+		if u.Handle != nil {
+			return "@" + *u.Handle
+		}
+		return "e.g. @ali_dev, others can find you by it"
+	case upEmailIdx:
+		return u.Email
+	case upStatusEmojiIdx:
+		return cmp.Or(u.StatusEmoji, "e.g. 🌴")
+	case upStatusTextIdx:
+		if u.StatusText != "" {
+			return u.StatusText + ", " + upClearValue + " to clear"
+		}
+		return "e.g. On vacation"
+	case upStatusExpiryIdx:
+		if u.StatusExpiresAt != nil {
+			return u.StatusExpiresAt.Local().Format("Jan 2 15:04") + ", or never"
+		}
+		return "e.g. 30m or 4h, never by default"
+	case upBioIdx:
+		if u.Bio != "" {
+			return u.Bio + ", " + upClearValue + " to clear"
+		}
+		return "A few words about you"
+	}
+	return ""
+}
+
 func (m UpdateProfileModel) renderForm() string {
 	m.manageInputStylesAccordingly()
 	var sb strings.Builder
 	for i, t := range m.inputTitles {
-		if i == 2 && !m.includePass {
+		if i == upPrevPassIdx && !m.includePass {
 			// do not include password fields
 			break
 		}
@@ -261,14 +345,14 @@ func (m UpdateProfileModel) renderForm() string {
 		return sb.String()
 	}
 	logoutActionStyle := lipgloss.NewStyle().Foreground(dangerDarkColor)
-	if m.tabIdx == 7 {
+	if m.tabIdx == upLogoutIdx {
 		logoutActionStyle = lipgloss.NewStyle().
 			Foreground(dangerColor).
 			Italic(true).
 			Underline(true)
 	}
 
-	logoutPrompt := zone.Mark("formItem7", logoutActionStyle.Render("Logout!"))
+	logoutPrompt := zone.Mark(fmt.Sprint("formItem", upLogoutIdx), logoutActionStyle.Render("Logout!"))
 	logoutPrompt = logoutPromptStyle.Render(logoutPrompt)
 	logoutPrompt = lipgloss.PlaceHorizontal(updateProfileWidth()-6, lipgloss.Center, logoutPrompt)
 	sb.WriteString(logoutPrompt)
@@ -285,21 +369,21 @@ func (m UpdateProfileModel) renderFormBtns() string {
 		btn1 = updateProfileFromBlurBtnStyle.Render(s2)
 	}
 	switch m.tabIdx {
-	case len(m.txtInputs):
+	case upPassBtnIdx:
 		btn1 = updateProfileFormActiveBtnStyle.Render(s1)
 		if m.includePass {
 			btn1 = updateProfileFormDangerBtnStyle.Render(s2)
 		}
-	case len(m.txtInputs) + 1:
+	case upUpdateBtnIdx:
 		btn2Style = updateProfileFormActiveBtnStyle.Padding(0, 3)
 	}
 	if m.spin {
 		s3 = m.spinner.View()
 		btn2Style = updateProfileFromBlurBtnStyle.Padding(0, 8).Background(primaryContrastColor)
 	}
-	btn1 = zone.Mark("formItem5", btn1)
+	btn1 = zone.Mark(fmt.Sprint("formItem", upPassBtnIdx), btn1)
 	btn2 := btn2Style.Render(s3)
-	btn2 = zone.Mark("formItem6", btn2)
+	btn2 = zone.Mark(fmt.Sprint("formItem", upUpdateBtnIdx), btn2)
 	btns := lipgloss.JoinHorizontal(lipgloss.Bottom, btn1, "  ", btn2)
 	if updateProfileWidth() < 50 {
 		btns = lipgloss.JoinVertical(lipgloss.Center, btn1, btn2)
@@ -309,7 +393,7 @@ func (m UpdateProfileModel) renderFormBtns() string {
 
 func (m *UpdateProfileModel) manageInputStylesAccordingly() {
 	for i := range m.inputTitles {
-		if i == 2 && !m.includePass {
+		if i == upPrevPassIdx && !m.includePass {
 			// do not include password fields
 			break
 		}
@@ -360,17 +444,19 @@ func (m *UpdateProfileModel) validateTxtInputs() error {
 	// clear the previous errors
 	maps.Clear(m.ev.Errors)
 	validateEmptyField := true
+	// if password fields are not included, and only some of the profile fields are set, then if name/email is
+	// empty, validate its placeholder instead
+	for i := upNameIdx; i <= upBioIdx; i++ {
+		if !m.includePass && m.txtInputs[i].Value() != "" {
+			validateEmptyField = false
+		}
+	}
 	for i := range m.txtInputs {
-		if !m.includePass && i == 2 {
+		if !m.includePass && i == upPrevPassIdx {
 			break
 		}
-		// if password fields are not included, and only one of name/email is set, then if other is empty,
-		// validate its placeholder instead
-		if !m.includePass && (m.txtInputs[0].Value() != "" || m.txtInputs[1].Value() != "") {
-			validateEmptyField = false
-		}
 		switch i {
-		case 0, 1:
+		case upNameIdx, upEmailIdx:
 			// if only password fields are included, and name/email are empty -> validate their placeholders instead
 			// if password fields are not included, and only one of name/email is set, then if other is empty,
 			// validate its placeholder instead
@@ -378,19 +464,27 @@ func (m *UpdateProfileModel) validateTxtInputs() error {
 			if (m.txtInputs[i].Value() == "" && m.includePass) || (!m.includePass && !validateEmptyField) {
 				toValidate = m.txtInputs[i].Placeholder
 			}
-			if i == 0 {
+			if i == upNameIdx {
 				domain.ValidateName(toValidate, m.ev)
 			} else {
 				domain.ValidateEmail(toValidate, m.ev)
 			}
-		case 2, 3, 4:
+		case upHandleIdx:
+			if h := m.txtInputs[i].Value(); h != "" {
+				domain.ValidateHandle(domain.NormalizeHandle(h), m.ev)
+			}
+		case upStatusExpiryIdx:
+			if _, err := parseStatusExpiry(m.txtInputs[i].Value()); err != nil {
+				m.ev.AddError(m.errFieldTitles[i], "must be a duration like 30m or 4h, or never")
+			}
+		case upPrevPassIdx, upNewPassIdx, upConfirmPassIdx:
 			domain.ValidPlainPasswordWithKey(m.txtInputs[i].Value(), m.ev, m.errFieldTitles[i])
 		}
 	}
 	// if passwords do not match
-	if m.txtInputs[4].Value() != m.txtInputs[3].Value() {
-		m.txtInputs[4].Reset()
-		m.ev.AddError(m.errFieldTitles[4], "must match the new password")
+	if m.txtInputs[upConfirmPassIdx].Value() != m.txtInputs[upNewPassIdx].Value() {
+		m.txtInputs[upConfirmPassIdx].Reset()
+		m.ev.AddError(m.errFieldTitles[upConfirmPassIdx], "must match the new password")
 	}
 	if m.ev.HasErrors() {
 		for i, et := range m.errFieldTitles { // et -> errorTitle
@@ -415,13 +509,7 @@ func (m *UpdateProfileModel) removeErrAccordingToTabIdx() {
 	if m.ev.HasErrors() {
 		for i, et := range m.errFieldTitles {
 			if m.txtInputs[i].Focused() {
-				if i == 0 {
-					m.txtInputs[i].Placeholder = m.client.CurrentUsr.Name
-				} else if i == 1 {
-					m.txtInputs[i].Placeholder = m.client.CurrentUsr.Email
-				} else {
-					m.txtInputs[i].Placeholder = ""
-				}
+				m.txtInputs[i].Placeholder = m.defaultPlaceholder(i)
 				m.txtInputs[i].PlaceholderStyle = lipgloss.NewStyle().Foreground(primarySubtleDarkColor)
 				delete(m.ev.Errors, et)
 				break
@@ -452,17 +540,22 @@ func (m *UpdateProfileModel) resetAllfields() {
 }
 
 func (m *UpdateProfileModel) populateServerErr(msg *domain.ErrValidation) {
-	if err, ok := msg.Errors["name"]; ok {
-		m.ev.AddError(m.errFieldTitles[0], err)
-		m.populateErr(0, err)
-	}
-	if err, ok := msg.Errors["email"]; ok {
-		m.ev.AddError(m.errFieldTitles[1], err)
-		m.populateErr(1, err)
+	// the keys of the server errors, by the form items they are about
+	serverKeys := map[int]string{
+		upNameIdx:         "name",
+		upHandleIdx:       "handle",
+		upEmailIdx:        "email",
+		upStatusEmojiIdx:  "statusEmoji",
+		upStatusTextIdx:   "statusText",
+		upStatusExpiryIdx: "statusExpiresAt",
+		upBioIdx:          "bio",
+		upPrevPassIdx:     "currentPassword",
 	}
-	if err, ok := msg.Errors["currentPassword"]; ok {
-		m.ev.AddError(m.errFieldTitles[2], err)
-		m.populateErr(2, err)
+	for i, key := range serverKeys {
+		if err, ok := msg.Errors[key]; ok {
+			m.ev.AddError(m.errFieldTitles[i], err)
+			m.populateErr(i, err)
+		}
 	}
 }
 
@@ -470,17 +563,18 @@ func (m *UpdateProfileModel) updateUser() tea.Cmd {
 	return func() tea.Msg {
 		u := domain.UserUpdate{
 			ID:    m.client.CurrentUsr.ID,
-			Name:  m.txtInputs[0].Value(),
-			Email: m.txtInputs[1].Value(),
+			Name:  m.txtInputs[upNameIdx].Value(),
+			Email: m.txtInputs[upEmailIdx].Value(),
 		}
+		m.setProfileUpdate(&u)
 		if u.Name == "" {
 			u.Name = m.client.CurrentUsr.Name
 		}
 		if u.Email == "" {
 			u.Email = m.client.CurrentUsr.Email
 		}
-		curPass := m.txtInputs[2].Value()
-		newPass := m.txtInputs[4].Value()
+		curPass := m.txtInputs[upPrevPassIdx].Value()
+		newPass := m.txtInputs[upConfirmPassIdx].Value()
 		if m.includePass {
 			u.CurrentPassword = &curPass
 			u.NewPassword = &newPass
@@ -508,6 +602,48 @@ func (m *UpdateProfileModel) updateUser() tea.Cmd {
 	}
 }
 
+// setProfileUpdate sets the handle, status & bio of u, the fields left empty keep their current value
+func (m *UpdateProfileModel) setProfileUpdate(u *domain.UserUpdate) {
+	cur := m.client.CurrentUsr
+	emoji := m.txtInputs[upStatusEmojiIdx].Value()
+	text := m.txtInputs[upStatusTextIdx].Value()
+	expiry := m.txtInputs[upStatusExpiryIdx].Value()
+	if emoji != "" || text != "" || expiry != "" {
+		emoji, text = cmp.Or(emoji, cur.StatusEmoji), cmp.Or(text, cur.StatusText)
+		if text == upClearValue {
+			emoji, text = "", ""
+		}
+		expiresAt := cur.StatusExpiresAt
+		if expiry != "" {
+			expiresAt, _ = parseStatusExpiry(expiry) // validated by validateTxtInputs
+		}
+		u.StatusEmoji, u.StatusText, u.StatusExpiresAt = &emoji, &text, expiresAt
+	}
+	if h := m.txtInputs[upHandleIdx].Value(); h != "" {
+		h = domain.NormalizeHandle(h)
+		u.Handle = &h
+	}
+	if bio := m.txtInputs[upBioIdx].Value(); bio != "" {
+		if bio == upClearValue {
+			bio = ""
+		}
+		u.Bio = &bio
+	}
+}
+
+// parseStatusExpiry parses the status expiry field, a duration from now, nil for never
+func parseStatusExpiry(s string) (*time.Time, error) {
+	if s == "" || strings.EqualFold(s, "never") {
+		return nil, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return nil, errors.New("invalid status expiry")
+	}
+	t := time.Now().Add(d)
+	return &t, nil
+}
+
 func (m UpdateProfileModel) logout() tea.Cmd {
 	return func() tea.Msg {
 		if err := m.client.Logout(); err != nil {
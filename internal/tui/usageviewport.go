@@ -1,11 +1,18 @@
 package tui
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"github.com/MuhamedUsman/letschat/internal/tui/embed"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/glamour/ansi"
 	"github.com/charmbracelet/lipgloss"
+	"log/slog"
+	"strings"
+	"text/template"
 )
 
 type UsageViewportModel struct {
@@ -16,12 +23,9 @@ type UsageViewportModel struct {
 
 func NewUsageViewportModel() UsageViewportModel {
 	vp := viewport.New(0, 0)
-	usageFiles := embed.EmbeddedFilesInstance()
-	g, _ := glamour.NewTermRenderer(glamour.WithStylesFromJSONBytes(usageFiles.UsageTheme), glamour.WithEmoji())
-	usage, _ := g.Render(string(usageFiles.UsageFile))
 	return UsageViewportModel{
 		vp:    vp,
-		usage: usage,
+		usage: renderUsage(),
 	}
 }
 
@@ -37,9 +41,13 @@ func (m UsageViewportModel) Update(msg tea.Msg) (UsageViewportModel, tea.Cmd) {
 	} else {
 		m.vp.GotoTop()
 	}
-	if _, ok := msg.(tea.WindowSizeMsg); ok {
+	switch msg.(type) {
+	case tea.WindowSizeMsg:
 		m.vp.Width = usageWidth()
-		m.vp.Height = conversationHeight() - 1
+		m.vp.Height = conversationHeight() - 1 - lipgloss.Height(renderThemePicker(""))
+		m.vp.SetContent(m.renderViewport())
+	case themeChangedMsg:
+		m.usage = renderUsage()
 		m.vp.SetContent(m.renderViewport())
 	}
 	var cmd tea.Cmd
@@ -56,3 +64,55 @@ func (m UsageViewportModel) renderViewport() string {
 	title = lipgloss.PlaceHorizontal(usageWidth(), lipgloss.Center, title)
 	return lipgloss.JoinVertical(lipgloss.Left, title, m.usage)
 }
+
+// Helpers & Stuff -----------------------------------------------------------------------------------------------------
+
+// renderUsage renders the usage file with the embedded glamour theme, colored after the active theme, its shortcuts are
+// filled in from the active keys, e.g. {{keys "chat.options"}}
+func renderUsage() string {
+	usageFiles := embed.EmbeddedFilesInstance()
+	t, err := template.New("usage").Funcs(template.FuncMap{"keys": usageKeys}).Parse(string(usageFiles.UsageFile))
+	if err != nil {
+		panic(err) // it's for developer to ensure the embedded usage is valid
+	}
+	var md bytes.Buffer
+	if err = t.Execute(&md, nil); err != nil {
+		panic(err)
+	}
+	var sc ansi.StyleConfig
+	if err := json.Unmarshal(usageFiles.UsageTheme, &sc); err != nil {
+		panic(err) // it's for developer to ensure the embedded theme is valid
+	}
+	color := func(c lipgloss.AdaptiveColor) *string {
+		s := activeTheme.pick(c)
+		return &s
+	}
+	sc.Document.Color = color(whiteColor)
+	sc.Heading.Color = color(dangerColor)
+	sc.H1.Color, sc.H1.BackgroundColor = color(primaryColor), color(primaryContrastColor)
+	sc.Code.Color, sc.Code.BackgroundColor = color(orangeColor), color(blackColor)
+	sc.HorizontalRule.Color = color(darkGreyColor)
+	g, err := glamour.NewTermRenderer(glamour.WithStyles(sc), glamour.WithEmoji())
+	if err != nil {
+		slog.Error(err.Error())
+		return md.String()
+	}
+	usage, err := g.Render(md.String())
+	if err != nil {
+		slog.Error(err.Error())
+		return md.String()
+	}
+	return usage
+}
+
+// usageKeys renders the keys of the binding by its name in the keys file as code, e.g. `CTRL+F`
+func usageKeys(name string) (string, error) {
+	for _, g := range keys.groups() {
+		for _, nb := range g.bindings {
+			if g.name+"."+nb.name == name {
+				return "`" + strings.ReplaceAll(keysHelp(*nb.Binding), " OR ", "` OR `") + "`", nil
+			}
+		}
+	}
+	return "", fmt.Errorf("unknown key binding %q", name)
+}
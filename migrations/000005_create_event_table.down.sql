@@ -0,0 +1 @@
+DROP TABLE IF EXISTS event;
\ No newline at end of file
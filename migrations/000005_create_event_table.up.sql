@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS event (
+    cursor BIGSERIAL PRIMARY KEY, -- monotonically increasing, so it is also increasing for every single user
+    user_id UUID NOT NULL REFERENCES users ON DELETE CASCADE, -- the user this event is to be delivered to
+    id UUID NOT NULL, -- id of the msg this event is about
+    sender_id UUID REFERENCES users ON DELETE SET NULL,
+    receiver_id UUID REFERENCES users ON DELETE SET NULL,
+    body TEXT NOT NULL DEFAULT '',
+    sent_at TIMESTAMP(0) WITH TIME ZONE,
+    delivered_at TIMESTAMP(0) WITH TIME ZONE,
+    read_at TIMESTAMP(0) WITH TIME ZONE,
+    operation INT NOT NULL,
+    created_at TIMESTAMP(0) WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_event_user_id_cursor ON event(user_id, cursor);
+
+-- msgs still pending delivery become the first events, so nothing is lost while upgrading
+INSERT INTO event (user_id, id, sender_id, receiver_id, body, sent_at, delivered_at, read_at, operation)
+SELECT receiver_id, id, sender_id, receiver_id, body, sent_at, delivered_at, read_at, operation
+FROM message
+WHERE receiver_id IS NOT NULL
+ORDER BY sent_at;
\ No newline at end of file
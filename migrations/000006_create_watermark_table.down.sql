@@ -0,0 +1,2 @@
+ALTER TABLE event DROP COLUMN IF EXISTS up_to;
+DROP TABLE IF EXISTS watermark;
\ No newline at end of file
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS watermark (
+    user_id UUID REFERENCES users ON DELETE CASCADE, -- the participant who received & read the msgs
+    peer_id UUID REFERENCES users ON DELETE CASCADE, -- the other participant, who sent them
+    delivered_up_to TIMESTAMP WITH TIME ZONE,
+    read_up_to TIMESTAMP WITH TIME ZONE,
+    PRIMARY KEY (user_id, peer_id)
+);
+
+ALTER TABLE event ADD COLUMN IF NOT EXISTS up_to TIMESTAMP WITH TIME ZONE;
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS status_text TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS status_emoji TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS status_expires_at TIMESTAMP(0) WITH TIME ZONE, -- NULL, the status never expires
+    ADD COLUMN IF NOT EXISTS bio TEXT NOT NULL DEFAULT '';
\ No newline at end of file
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS handle_redirect;
+ALTER TABLE users DROP COLUMN IF EXISTS handle;
\ No newline at end of file
@@ -0,0 +1,10 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS handle CITEXT UNIQUE; -- NULL until chosen
+
+-- the previous handles of the users, resolving to them until expires_at, no one else may take them meanwhile
+CREATE TABLE IF NOT EXISTS handle_redirect (
+    handle CITEXT PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users ON DELETE CASCADE,
+    expires_at TIMESTAMP(0) WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_handle_redirect_user_id ON handle_redirect(user_id);
\ No newline at end of file
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS user_block;
+DROP INDEX IF EXISTS idx_users_handle_trgm;
+DROP INDEX IF EXISTS idx_users_email_trgm;
+CREATE INDEX IF NOT EXISTS idx_users_email_trgm ON users USING GIN (name GIN_TRGM_OPS);
\ No newline at end of file
@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_users_email_trgm; -- was built on name
+CREATE INDEX IF NOT EXISTS idx_users_email_trgm ON users USING GIN ((email::TEXT) GIN_TRGM_OPS);
+CREATE INDEX IF NOT EXISTS idx_users_handle_trgm ON users USING GIN ((handle::TEXT) GIN_TRGM_OPS);
+
+-- blocked users never show up in the searches of the ones blocking them, and vice versa
+CREATE TABLE IF NOT EXISTS user_block (
+    blocker_id UUID NOT NULL REFERENCES users ON DELETE CASCADE,
+    blocked_id UUID NOT NULL REFERENCES users ON DELETE CASCADE,
+    created_at TIMESTAMP(0) WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (blocker_id, blocked_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_block_blocked_id ON user_block(blocked_id);
\ No newline at end of file
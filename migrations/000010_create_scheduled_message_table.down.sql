@@ -0,0 +1 @@
+DROP TABLE IF EXISTS scheduled_message;
\ No newline at end of file
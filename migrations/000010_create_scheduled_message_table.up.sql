@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS scheduled_message (
+    id UUID PRIMARY KEY, -- becomes the id of the msg once released
+    sender_id UUID NOT NULL REFERENCES users ON DELETE CASCADE,
+    receiver_id UUID NOT NULL REFERENCES users ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    send_at TIMESTAMP(0) WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP(0) WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    version INT NOT NULL DEFAULT 1
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_message_send_at ON scheduled_message(send_at);
+CREATE INDEX IF NOT EXISTS idx_scheduled_message_sender_id_send_at ON scheduled_message(sender_id, send_at);
\ No newline at end of file
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_event_expires_at;
+DROP INDEX IF EXISTS idx_message_expires_at;
+
+ALTER TABLE event DROP COLUMN IF EXISTS disappear_after;
+ALTER TABLE event DROP COLUMN IF EXISTS expires_at;
+ALTER TABLE message DROP COLUMN IF EXISTS expires_at;
+ALTER TABLE conversation DROP COLUMN IF EXISTS disappear_after;
\ No newline at end of file
@@ -0,0 +1,9 @@
+-- seconds after which the msgs of the conversation disappear, zero if they do not
+ALTER TABLE conversation ADD COLUMN IF NOT EXISTS disappear_after BIGINT NOT NULL DEFAULT 0;
+
+ALTER TABLE message ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP(0) WITH TIME ZONE;
+ALTER TABLE event ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP(0) WITH TIME ZONE;
+ALTER TABLE event ADD COLUMN IF NOT EXISTS disappear_after BIGINT;
+
+CREATE INDEX IF NOT EXISTS idx_message_expires_at ON message(expires_at) WHERE expires_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_event_expires_at ON event(expires_at) WHERE expires_at IS NOT NULL;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE event DROP COLUMN IF EXISTS pinned_at;
\ No newline at end of file
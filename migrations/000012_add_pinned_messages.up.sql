@@ -0,0 +1,2 @@
+-- pins are kept by the clients, the event log relays them to the other user of the conversation
+ALTER TABLE event ADD COLUMN IF NOT EXISTS pinned_at TIMESTAMP(0) WITH TIME ZONE;
\ No newline at end of file
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_users_bot_owner_id;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_bot_owner_check;
+ALTER TABLE users DROP COLUMN IF EXISTS bot_owner_id;
+ALTER TABLE users DROP COLUMN IF EXISTS is_bot;
\ No newline at end of file
@@ -0,0 +1,6 @@
+-- bots are users owned by a human user, activated on creation & authenticated with the keys of the bot token scope
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_bot BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS bot_owner_id UUID REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE users ADD CONSTRAINT users_bot_owner_check CHECK (is_bot = (bot_owner_id IS NOT NULL));
+
+CREATE INDEX IF NOT EXISTS idx_users_bot_owner_id ON users(bot_owner_id) WHERE bot_owner_id IS NOT NULL;
\ No newline at end of file
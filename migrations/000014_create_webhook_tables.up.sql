@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS webhook (
+    id UUID DEFAULT GEN_RANDOM_UUID() PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    events TEXT[] NOT NULL, -- create, read, delete or presence
+    secret TEXT NOT NULL, -- the payloads are signed with, HMAC-SHA256
+    created_at TIMESTAMP(0) WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_user_id ON webhook(user_id);
+
+CREATE TABLE IF NOT EXISTS webhook_delivery (
+    id BIGSERIAL PRIMARY KEY,
+    webhook_id UUID NOT NULL REFERENCES webhook ON DELETE CASCADE,
+    event TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending', -- pending, delivered, or failed once out of attempts
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP(3) WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    response_status INT,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP(0) WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    delivered_at TIMESTAMP(0) WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_delivery_next_attempt_at ON webhook_delivery(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_webhook_delivery_webhook_id_id ON webhook_delivery(webhook_id, id DESC);
+CREATE INDEX IF NOT EXISTS idx_webhook_delivery_created_at ON webhook_delivery(created_at) WHERE status <> 'pending';
\ No newline at end of file
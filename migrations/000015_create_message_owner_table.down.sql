@@ -0,0 +1 @@
+DROP TABLE IF EXISTS message_owner;
\ No newline at end of file
@@ -0,0 +1,21 @@
+-- outlives the msg rows deleted once acknowledged, so the ops on a msg are authorized by who it is between
+CREATE TABLE IF NOT EXISTS message_owner (
+    id UUID PRIMARY KEY,
+    sender_id UUID REFERENCES users ON DELETE CASCADE,
+    receiver_id UUID REFERENCES users ON DELETE CASCADE
+);
+
+-- the msgs still pending, the DeliveredMsg & ReadMsg rows are written by the receiver
+INSERT INTO message_owner (id, sender_id, receiver_id)
+SELECT id,
+       CASE WHEN operation IN (1, 3) THEN receiver_id ELSE sender_id END,
+       CASE WHEN operation IN (1, 3) THEN sender_id ELSE receiver_id END
+FROM message
+ON CONFLICT (id) DO NOTHING;
+
+-- the msgs acknowledged by a party but still pending as events of the other one
+INSERT INTO message_owner (id, sender_id, receiver_id)
+SELECT DISTINCT ON (id) id, sender_id, receiver_id
+FROM event
+WHERE operation = 0 AND sender_id IS NOT NULL AND receiver_id IS NOT NULL
+ON CONFLICT (id) DO NOTHING;
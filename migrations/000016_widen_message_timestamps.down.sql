@@ -0,0 +1,4 @@
+ALTER TABLE event ALTER COLUMN expires_at TYPE TIMESTAMP(0) WITH TIME ZONE;
+ALTER TABLE event ALTER COLUMN sent_at TYPE TIMESTAMP(0) WITH TIME ZONE;
+ALTER TABLE message ALTER COLUMN expires_at TYPE TIMESTAMP(0) WITH TIME ZONE;
+ALTER TABLE message ALTER COLUMN sent_at TYPE TIMESTAMP(0) WITH TIME ZONE;
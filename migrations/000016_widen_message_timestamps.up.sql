@@ -0,0 +1,5 @@
+-- sub-second, so a watermark taken from the sent_at of a msg is not rounded over the msgs sent in the same second
+ALTER TABLE message ALTER COLUMN sent_at TYPE TIMESTAMP WITH TIME ZONE;
+ALTER TABLE message ALTER COLUMN expires_at TYPE TIMESTAMP WITH TIME ZONE;
+ALTER TABLE event ALTER COLUMN sent_at TYPE TIMESTAMP WITH TIME ZONE;
+ALTER TABLE event ALTER COLUMN expires_at TYPE TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,2 @@
+-- blocking never got an api, the searches do not filter on it anymore
+DROP TABLE IF EXISTS user_block;